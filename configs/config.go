@@ -1,42 +1,448 @@
 package configs
 
 import (
-	"github.com/joho/godotenv"
+	"encoding"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // image แบบ minimal อาจไม่มี zoneinfo
+
+	"backend/pkg/logx"
+	"backend/ratelimit"
+
+	"github.com/joho/godotenv"
 )
 
+// Config = ค่าตั้งทั้งหมดของแอป โหลดครั้งเดียวตอนเริ่มโปรแกรม (ดู Load)
+//
+// tag env = ชื่อตัวแปร, default = ค่าเริ่มต้น (ทับได้ด้วย profile), secret = ไม่พิมพ์ค่าออก log
 type Config struct {
-	DBDriver  string
-	DBSource  string
-	Port      string
-	JWTSecret string
-	JWTTTL    time.Duration
-	EasySlipAPIKey string
+	Profile string `env:"APP_ENV" default:"dev"` // dev | test | prod
+
+	// Database
+	DBDriver      string `env:"DB_DRIVER" default:"sqlite"`     // sqlite | postgres | mysql
+	DBSource      string `env:"DB_SOURCE" default:"test.db"`    // path ของ sqlite หรือ DSN ของ postgres/mysql
+	DBAutoMigrate bool   `env:"DB_AUTO_MIGRATE" default:"true"` // apply migration ที่ค้างตอน serve
+
+	// Connection pool (0 = ใช้ค่า default ของ database/sql)
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"10"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+
+	// HTTP server
+	Port            string        `env:"PORT" default:"8000"`
+	ReadTimeout     time.Duration `env:"HTTP_READ_TIMEOUT" default:"15s"`
+	WriteTimeout    time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout     time.Duration `env:"HTTP_IDLE_TIMEOUT" default:"60s"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"20s"` // รอ request/worker ที่ค้างตอน SIGTERM
+	CORSOrigins     []string      `env:"CORS_ORIGINS" default:"*"`       // คั่นด้วย comma; ใช้กับ WebSocket ด้วย
+
+	// Log: text อ่านง่ายตอน dev, json ให้ระบบเก็บ log ใน prod
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`  // debug | info | warn | error
+	LogFormat string `env:"LOG_FORMAT" default:"text"` // text | json
+
+	// Bearer token ของ /metrics (ว่าง = เปิดให้ scrape ได้ ต้องกันที่ระดับ network เอง)
+	MetricsToken string `env:"METRICS_TOKEN" secret:"true"`
+
+	// Security headers
+	HSTSMaxAge   time.Duration `env:"HSTS_MAX_AGE" default:"0"` // 0 = ไม่ส่ง HSTS
+	FrameOptions string        `env:"FRAME_OPTIONS" default:"DENY"`
+	UploadsCSP   string        `env:"UPLOADS_CSP" default:"default-src 'none'; img-src 'self'; sandbox"`
+
+	// IP ของ reverse proxy ที่เชื่อ X-Forwarded-For ได้ (ว่าง = ใช้ IP ที่ต่อเข้ามาตรง ๆ)
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	// Rate limit ต่อ route ต่อ user/IP รูปแบบ "จำนวน/ช่วงเวลา" (0 = ไม่จำกัด)
+	RateLimitAuth       ratelimit.Rate `env:"RATE_LIMIT_AUTH" default:"10/1m"`       // login, register
+	RateLimitSlipVerify ratelimit.Rate `env:"RATE_LIMIT_SLIP_VERIFY" default:"5/1m"` // ใช้โควตา EasySlip ที่เสียเงิน
+	RateLimitReports    ratelimit.Rate `env:"RATE_LIMIT_REPORTS" default:"20/1h"`
+
+	// ล็อกบัญชีเมื่อ login ผิดติดกัน (ดู services.LockoutPolicy)
+	LoginMaxFailures int           `env:"LOGIN_MAX_FAILURES" default:"5"` // 0 = ปิด
+	LoginLockout     time.Duration `env:"LOGIN_LOCKOUT" default:"1m"`
+	LoginLockoutMax  time.Duration `env:"LOGIN_LOCKOUT_MAX" default:"24h"`
+
+	// Auth
+	JWTSecret  string        `env:"JWT_SECRET" default:"changeme" secret:"true"`
+	JWTTTL     time.Duration `env:"JWT_TTL" default:"24h"`
+	RefreshTTL time.Duration `env:"JWT_REFRESH_TTL" default:"168h"`
+
+	// บริการภายนอก
+	EasySlipAPIKey    string        `env:"EASYSLIP_API_KEY" secret:"true"`
+	SlipVerifyTimeout time.Duration `env:"SLIP_VERIFY_TIMEOUT" default:"25s"`
+	UpstreamTimeout   time.Duration `env:"UPSTREAM_TIMEOUT" default:"10s"` // webhook / push
+
+	// Upload (ขนาดหลัง decode base64)
+	MaxSlipBytes   int `env:"MAX_SLIP_BYTES" default:"5242880"`
+	MaxAvatarBytes int `env:"MAX_AVATAR_BYTES" default:"10485760"`
+
+	// WebSocket แชท
+	WSMaxMessageBytes int64         `env:"WS_MAX_MESSAGE_BYTES" default:"8192"` // ข้อความจาก client ที่ใหญ่กว่านี้ = ตัด connection
+	WSSendBuffer      int           `env:"WS_SEND_BUFFER" default:"32"`         // ข้อความค้างส่งต่อ connection ก่อนถูกตัดเป็น slow consumer
+	WSPongWait        time.Duration `env:"WS_PONG_WAIT" default:"60s"`          // ping ทุก 9/10 ของค่านี้
+
+	// กระจายแชทข้าม instance: memory (instance เดียว) | redis (ต้องตั้ง REDIS_URL)
+	ChatBroker string `env:"CHAT_BROKER" default:"memory"`
+	RedisURL   string `env:"REDIS_URL" secret:"true"` // redis://[:password@]host:6379/0
+
+	// โซนเวลาของร้าน ใช้ตีความเวลาเปิด-ปิด ("HH:MM") ตอนตรวจเวลาสั่งล่วงหน้า (ไม่ขึ้นกับ TZ ของเครื่อง)
+	Timezone *time.Location `env:"TIMEZONE" default:"Asia/Bangkok"`
+
+	// ค่าส่งเมื่อ client ไม่ได้ส่ง deliveryFee มา (บาท)
+	DefaultDeliveryFee int64 `env:"DEFAULT_DELIVERY_FEE" default:"0"`
+
+	// Push notification (ว่าง = ใช้ log provider)
+	FCMCredentialsFile string `env:"FCM_CREDENTIALS_FILE"` // service account JSON ของ Firebase (FCM HTTP v1)
+	FCMProjectID       string `env:"FCM_PROJECT_ID"`       // ว่าง = project_id ในไฟล์ credentials
+	APNsAuthToken      string `env:"APNS_AUTH_TOKEN" secret:"true"`
+	APNsTopic          string `env:"APNS_TOPIC"`
+	APNsSandbox        bool   `env:"APNS_SANDBOX" default:"false"`
+
+	// Seed ตอน serve
+	AdminEmail    string `env:"ADMIN_EMAIL"`
+	AdminPassword string `env:"ADMIN_PASSWORD" secret:"true"`
+	SeedDemo      bool   `env:"SEED_DEMO" default:"false"`
 }
 
-func LoadConfig() *Config {
-	err := godotenv.Load()
+// ค่า default ที่ต่างกันตาม profile (ทับ tag default)
+var profileDefaults = map[string]map[string]string{
+	"dev": {
+		"SEED_DEMO": "true",
+		"LOG_LEVEL": "debug",
+	},
+	"test": {
+		"DB_SOURCE":  "file::memory:?cache=shared",
+		"JWT_SECRET": "test-secret",
+		// test ยิงจาก IP เดียวกันทั้งหมด
+		"RATE_LIMIT_AUTH":        "0",
+		"RATE_LIMIT_SLIP_VERIFY": "0",
+		"RATE_LIMIT_REPORTS":     "0",
+		"LOG_LEVEL":              "warn",
+	},
+	"prod": {
+		"CORS_ORIGINS":    "",
+		"HSTS_MAX_AGE":    "4320h", // 180 วัน
+		"DB_AUTO_MIGRATE": "false", // prod รัน `backend migrate up` เอง
+		"LOG_FORMAT":      "json",
+	},
+}
+
+// LoadOptions = แหล่งค่าเพิ่มเติมนอกจาก env (ลำดับความสำคัญ: default < profile < ไฟล์ < env < Overrides)
+type LoadOptions struct {
+	File      string            // ไฟล์รูปแบบ .env; ว่าง = ใช้ CONFIG_FILE ถ้ามี
+	Overrides map[string]string // มาจาก flag เช่น -profile, -set KEY=VALUE
+}
+
+// Load อ่าน config จากทุกแหล่งแล้ว validate
+func Load(opts LoadOptions) (*Config, error) {
+	values := map[string]string{}
+
+	// .env ในโฟลเดอร์ปัจจุบัน (ไม่มีก็ได้: docker / CI ใช้ env ของ process)
+	if m, err := godotenv.Read(); err == nil {
+		for k, v := range m {
+			values[k] = v
+		}
+	}
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		m, err := godotenv.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %w", file, err)
+		}
+		for k, v := range m {
+			values[k] = v
+		}
+	}
+
+	lookup := func(key string) (string, bool) {
+		if v, ok := opts.Overrides[key]; ok {
+			return v, true
+		}
+		if v, ok := os.LookupEnv(key); ok {
+			return v, true
+		}
+		v, ok := values[key]
+		return v, ok
+	}
+
+	profile := "dev"
+	if v, ok := lookup("APP_ENV"); ok && v != "" {
+		profile = v
+	}
+	cfg, err := build(profile, lookup)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	return &Config{
-		DBDriver:       getEnv("DB_DRIVER", "sqlite"),
-		DBSource:       getEnv("DB_SOURCE", "test.db"),
-		Port:           getEnv("PORT", "8000"),
-		JWTSecret:      getEnv("JWT_SECRET", "changeme"),
-		JWTTTL:         time.Duration(24) * time.Hour,
-		EasySlipAPIKey: os.Getenv("EASYSLIP_API_KEY"),
+// Default คืน config ของ profile จาก default ล้วน ๆ (ไม่อ่าน env / ไฟล์) — ใช้กับ test
+func Default(profile string) *Config {
+	cfg, err := build(profile, func(string) (string, bool) { return "", false })
+	if err != nil {
+		panic(err) // tag default เขียนผิด
 	}
+	return cfg
 }
 
-func getEnv(key, fallback string) string {
-	if v, ok := os.LookupEnv(key); ok {
-		return v
+func build(profile string, lookup func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("env")
+		raw, ok := lookup(key)
+		if !ok {
+			raw, ok = profileDefaults[profile][key]
+		}
+		if !ok {
+			raw = f.Tag.Get("default")
+		}
+		if key == "APP_ENV" {
+			raw = profile
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q: %w", key, raw, err))
+		}
 	}
-	return fallback
+	return cfg, errors.Join(errs...)
+}
+
+func setField(fv reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	switch fv.Interface().(type) {
+	case string:
+		fv.SetString(raw)
+	case bool:
+		if raw == "" {
+			fv.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case int, int64:
+		if raw == "" {
+			fv.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case time.Duration:
+		if raw == "" {
+			fv.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+	case *time.Location:
+		if raw == "" {
+			raw = "Local"
+		}
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(loc))
+	case []string:
+		var list []string
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		fv.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported config type %s", fv.Type())
+	}
+	return nil
+}
+
+// Validate ตรวจค่าที่ใช้ไม่ได้ / ไม่ปลอดภัย (รวมทุกข้อผิดพลาดไว้ใน error เดียว)
+func (c *Config) Validate() error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	prod := c.Profile == "prod"
+	switch c.Profile {
+	case "dev", "test", "prod":
+	default:
+		bad("APP_ENV must be dev, test or prod (got %q)", c.Profile)
+	}
+	switch c.DBDriver {
+	case "sqlite", "sqlite3", "postgres", "postgresql", "pg", "mysql":
+	default:
+		bad("DB_DRIVER %q is not supported", c.DBDriver)
+	}
+	if c.DBSource == "" {
+		bad("DB_SOURCE is required")
+	}
+	if p, err := strconv.Atoi(c.Port); err != nil || p <= 0 || p > 65535 {
+		bad("PORT must be a TCP port (got %q)", c.Port)
+	}
+	if _, err := logx.ParseLevel(c.LogLevel); err != nil {
+		bad("LOG_LEVEL: %v", err)
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		bad("LOG_FORMAT must be text or json (got %q)", c.LogFormat)
+	}
+
+	if c.JWTSecret == "" {
+		bad("JWT_SECRET is required")
+	}
+	if prod && (c.JWTSecret == "changeme" || len(c.JWTSecret) < 32) {
+		bad("JWT_SECRET must be a random value of at least 32 characters in prod")
+	}
+	if c.JWTTTL <= 0 || c.RefreshTTL <= 0 {
+		bad("JWT_TTL and JWT_REFRESH_TTL must be positive")
+	}
+
+	if prod && c.EasySlipAPIKey == "" {
+		bad("EASYSLIP_API_KEY is required in prod")
+	}
+	if (c.APNsAuthToken == "") != (c.APNsTopic == "") {
+		bad("APNS_AUTH_TOKEN and APNS_TOPIC must be set together")
+	}
+	if prod {
+		if len(c.CORSOrigins) == 0 {
+			bad("CORS_ORIGINS is required in prod")
+		}
+		for _, o := range c.CORSOrigins {
+			if o == "*" {
+				bad("CORS_ORIGINS must not contain * in prod")
+			}
+		}
+		if c.SeedDemo {
+			bad("SEED_DEMO must be false in prod")
+		}
+	}
+
+	switch c.FrameOptions {
+	case "DENY", "SAMEORIGIN":
+	default:
+		bad("FRAME_OPTIONS must be DENY or SAMEORIGIN (got %q)", c.FrameOptions)
+	}
+	if c.HSTSMaxAge < 0 {
+		bad("HSTS_MAX_AGE must not be negative")
+	}
+
+	if c.LoginMaxFailures < 0 {
+		bad("LOGIN_MAX_FAILURES must not be negative")
+	}
+	if c.LoginMaxFailures > 0 && (c.LoginLockout <= 0 || c.LoginLockoutMax < c.LoginLockout) {
+		bad("LOGIN_LOCKOUT must be positive and not exceed LOGIN_LOCKOUT_MAX")
+	}
+
+	if c.MaxSlipBytes <= 0 || c.MaxAvatarBytes <= 0 {
+		bad("MAX_SLIP_BYTES and MAX_AVATAR_BYTES must be positive")
+	}
+	if c.DefaultDeliveryFee < 0 {
+		bad("DEFAULT_DELIVERY_FEE must not be negative")
+	}
+	// HTTP timeout 0 = ไม่จำกัด; timeout ฝั่ง upstream ต้องมีเสมอ
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		bad("HTTP_*_TIMEOUT must not be negative")
+	}
+	if c.WSMaxMessageBytes <= 0 || c.WSSendBuffer <= 0 || c.WSPongWait <= 0 {
+		bad("WS_MAX_MESSAGE_BYTES, WS_SEND_BUFFER and WS_PONG_WAIT must be positive")
+	}
+	switch c.ChatBroker {
+	case "memory":
+	case "redis":
+		if c.RedisURL == "" {
+			bad("REDIS_URL is required when CHAT_BROKER=redis")
+		}
+	default:
+		bad("CHAT_BROKER must be memory or redis")
+	}
+	if c.ShutdownTimeout <= 0 {
+		bad("SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.SlipVerifyTimeout <= 0 || c.UpstreamTimeout <= 0 {
+		bad("SLIP_VERIFY_TIMEOUT and UPSTREAM_TIMEOUT must be positive")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n  %w", joinLines(errs))
+	}
+	return nil
+}
+
+func joinLines(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n  "))
+}
+
+// รหัสผ่านใน DSN: postgres "password=xxx", URL "scheme://user:xxx@", mysql "user:xxx@tcp(...)"
+var (
+	dsnKeyPassword   = regexp.MustCompile(`(password=)\S+`)
+	dsnURLPassword   = regexp.MustCompile(`(://[^:/@\s]+:)[^@]*(@)`)
+	dsnMySQLPassword = regexp.MustCompile(`^([^:/@\s]+:)[^@]*(@)`)
+)
+
+func redactDSN(dsn string) string {
+	dsn = dsnKeyPassword.ReplaceAllString(dsn, "${1}***")
+	if strings.Contains(dsn, "://") {
+		return dsnURLPassword.ReplaceAllString(dsn, "${1}***${2}")
+	}
+	return dsnMySQLPassword.ReplaceAllString(dsn, "${1}***${2}")
+}
+
+// Effective คืนค่าที่ใช้จริงทีละบรรทัด (KEY=value) โดยปิดค่าลับไว้
+func (c *Config) Effective() []string {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	lines := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		val := fmt.Sprint(v.Field(i).Interface())
+		if list, ok := v.Field(i).Interface().([]string); ok {
+			val = strings.Join(list, ",")
+		}
+		switch {
+		case f.Tag.Get("secret") == "true" && val != "":
+			val = "***"
+		case f.Name == "DBSource":
+			val = redactDSN(val)
+		}
+		lines = append(lines, f.Tag.Get("env")+"="+val)
+	}
+	return lines
+}
+
+// LogEffective พิมพ์ค่าที่ใช้จริงตอนเริ่ม server
+func (c *Config) LogEffective() {
+	slog.Info("config loaded", "profile", c.Profile, "settings", c.Effective())
 }
 
 // Helper เผื่อไฟล์อื่นต้องใช้ (เช่น seed)
@@ -1,41 +1,95 @@
 package configs
 
 import (
+	"fmt"
 	"log"
+	"log/slog"
+	"time"
 
 	"backend/entity"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var db *gorm.DB
 
 func DB() *gorm.DB { return db }
 
-func ConnectionDB() {
-	cfg := LoadConfig()
+// ConnectionDB เปิด DB หลักของแอปจาก config ที่โหลดแล้ว (ดู DB())
+func ConnectionDB(cfg *Config) {
+	database, err := Open(cfg)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	db = database
+}
 
-	var (
-		database *gorm.DB
-		err      error
-	)
+// CloseDB ปิด connection pool ของ DB หลัก (เรียกตอน shutdown หลัง request/worker หยุดหมดแล้ว)
+func CloseDB() error {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
 
+// Open เปิด DB ตาม DB_DRIVER (sqlite | postgres | mysql) แล้วตั้งค่า connection pool
+func Open(cfg *Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
 	switch cfg.DBDriver {
-	case "sqlite":
-		database, err = gorm.Open(sqlite.Open(cfg.DBSource), &gorm.Config{})
+	case "sqlite", "sqlite3":
+		dialector = sqlite.Open(cfg.DBSource)
+	case "postgres", "postgresql", "pg":
+		dialector = postgres.Open(cfg.DBSource)
+	case "mysql":
+		// DSN ต้องมี parseTime=True เพื่อ scan DATETIME เป็น time.Time
+		dialector = mysql.Open(cfg.DBSource)
 	default:
-		log.Fatalf("unsupported DB driver: %s", cfg.DBDriver)
+		return nil, fmt.Errorf("unsupported DB driver: %s", cfg.DBDriver)
 	}
 
+	// log ของ gorm ผ่าน slog ด้วย (ไม่ใส่ค่าพารามิเตอร์ของ SQL กันข้อมูลผู้ใช้หลุดลง log)
+	database, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
+	})
 	if err != nil {
-		log.Fatalf("failed to connect database: %v", err)
+		return nil, err
 	}
-	db = database
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DBMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	}
+	if cfg.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	}
+	if cfg.DBConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	}
+	if cfg.DBConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+	}
+	return database, nil
 }
 
-func SetupDatabase() {
-	// AutoMigrate ทั้งหมดของคุณ (มี entity อยู่แล้ว)
-	if err := db.AutoMigrate(
+// Models = entity ทั้งหมดที่ต้องมีตาราง (เรียงตาม dependency ของ foreign key)
+// schema จริงสร้างผ่าน package migrations; รายการนี้ใช้ตอนล้างตาราง (เช่นใน testkit)
+func Models() []any {
+	return []any{
 		&entity.User{}, &entity.Admin{},
 		&entity.RestaurantCategory{}, &entity.RestaurantStatus{}, &entity.Restaurant{},
 		&entity.MenuType{}, &entity.MenuStatus{}, &entity.Menu{},
@@ -43,12 +97,15 @@ func SetupDatabase() {
 		&entity.Cart{}, &entity.CartItem{},
 		&entity.PaymentMethod{}, &entity.PaymentStatus{}, &entity.Payment{},
 		&entity.RiderStatus{}, &entity.Rider{}, &entity.RiderWork{},
-		&entity.ChatRoom{}, &entity.MessageType{}, &entity.Message{},
+		&entity.ChatRoom{}, &entity.MessageType{}, &entity.Message{}, &entity.ChatRead{},
 		&entity.PromoType{}, &entity.Promotion{}, &entity.UserPromotion{},
 		&entity.Review{},
 		&entity.IssueType{}, &entity.Report{},
-		&entity.RestaurantApplication{},&entity.RiderApplication{},
-	); err != nil {
-		log.Fatalf("auto-migrate failed: %v", err)
+		&entity.RestaurantApplication{}, &entity.RiderApplication{},
+		&entity.WebhookEndpoint{}, &entity.WebhookDelivery{},
+		&entity.DeviceToken{}, &entity.NotificationPreference{},
+		&entity.Notification{},
+		&entity.IdempotencyKey{},
+		&entity.LoginAttempt{},
 	}
 }
@@ -0,0 +1,68 @@
+package configs
+
+import (
+	"testing"
+
+	"backend/entity"
+	"backend/migrations"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSeedDB(t *testing.T, migrate bool) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := conn.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if migrate {
+		if _, err := migrations.Up(conn); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+	return conn
+}
+
+func TestSeedLookupTablesReturnsError(t *testing.T) {
+	// ยังไม่ migrate = ไม่มีตาราง → ต้องได้ error ไม่ใช่ nil เงียบ ๆ
+	if err := SeedLookupTables(openSeedDB(t, false)); err == nil {
+		t.Fatal("seed without tables: err = nil")
+	}
+}
+
+func TestSeedIsIdempotent(t *testing.T) {
+	conn := openSeedDB(t, true)
+	prev := db
+	db = conn
+	t.Cleanup(func() { db = prev })
+
+	counts := func() [3]int64 {
+		var c [3]int64
+		conn.Model(&entity.OrderStatus{}).Count(&c[0])
+		conn.Model(&entity.User{}).Count(&c[1])
+		conn.Model(&entity.Menu{}).Count(&c[2])
+		return c
+	}
+	var first [3]int64
+	for run := 1; run <= 2; run++ {
+		if err := SeedLookups(); err != nil {
+			t.Fatalf("run %d: seed lookups: %v", run, err)
+		}
+		if err := SeedDemoData(); err != nil {
+			t.Fatalf("run %d: seed demo data: %v", run, err)
+		}
+		if run == 1 {
+			first = counts()
+			if first[0] == 0 || first[1] == 0 || first[2] == 0 {
+				t.Fatalf("nothing seeded: %v", first)
+			}
+		} else if got := counts(); got != first {
+			t.Fatalf("second run changed counts: %v → %v", first, got)
+		}
+	}
+}
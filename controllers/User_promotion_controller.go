@@ -1,10 +1,9 @@
 package controllers
 
 import (
-	"errors"
-	"net/http"
 	"strconv"
 
+	"backend/pkg/resp"
 	"backend/services"
 	"github.com/gin-gonic/gin"
 )
@@ -30,10 +29,16 @@ func getUserID(c *gin.Context) (uint, bool) {
 			return id, true
 		}
 	}
-	c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	resp.Unauthorized(c, "unauthorized")
 	return 0, false
 }
 
+// ส่งชื่อไหนก็ได้ (FE เก่าใช้ promoId)
+type PromotionIDReq struct {
+	PromoId     uint `json:"promoId"`
+	PromotionId uint `json:"promotionId"`
+}
+
 // ---------- POST /user/promotions |  POST /user/promotions/:id ----------
 func (ctrl *UserPromotionController) SavePromotion(c *gin.Context) {
 	userID, ok := getUserID(c)
@@ -46,7 +51,7 @@ func (ctrl *UserPromotionController) SavePromotion(c *gin.Context) {
 	if idStr := c.Param("id"); idStr != "" {
 		n, err := strconv.Atoi(idStr)
 		if err != nil || n <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+			resp.BadRequest(c, "invalid promotion id")
 			return
 		}
 		promoID = uint(n)
@@ -54,12 +59,9 @@ func (ctrl *UserPromotionController) SavePromotion(c *gin.Context) {
 
 	// 2) จาก JSON body
 	if promoID == 0 {
-		var body struct {
-			PromoId     uint `json:"promoId"`
-			PromotionId uint `json:"promotionId"`
-		}
+		var body PromotionIDReq
 		if err := c.ShouldBindJSON(&body); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+			resp.BadRequest(c, "invalid json")
 			return
 		}
 		promoID = body.PromoId
@@ -69,24 +71,17 @@ func (ctrl *UserPromotionController) SavePromotion(c *gin.Context) {
 	}
 
 	if promoID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing promoId / promotionId"})
+		resp.BadRequest(c, "missing promoId / promotionId")
 		return
 	}
 
 	// เรียก service
 	if err := ctrl.userPromotionService.SavePromotion(userID, promoID); err != nil {
-		switch {
-		case errors.Is(err, services.ErrAlreadySaved):
-			c.JSON(http.StatusConflict, gin.H{"error": "promotion already saved"})
-		case errors.Is(err, services.ErrPromotionNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save promotion"})
-		}
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "promotion saved"})
+	resp.Created(c, gin.H{"message": "promotion saved"})
 }
 
 // ---------- POST /user/promotions/:id/use  |  POST /user/promotions/use ----------
@@ -101,16 +96,13 @@ func (ctrl *UserPromotionController) UsePromotion(c *gin.Context) {
 	if idStr := c.Param("id"); idStr != "" {
 		n, err := strconv.Atoi(idStr)
 		if err != nil || n <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+			resp.BadRequest(c, "invalid promotion id")
 			return
 		}
 		promoID = uint(n)
 	}
 	if promoID == 0 {
-		var body struct {
-			PromoId     uint `json:"promoId"`
-			PromotionId uint `json:"promotionId"`
-		}
+		var body PromotionIDReq
 		if err := c.ShouldBindJSON(&body); err == nil {
 			promoID = body.PromoId
 			if promoID == 0 {
@@ -119,22 +111,15 @@ func (ctrl *UserPromotionController) UsePromotion(c *gin.Context) {
 		}
 	}
 	if promoID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing promoId / promotionId"})
+		resp.BadRequest(c, "missing promoId / promotionId")
 		return
 	}
 
 	if err := ctrl.userPromotionService.UsePromotion(userID, promoID); err != nil {
-		switch {
-		case errors.Is(err, services.ErrUserPromotionNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "user promotion not found"})
-		case errors.Is(err, services.ErrAlreadyUsed):
-			c.JSON(http.StatusConflict, gin.H{"error": "promotion already used"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to use promotion"})
-		}
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "promotion used"})
+	resp.OK(c, gin.H{"message": "promotion used"})
 }
 
 // ---------- GET /user/promotions ----------
@@ -147,8 +132,8 @@ func (ctrl *UserPromotionController) List(c *gin.Context) {
 	// แนะนำให้ service ทำ DB.Preload("Promotion") ด้วย
 	rows, err := ctrl.userPromotionService.List(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list user promotions"})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, rows)
+	resp.OK(c, rows)
 }
@@ -2,15 +2,14 @@ package controllers
 
 import (
 	"fmt"
-	"net/http"
 	"strconv"
 	"time"
 
 	"backend/entity"
+	"backend/lookups"
 	"backend/pkg/resp"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
 )
 
@@ -34,13 +33,13 @@ func (ac *AdminController) Dashboard(c *gin.Context) {
 
 	// ผู้ใช้ทั้งหมด
 	if err := db.Model(&entity.User{}).Count(&totalUsers).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "count users failed"})
+		resp.Error(c, err)
 		return
 	}
 
 	// ร้านทั้งหมด
 	if err := db.Model(&entity.Restaurant{}).Count(&totalRestaurants).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "count restaurants failed"})
+		resp.Error(c, err)
 		return
 	}
 
@@ -48,7 +47,7 @@ func (ac *AdminController) Dashboard(c *gin.Context) {
 	if err := db.Model(&entity.RestaurantApplication{}).
 		Where("status = ?", "pending").
 		Count(&pendingApps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "count pending application failed"})
+		resp.Error(c, err)
 		return
 	}
 
@@ -57,12 +56,12 @@ func (ac *AdminController) Dashboard(c *gin.Context) {
 	if err := db.Model(&entity.Order{}).
 		Where("created_at >= ?", start).
 		Count(&ordersToday).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "count orders today failed"})
+		resp.Error(c, err)
 		return
 	}
 
 	// ตอบกลับ
-	c.JSON(http.StatusOK, gin.H{
+	resp.OK(c, gin.H{
 		"totalUser":           totalUsers,
 		"totalRestaurants":    totalRestaurants,
 		"pendingApplications": pendingApps,
@@ -70,6 +69,14 @@ func (ac *AdminController) Dashboard(c *gin.Context) {
 	})
 }
 
+type AdminRestaurantRow struct {
+	ID                 uint      `json:"id"`
+	Name               string    `json:"name"`
+	RestaurantStatusID uint      `json:"restaurantStatusId"`
+	UserID             uint      `json:"ownerUserId"`
+	CreatedAt          time.Time `json:"createdAt"`
+}
+
 // รายการร้าน (page/limit)
 func (ac *AdminController) Restaurants(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -85,14 +92,7 @@ func (ac *AdminController) Restaurants(c *gin.Context) {
 	var total int64
 	ac.DB.Model(&entity.Restaurant{}).Count(&total)
 
-	type row struct {
-		ID                 uint      `json:"id"`
-		Name               string    `json:"name"`
-		RestaurantStatusID uint      `json:"restaurantStatusId"`
-		UserID             uint      `json:"ownerUserId"`
-		CreatedAt          time.Time `json:"createdAt"`
-	}
-	var items []row
+	var items []AdminRestaurantRow
 	if err := ac.DB.Model(&entity.Restaurant{}).
 		Select("id, name, restaurant_status_id, user_id, created_at").
 		Order("id DESC").Limit(limit).Offset(offset).
@@ -124,15 +124,16 @@ func (ac *AdminController) Reports(c *gin.Context) {
 	resp.OK(c, gin.H{"items": items})
 }
 
+type AdminRiderRow struct {
+	ID            uint   `json:"id"`
+	UserID        uint   `json:"userId"`
+	VehiclePlate  string `json:"vehiclePlate"`
+	RiderStatusID uint   `json:"riderStatusId"`
+}
+
 // ไรเดอร์
 func (ac *AdminController) Riders(c *gin.Context) {
-	type row struct {
-		ID            uint   `json:"id"`
-		UserID        uint   `json:"userId"`
-		VehiclePlate  string `json:"vehiclePlate"`
-		RiderStatusID uint   `json:"riderStatusId"`
-	}
-	var items []row
+	var items []AdminRiderRow
 	if err := ac.DB.Model(&entity.Rider{}).
 		Select("id, user_id, vehicle_plate, rider_status_id").
 		Order("id DESC").Limit(100).
@@ -170,18 +171,18 @@ func ensureAdmin(c *gin.Context) (uint, bool) {
 	// role
 	if roleVal, has := c.Get("role"); has {
 		if roleStr, _ := roleVal.(string); roleStr != "admin" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			resp.Forbidden(c, "forbidden")
 			return 0, false
 		}
 	} else {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		resp.Forbidden(c, "forbidden")
 		return 0, false
 	}
 
 	// admin id
 	adminID, ok := getUintFromCtx(c, "userId", "id", "userID")
 	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin ID not found in token"})
+		resp.Unauthorized(c, "Admin ID not found in token")
 		return 0, false
 	}
 	return adminID, true
@@ -204,6 +205,19 @@ func parseDateFlexible(s string) (*time.Time, error) {
 	return nil, fmt.Errorf("invalid time format")
 }
 
+type AdminPromotionRow struct {
+	ID          uint              `json:"id"`
+	PromoCode   string            `json:"promoCode"`
+	PromoDetail string            `json:"promoDetail"`
+	Values      uint              `json:"values"`
+	MinOrder    int64             `json:"minOrder"`
+	StartAt     *time.Time        `json:"startAt,omitempty"`
+	EndAt       *time.Time        `json:"endAt,omitempty"`
+	PromoTypeID uint              `json:"promoTypeId"`
+	PromoType   *entity.PromoType `json:"promoType,omitempty"`
+	AdminID     uint              `json:"adminId"`
+}
+
 func (ac *AdminController) Promotions(c *gin.Context) {
 	if _, ok := ensureAdmin(c); !ok {
 		return
@@ -216,27 +230,14 @@ func (ac *AdminController) Promotions(c *gin.Context) {
 		Order("id DESC").
 		Limit(100).
 		Find(&promos).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	type row struct {
-		ID          uint              `json:"id"`
-		PromoCode   string            `json:"promoCode"`
-		PromoDetail string            `json:"promoDetail"`
-		Values      uint              `json:"values"`
-		MinOrder    int64             `json:"minOrder"`
-		StartAt     *time.Time        `json:"startAt,omitempty"`
-		EndAt       *time.Time        `json:"endAt,omitempty"`
-		PromoTypeID uint              `json:"promoTypeId"`
-		PromoType   *entity.PromoType `json:"promoType,omitempty"`
-		AdminID     uint              `json:"adminId"`
-	}
-
-	items := make([]row, 0, len(promos))
+	items := make([]AdminPromotionRow, 0, len(promos))
 	for _, p := range promos {
 		pt := p.PromoType // copy
-		items = append(items, row{
+		items = append(items, AdminPromotionRow{
 			ID:          p.ID,
 			PromoCode:   p.PromoCode,
 			PromoDetail: p.PromoDetail,
@@ -249,7 +250,7 @@ func (ac *AdminController) Promotions(c *gin.Context) {
 			AdminID:     p.AdminID,
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"items": items})
+	resp.OK(c, gin.H{"items": items})
 }
 
 // -------- Request Struct --------
@@ -276,37 +277,25 @@ func (ac *AdminController) CreatePromotion(c *gin.Context) {
 
 	// --- Bind JSON ---
 	if err := c.ShouldBindJSON(&req); err != nil {
-		// 🔎 Debug: ลองอ่าน raw body ใหม่ (ไม่ทำหาย)
-		var raw map[string]any
-		if err2 := c.ShouldBindBodyWith(&raw, binding.JSON); err2 == nil {
-			fmt.Printf("❌ Raw body: %+v\n", raw)
-		}
-		fmt.Printf("❌ Bind JSON error: %v\n", err)
-
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request body",
-			"details": err.Error(),
-		})
+		resp.Invalid(c, err)
 		return
 	}
 
 	// --- Extra validation ---
-	if req.PromoTypeID == 2 && (req.Values < 1 || req.Values > 100) {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "Value for percentage promo must be between 1 and 100",
-		})
+	if req.PromoTypeID == lookups.ID(lookups.PromoPercent) && (req.Values < 1 || req.Values > 100) {
+		resp.BadRequest(c, "Value for percentage promo must be between 1 and 100")
 		return
 	}
 
 	// --- Parse dates ---
 	st, err := parseDateFlexible(req.StartAt)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid startAt"})
+		resp.BadRequest(c, "invalid startAt")
 		return
 	}
 	et, err := parseDateFlexible(req.EndAt)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid endAt"})
+		resp.BadRequest(c, "invalid endAt")
 		return
 	}
 
@@ -323,11 +312,11 @@ func (ac *AdminController) CreatePromotion(c *gin.Context) {
 	}
 
 	if err := ac.DB.Create(&p).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"id": p.ID})
+	resp.Created(c, gin.H{"id": p.ID})
 }
 
 // PUT /admin/promotion/:id
@@ -349,19 +338,19 @@ func (ac *AdminController) UpdatePromotion(c *gin.Context) {
 	id := c.Param("id")
 	promoID, err := strconv.Atoi(id)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		resp.BadRequest(c, "Invalid promotion ID")
 		return
 	}
 
 	var req PromotionUpdateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	var promotion entity.Promotion
 	if err := ac.DB.First(&promotion, promoID).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Promotion not found"})
+		resp.BadRequest(c, "Promotion not found")
 		return
 	}
 
@@ -376,10 +365,8 @@ func (ac *AdminController) UpdatePromotion(c *gin.Context) {
 	}
 	if req.PromoTypeID != nil {
 		promotion.PromoTypeID = *req.PromoTypeID
-		if *req.PromoTypeID == 2 && req.Values != nil && (*req.Values < 1 || *req.Values > 100) {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"error": "Value for percentage promo must be between 1 and 100",
-			})
+		if *req.PromoTypeID == lookups.ID(lookups.PromoPercent) && req.Values != nil && (*req.Values < 1 || *req.Values > 100) {
+			resp.BadRequest(c, "Value for percentage promo must be between 1 and 100")
 			return
 		}
 	}
@@ -389,7 +376,7 @@ func (ac *AdminController) UpdatePromotion(c *gin.Context) {
 	if req.StartAt != nil {
 		st, err := parseDateFlexible(*req.StartAt)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid startAt"})
+			resp.BadRequest(c, "invalid startAt")
 			return
 		}
 		promotion.StartAt = st
@@ -397,18 +384,18 @@ func (ac *AdminController) UpdatePromotion(c *gin.Context) {
 	if req.EndAt != nil {
 		et, err := parseDateFlexible(*req.EndAt)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid endAt"})
+			resp.BadRequest(c, "invalid endAt")
 			return
 		}
 		promotion.EndAt = et
 	}
 
 	if err := ac.DB.Save(&promotion).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Promotion updated successfully"})
+	resp.OK(c, gin.H{"message": "Promotion updated successfully"})
 }
 
 // DELETE /admin/promotion/:id
@@ -421,14 +408,14 @@ func (ac *AdminController) DeletePromotion(c *gin.Context) {
 	id := c.Param("id")
 	promoID, err := strconv.Atoi(id)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		resp.BadRequest(c, "Invalid promotion ID")
 		return
 	}
 
 	// ตรวจว่ามีอยู่จริงก่อน
 	var promotion entity.Promotion
 	if err := ac.DB.First(&promotion, promoID).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Promotion not found"})
+		resp.BadRequest(c, "Promotion not found")
 		return
 	}
 
@@ -447,9 +434,9 @@ func (ac *AdminController) DeletePromotion(c *gin.Context) {
 		}
 		return nil
 	}); err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully (hard delete)"})
+	resp.OK(c, gin.H{"message": "Promotion deleted successfully (hard delete)"})
 }
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"backend/pkg/resp"
 	"backend/services"
-	"net/http"
+	"errors"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,50 +21,60 @@ func NewAuthController(authService *services.AuthService) *AuthController {
 	return &AuthController{authService: authService}
 }
 
+type RegisterReq struct {
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,min=6"`
+	FirstName   string `json:"firstName" binding:"required"`
+	LastName    string `json:"lastName" binding:"required"`
+	PhoneNumber string `json:"phoneNumber"`
+}
+
 // POST /auth/register
 func (a *AuthController) Register(c *gin.Context) {
-	var req struct {
-		Email       string `json:"email" binding:"required,email"`
-		Password    string `json:"password" binding:"required,min=6"`
-		FirstName   string `json:"firstName" binding:"required"`
-		LastName    string `json:"lastName" binding:"required"`
-		PhoneNumber string `json:"phoneNumber"`
-	}
+	var req RegisterReq
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	user, err := a.authService.Register(req.Email, req.Password, req.FirstName, req.LastName, req.PhoneNumber)
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"ok": true, "user": user})
+	resp.Created(c, gin.H{"user": user})
+}
+
+type LoginReq struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
 }
 
 // POST /auth/login
 func (a *AuthController) Login(c *gin.Context) {
-	var req struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
-	}
+	var req LoginReq
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
-	token, user, err := a.authService.Login(req.Email, req.Password)
+	token, user, err := a.authService.Login(c.Request.Context(), req.Email, req.Password)
+	var locked *services.AccountLockedError
+	if errors.As(err, &locked) {
+		secs := int(math.Ceil(time.Until(locked.Until).Seconds()))
+		c.Header("Retry-After", strconv.Itoa(secs))
+		resp.ErrorWith(c, err, gin.H{"retryAfter": secs})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"ok":    true,
+	resp.OK(c, gin.H{
 		"token": token,
 		"user":  user,
 	})
@@ -70,37 +84,40 @@ func (a *AuthController) Login(c *gin.Context) {
 func (a *AuthController) Me(c *gin.Context) {
 	userIDAny, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := userIDAny.(uint)
 
 	user, err := a.authService.GetProfile(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		resp.NotFound(c, "user not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true, "user": user})
+	resp.OK(c, gin.H{"user": user})
+}
+
+// field ที่ไม่ส่ง = ไม่แก้
+type UpdateMeReq struct {
+	FirstName   *string `json:"firstName"`
+	LastName    *string `json:"lastName"`
+	PhoneNumber *string `json:"phoneNumber"`
+	Address     *string `json:"address"`
 }
 
 // PATCH /auth/me
 func (a *AuthController) UpdateMe(c *gin.Context) {
 	userIDAny, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := userIDAny.(uint)
 
-	var req struct {
-		FirstName   *string `json:"firstName"`
-		LastName    *string `json:"lastName"`
-		PhoneNumber *string `json:"phoneNumber"`
-		Address     *string `json:"address"`
-	}
+	var req UpdateMeReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
@@ -120,11 +137,15 @@ func (a *AuthController) UpdateMe(c *gin.Context) {
 
 	user, err := a.authService.UpdateProfile(userID, updates)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot update profile"})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	resp.OK(c, gin.H{"user": user})
+}
+
+type UploadAvatarReq struct {
+	AvatarBase64 string `json:"avatarBase64" binding:"required"`
 }
 
 // POST /auth/me/avatar
@@ -132,21 +153,19 @@ func (a *AuthController) UploadAvatar(c *gin.Context) {
 	userIDAny, _ := c.Get("userId")
 	userID := userIDAny.(uint)
 
-	var req struct {
-		AvatarBase64 string `json:"avatarBase64" binding:"required"`
-	}
+	var req UploadAvatarReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid base64"})
+		resp.BadRequest(c, "invalid base64")
 		return
 	}
 
 	if err := a.authService.UploadAvatarBase64(userID, req.AvatarBase64); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
 	user, _ := a.authService.GetProfile(userID)
-	c.JSON(http.StatusOK, gin.H{"ok": true, "user": user})
+	resp.OK(c, gin.H{"user": user})
 }
 
 // GET /auth/me/avatar
@@ -156,16 +175,16 @@ func (a *AuthController) GetAvatar(c *gin.Context) {
 
 	b64, err := a.authService.GetAvatarBase64(userID)
 	if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
-			return
+		resp.Error(c, err)
+		return
 	}
 	if b64 == "" {
-			// ✅ ส่ง default แทน ไม่ต้อง 404
-			c.JSON(http.StatusOK, gin.H{"avatarBase64": ""})
-			return
+		// ✅ ส่ง default แทน ไม่ต้อง 404
+		resp.OK(c, gin.H{"avatarBase64": ""})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"avatarBase64": b64})
+	resp.OK(c, gin.H{"avatarBase64": b64})
 }
 
 // GET /auth/me/restaurant
@@ -174,18 +193,17 @@ func (a *AuthController) MeRestaurant(c *gin.Context) {
 	role := c.GetString("role")
 
 	if role != "owner" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: not an owner"})
-			return
+		resp.Forbidden(c, "forbidden: not an owner")
+		return
 	}
 
 	restaurant, err := a.authService.GetRestaurantByUserID(userID)
 	if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "restaurant not found"})
-			return
+		resp.NotFound(c, "restaurant not found")
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-			"ok": true,
-			"restaurant": restaurant,
+	resp.OK(c, gin.H{
+		"restaurant": restaurant,
 	})
 }
@@ -1,8 +1,8 @@
 package controllers
 
 import (
+	"backend/pkg/resp"
 	"backend/services"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +20,7 @@ func NewChatController(s *services.ChatService) *ChatController {
 func (ctl *ChatController) GetOrCreateRoom(c *gin.Context) {
 	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		resp.BadRequest(c, "invalid order id")
 		return
 	}
 
@@ -30,27 +30,32 @@ func (ctl *ChatController) GetOrCreateRoom(c *gin.Context) {
 	// ✅ ตรวจสิทธิ์ก่อน
 	ok, err := ctl.Service.CanAccessRoom(userID, uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	if !ok {
-		c.JSON(http.StatusForbidden, gin.H{"error": "no access"})
+		resp.Forbidden(c, "no access")
 		return
 	}
 
 	room, err := ctl.Service.GetOrCreateRoom(uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"room": room})
+	unread, err := ctl.Service.UnreadCount(room.ID, userID)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"room": room, "unreadCount": unread})
 }
 
 // GET /orders/:id/messages
 func (ctl *ChatController) GetMessages(c *gin.Context) {
 	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		resp.BadRequest(c, "invalid order id")
 		return
 	}
 
@@ -60,42 +65,50 @@ func (ctl *ChatController) GetMessages(c *gin.Context) {
 	// ✅ ตรวจสิทธิ์ก่อน
 	ok, err := ctl.Service.CanAccessRoom(userID, uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	if !ok {
-		c.JSON(http.StatusForbidden, gin.H{"error": "no access"})
+		resp.Forbidden(c, "no access")
 		return
 	}
 
 	room, err := ctl.Service.GetOrCreateRoom(uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
 	msgs, err := ctl.Service.GetMessages(room.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
+		return
+	}
+	// read receipt ของทุกคนในห้อง (ให้ FE แสดง "อ่านแล้ว" ได้)
+	reads, err := ctl.Service.GetReads(room.ID)
+	if err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"messages": msgs})
+	resp.OK(c, gin.H{"messages": msgs, "reads": reads})
+}
+
+type SendMessageReq struct {
+	Body          string `json:"body"`
+	TypeMessageID uint   `json:"typeMessageId"`
 }
 
 // POST /orders/:id/messages
 func (ctl *ChatController) SendMessage(c *gin.Context) {
 	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		resp.BadRequest(c, "invalid order id")
 		return
 	}
 
-	var req struct {
-		Body          string `json:"body"`
-		TypeMessageID uint   `json:"typeMessageId"`
-	}
+	var req SendMessageReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		resp.BadRequest(c, "invalid request")
 		return
 	}
 
@@ -105,25 +118,87 @@ func (ctl *ChatController) SendMessage(c *gin.Context) {
 	// ✅ ตรวจสิทธิ์ก่อน
 	ok, err := ctl.Service.CanAccessRoom(userID, uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	if !ok {
-		c.JSON(http.StatusForbidden, gin.H{"error": "no access"})
+		resp.Forbidden(c, "no access")
 		return
 	}
 
 	room, err := ctl.Service.GetOrCreateRoom(uint(orderID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
+		return
+	}
+
+	msg, err := ctl.Service.SendMessage(c.Request.Context(), room.ID, userID, req.TypeMessageID, req.Body)
+	if err != nil {
+		resp.Error(c, err)
 		return
 	}
 
-	msg, err := ctl.Service.SendMessage(room.ID, userID, req.TypeMessageID, req.Body)
+	resp.OK(c, gin.H{"message": msg})
+}
+
+type MarkReadReq struct {
+	MessageID uint `json:"messageId"` // 0 / ไม่ส่ง = อ่านถึงข้อความล่าสุด
+}
+
+// POST /orders/:id/messages/read
+func (ctl *ChatController) MarkRead(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.BadRequest(c, "invalid order id")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": msg})
+	var req MarkReadReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.BadRequest(c, "invalid request")
+			return
+		}
+	}
+
+	uidAny, _ := c.Get("userId")
+	userID := uidAny.(uint)
+
+	ok, err := ctl.Service.CanAccessRoom(userID, uint(orderID))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	if !ok {
+		resp.Forbidden(c, "no access")
+		return
+	}
+
+	room, err := ctl.Service.GetOrCreateRoom(uint(orderID))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	read, err := ctl.Service.MarkRead(c.Request.Context(), room.ID, userID, req.MessageID)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"read": read})
+}
+
+// GET /chatrooms?all=true — ห้องแชทของฉัน (ลูกค้า/ไรเดอร์) พร้อมข้อความล่าสุดและจำนวนที่ยังไม่อ่าน
+// ค่า default เอาเฉพาะ order ที่ยังไม่จบ; all=true รวม order ที่ส่งแล้ว/ยกเลิกด้วย
+func (ctl *ChatController) ListRooms(c *gin.Context) {
+	uidAny, _ := c.Get("userId")
+	userID := uidAny.(uint)
+
+	all, _ := strconv.ParseBool(c.DefaultQuery("all", "false"))
+	rooms, err := ctl.Service.ListRooms(userID, all)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"rooms": rooms})
 }
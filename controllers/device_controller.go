@@ -0,0 +1,153 @@
+// controllers/device_controller.go
+package controllers
+
+import (
+	"backend/entity"
+	"backend/pkg/resp"
+	"backend/services"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceController struct {
+	DB   *gorm.DB
+	Push *services.PushService
+}
+
+func NewDeviceController(db *gorm.DB, push *services.PushService) *DeviceController {
+	return &DeviceController{DB: db, Push: push}
+}
+
+// ---------------- DTO ----------------
+type RegisterDeviceReq struct {
+	Platform string `json:"platform" binding:"required,oneof=android ios web"`
+	Token    string `json:"token" binding:"required,max=512"`
+}
+
+type UnregisterDeviceReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type NotificationPreferenceReq struct {
+	Locale       *string `json:"locale" binding:"omitempty,oneof=th en"`
+	PushEnabled  *bool   `json:"pushEnabled"`
+	OrderUpdates *bool   `json:"orderUpdates"`
+	ChatMessages *bool   `json:"chatMessages"`
+	Applications *bool   `json:"applications"`
+}
+
+// ---------------- Handlers ----------------
+
+// POST /notifications/devices
+func (ctl *DeviceController) Register(c *gin.Context) {
+	userID := c.GetUint("userId")
+
+	var req RegisterDeviceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+
+	// token เดียวกันย้ายเจ้าของได้ (เช่น logout แล้ว login บัญชีอื่นบนเครื่องเดิม)
+	now := time.Now()
+	device := entity.DeviceToken{
+		UserID:     userID,
+		Platform:   req.Platform,
+		Token:      strings.TrimSpace(req.Token),
+		LastSeenAt: &now,
+	}
+	if err := ctl.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at", "updated_at", "deleted_at"}),
+	}).Create(&device).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	resp.OK(c, nil)
+}
+
+// DELETE /notifications/devices
+func (ctl *DeviceController) Unregister(c *gin.Context) {
+	userID := c.GetUint("userId")
+
+	var req UnregisterDeviceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+
+	if err := ctl.DB.Unscoped().
+		Where("user_id = ? AND token = ?", userID, strings.TrimSpace(req.Token)).
+		Delete(&entity.DeviceToken{}).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, nil)
+}
+
+// GET /notifications/preferences
+func (ctl *DeviceController) GetPreferences(c *gin.Context) {
+	userID := c.GetUint("userId")
+
+	pref, err := ctl.Push.Preferences(userID)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"preferences": pref})
+}
+
+// PUT /notifications/preferences
+func (ctl *DeviceController) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("userId")
+
+	var req NotificationPreferenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+
+	updates := map[string]any{}
+	if req.Locale != nil {
+		updates["locale"] = *req.Locale
+	}
+	if req.PushEnabled != nil {
+		updates["push_enabled"] = *req.PushEnabled
+	}
+	if req.OrderUpdates != nil {
+		updates["order_updates"] = *req.OrderUpdates
+	}
+	if req.ChatMessages != nil {
+		updates["chat_messages"] = *req.ChatMessages
+	}
+	if req.Applications != nil {
+		updates["applications"] = *req.Applications
+	}
+	if len(updates) == 0 {
+		resp.BadRequest(c, "no fields to update")
+		return
+	}
+
+	err := ctl.DB.Transaction(func(tx *gorm.DB) error {
+		// สร้างแถว default ก่อน (ถ้ายังไม่มี) แล้วค่อย update ด้วย map
+		// เพราะ Create ด้วย struct จะมองค่า false เป็น zero value แล้วใช้ default:true แทน
+		var pref entity.NotificationPreference
+		if err := tx.Where(entity.NotificationPreference{UserID: userID}).
+			FirstOrCreate(&pref).Error; err != nil {
+			return err
+		}
+		return tx.Model(&pref).Updates(updates).Error
+	})
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	pref, _ := ctl.Push.Preferences(userID)
+	resp.OK(c, gin.H{"preferences": pref})
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/pkg/resp"
+	"backend/services"
+
+	"gorm.io/gorm"
+)
+
+// ตาราง error ของ service → HTTP status + code (ใช้ผ่าน resp.Error)
+// error ที่ไม่อยู่ในตารางจะตอบ 500 internal_error โดยไม่ส่งข้อความจริงออกไป
+func init() {
+	for _, e := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		// not found
+		{gorm.ErrRecordNotFound, http.StatusNotFound, resp.CodeNotFound},
+		{services.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+		{services.ErrOrderNotFound, http.StatusNotFound, "order_not_found"},
+		{services.ErrMenuNotFound, http.StatusNotFound, "menu_not_found"},
+		{services.ErrRestaurantNotFound, http.StatusNotFound, "restaurant_not_found"},
+		{services.ErrCartItemNotFound, http.StatusNotFound, "cart_item_not_found"},
+		{services.ErrPaymentNotFound, http.StatusNotFound, "payment_not_found"},
+		{services.ErrPromotionNotFound, http.StatusNotFound, "promotion_not_found"},
+		{services.ErrUserPromotionNotFound, http.StatusNotFound, "user_promotion_not_found"},
+		{services.ErrNotificationNotFound, http.StatusNotFound, "notification_not_found"},
+		{services.ErrWebhookDeliveryNotFound, http.StatusNotFound, "webhook_delivery_not_found"},
+		{services.ErrMessageNotFound, http.StatusNotFound, "message_not_found"},
+
+		// สิทธิ์
+		{services.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{services.ErrNotRestaurantOwner, http.StatusForbidden, resp.CodeForbidden},
+		{services.ErrNotOrderOwner, http.StatusForbidden, resp.CodeForbidden},
+
+		// สถานะชนกัน
+		{services.ErrEmailTaken, http.StatusConflict, "email_taken"},
+		{services.ErrAlreadySaved, http.StatusConflict, "already_saved"},
+		{services.ErrAlreadyUsed, http.StatusConflict, "already_used"},
+		{services.ErrRestaurantClosed, http.StatusConflict, "restaurant_closed"},
+		{services.ErrCartChanged, http.StatusConflict, "cart_changed"},
+		{services.ErrInsufficientStock, http.StatusConflict, "insufficient_stock"},
+		{services.ErrOrderReleased, http.StatusConflict, "order_released"},
+		{services.ErrOrderNotPreparing, http.StatusConflict, "order_not_preparing"},
+
+		// input ไม่ถูกต้อง
+		{services.ErrPasswordTooShort, http.StatusBadRequest, "password_too_short"},
+		{services.ErrItemsRequired, http.StatusBadRequest, "items_required"},
+		{services.ErrMenuNotInRestaurant, http.StatusBadRequest, "menu_not_in_restaurant"},
+		{services.ErrCartEmpty, http.StatusBadRequest, "cart_empty"},
+		{services.ErrNoItemsAvailable, http.StatusBadRequest, "no_items_available"},
+		{services.ErrNothingToReorder, http.StatusUnprocessableEntity, "nothing_to_reorder"},
+		{services.ErrScheduleTooSoon, http.StatusBadRequest, "schedule_too_soon"},
+		{services.ErrScheduleTooFar, http.StatusBadRequest, "schedule_too_far"},
+		{services.ErrScheduleOutsideHours, http.StatusBadRequest, "schedule_outside_hours"},
+		{services.ErrInvalidQuota, http.StatusBadRequest, "invalid_quota"},
+		{services.ErrInvalidStock, http.StatusBadRequest, "invalid_stock"},
+		{services.ErrInvalidQty, http.StatusBadRequest, "invalid_qty"},
+		{services.ErrInvalidDeviceToken, http.StatusBadRequest, "invalid_device_token"},
+		{services.ErrUnknownNotifyKind, http.StatusBadRequest, "unknown_notification_kind"},
+		{services.ErrInvalidBase64, http.StatusBadRequest, "invalid_base64"},
+		{services.ErrSlipTooLarge, http.StatusBadRequest, "slip_too_large"},
+		{services.ErrInvalidContentType, http.StatusBadRequest, "invalid_content_type"},
+		{services.ErrNoPromptPay, http.StatusBadRequest, "no_promptpay"},
+		{services.ErrPaymentNotCompleted, http.StatusBadRequest, "payment_not_completed"},
+		{services.ErrEmptyMessage, http.StatusBadRequest, "empty_message"},
+		{services.ErrAvatarTooLarge, http.StatusBadRequest, "avatar_too_large"},
+		{services.ErrInvalidAvatarFormat, http.StatusBadRequest, "invalid_avatar_format"},
+		{services.ErrWebhookURLNotAllowed, http.StatusBadRequest, "webhook_url_not_allowed"},
+
+		// ระบบภายนอก
+		{services.ErrSlipVerifierNotConfigured, http.StatusServiceUnavailable, "slip_verifier_not_configured"},
+	} {
+		resp.Register(e.err, e.status, e.code)
+	}
+
+	resp.RegisterType[*services.AccountLockedError](http.StatusTooManyRequests, "account_locked")
+	resp.RegisterType[*services.CartConflictError](http.StatusConflict, "cart_conflict")
+	resp.RegisterType[*services.AlreadyPaidError](http.StatusConflict, "already_paid")
+	resp.RegisterType[*services.AmountMismatchError](http.StatusBadRequest, "amount_mismatch")
+
+	// error จาก EasySlip: code ตามผู้ให้บริการ, status ตามประเภท
+	resp.RegisterFunc(func(err error) *resp.APIError {
+		var ve *services.SlipVerifyError
+		if !errors.As(err, &ve) {
+			return nil
+		}
+		status := http.StatusBadGateway // token ฝั่ง server ผิด / ติดต่อไม่ได้ / อ่านผลไม่ได้
+		switch ve.Code {
+		case "duplicate_slip", "qrcode_not_found", "invalid_image":
+			status = http.StatusBadRequest
+		case "quota_exceeded":
+			status = http.StatusTooManyRequests
+		case "unauthorized", "easyslip_unreachable", "easyslip_decode_error":
+		default:
+			if ve.UpstreamStatus >= 400 {
+				status = ve.UpstreamStatus
+			}
+		}
+		return resp.New(status, ve.Code, "slip verification failed: "+ve.Code)
+	})
+}
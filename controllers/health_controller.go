@@ -0,0 +1,82 @@
+// controllers/health_controller.go
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"backend/pkg/resp"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HealthController = /healthz (process ยังอยู่) และ /readyz (พร้อมรับ traffic)
+type HealthController struct {
+	DB    *gorm.DB
+	Slips services.SlipVerifier
+
+	// dependency อื่นที่ต้องพร้อมด้วย เช่น broker ของแชท (key = ชื่อใน checks)
+	Checks map[string]services.ReadyChecker
+
+	Timeout time.Duration // เวลารวมของการตรวจ /readyz (0 = 2 วินาที)
+}
+
+func NewHealthController(db *gorm.DB, slips services.SlipVerifier) *HealthController {
+	return &HealthController{DB: db, Slips: slips}
+}
+
+// GET /healthz — ไม่แตะ dependency ภายนอก (ให้ orchestrator restart เฉพาะตอน process ค้างจริง)
+func (ctl *HealthController) Live(c *gin.Context) {
+	resp.OK(c, gin.H{"status": "ok"})
+}
+
+// GET /readyz — ตรวจ database และการตั้งค่าตัวตรวจสลิป; ไม่ผ่านข้อใด = 503
+func (ctl *HealthController) Ready(c *gin.Context) {
+	timeout := ctl.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	checks := map[string]string{}
+	ready := true
+	record := func(name string, err error) {
+		if err != nil {
+			// รายละเอียด error ลง log เท่านั้น (endpoint นี้ไม่มี auth)
+			slog.WarnContext(ctx, "readyz: check failed", "check", name, "error", err)
+			checks[name] = "fail"
+			ready = false
+			return
+		}
+		checks[name] = "ok"
+	}
+
+	sqlDB, err := ctl.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	record("database", err)
+
+	if ctl.Slips == nil {
+		record("slipVerifier", services.ErrSlipVerifierNotConfigured)
+	} else if rc, ok := ctl.Slips.(services.ReadyChecker); ok {
+		record("slipVerifier", rc.Ready(ctx))
+	} else {
+		record("slipVerifier", nil)
+	}
+
+	for name, rc := range ctl.Checks {
+		record(name, rc.Ready(ctx))
+	}
+
+	if !ready {
+		resp.ErrorWith(c, resp.New(http.StatusServiceUnavailable, resp.CodeUnavailable, "not ready"), gin.H{"checks": checks})
+		return
+	}
+	resp.OK(c, gin.H{"status": "ready", "checks": checks})
+}
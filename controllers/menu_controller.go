@@ -2,128 +2,149 @@ package controllers
 
 import (
 	"backend/entity"
-	"net/http"
+	"backend/pkg/resp"
+	"backend/services"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// MenuController = ชั้น HTTP บาง ๆ (business rule อยู่ใน services.MenuService)
 type MenuController struct {
-	DB *gorm.DB
+	Menus *services.MenuService
 }
 
-func NewMenuController(db *gorm.DB) *MenuController {
-	return &MenuController{DB: db}
+func NewMenuController(menus *services.MenuService) *MenuController {
+	return &MenuController{Menus: menus}
 }
 
 // GET /restaurants/:id/menus
 func (ctl *MenuController) ListByRestaurant(c *gin.Context) {
 	restID, _ := strconv.Atoi(c.Param("id"))
 
-	var menus []entity.Menu
-	if err := ctl.DB.
-		Preload("MenuType").
-		Preload("MenuStatus").
-		Where("restaurant_id = ?", uint(restID)).
-		Find(&menus).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	menus, err := ctl.Menus.ListByRestaurant(uint(restID))
+	if err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"items": menus})
+	resp.OK(c, gin.H{"items": menus})
 }
 
 // GET /menus/:id
 func (ctl *MenuController) Get(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	var menu entity.Menu
-	if err := ctl.DB.
-		Preload("MenuType").
-		Preload("MenuStatus").
-		First(&menu, uint(id)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "menu not found"})
+	menu, err := ctl.Menus.Get(uint(id))
+	if err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, menu)
+	resp.OK(c, menu)
 }
 
 // POST /owner/restaurants/:id/menus
 func (ctl *MenuController) Create(c *gin.Context) {
+	userID := c.GetUint("userId")
 	restID, _ := strconv.Atoi(c.Param("id"))
 
 	var req entity.Menu
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
-	req.RestaurantID = uint(restID)
 
-	if err := ctl.DB.Create(&req).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := ctl.Menus.Create(userID, uint(restID), &req); err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, req)
+	resp.Created(c, req)
 }
 
 // PATCH /owner/menus/:id
 func (ctl *MenuController) Update(c *gin.Context) {
+	userID := c.GetUint("userId")
 	id, _ := strconv.Atoi(c.Param("id"))
 
 	var req entity.Menu
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 	req.ID = uint(id)
 
-	fields := map[string]interface{}{
-		"name":          req.Name,
-		"detail":        req.Detail,
-		"price":         req.Price,
-		"image":         req.Image,
-		"menu_type_id":  req.MenuTypeID,
-		"menu_status_id": req.MenuStatusID,
-	}
-
-	if err := ctl.DB.Model(&entity.Menu{}).
-		Where("id = ?", req.ID).
-		Updates(fields).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := ctl.Menus.Update(userID, &req); err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, req)
+	resp.OK(c, req)
 }
 
 // DELETE /owner/menus/:id
 func (ctl *MenuController) Delete(c *gin.Context) {
+	userID := c.GetUint("userId")
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := ctl.DB.Delete(&entity.Menu{}, uint(id)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := ctl.Menus.Delete(userID, uint(id)); err != nil {
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "menu deleted"})
+	resp.OK(c, gin.H{"message": "menu deleted"})
+}
+
+type MenuStatusReq struct {
+	MenuStatusID uint `json:"menuStatusId"`
 }
 
 // PATCH /owner/menus/:id/status
 func (ctl *MenuController) UpdateStatus(c *gin.Context) {
+	userID := c.GetUint("userId")
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	var req struct {
-		MenuStatusID uint `json:"menuStatusId"`
-	}
+	var req MenuStatusReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
-	if err := ctl.DB.Model(&entity.Menu{}).
-		Where("id = ?", uint(id)).
-		Update("menu_status_id", req.MenuStatusID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := ctl.Menus.UpdateStatus(userID, uint(id), req.MenuStatusID); err != nil {
+		resp.Error(c, err)
 		return
 	}
+	resp.OK(c, gin.H{"message": "menu status updated"})
+}
+
+// ---- Bulk stock DTO ----
+type MenuStockIn struct {
+	MenuID          uint `json:"menuId" binding:"required"`
+	DailyQuota      *int `json:"dailyQuota"`      // ตั้ง/เปลี่ยนโควต้ารายวัน
+	StockRemaining  *int `json:"stockRemaining"`  // ไม่ส่ง = เติมเต็มโควต้า
+	DisableTracking bool `json:"disableTracking"` // true = เลิก track stock เมนูนี้
+}
+
+type BulkStockReq struct {
+	Items []MenuStockIn `json:"items" binding:"required,min=1,dive"`
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "menu status updated"})
+// PUT /owner/restaurants/:id/menus/stock
+func (ctl *MenuController) BulkUpdateStock(c *gin.Context) {
+	userID := c.GetUint("userId")
+	restID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	var req BulkStockReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+
+	items := make([]services.MenuStockUpdate, 0, len(req.Items))
+	for _, in := range req.Items {
+		items = append(items, services.MenuStockUpdate(in))
+	}
+
+	updated, err := ctl.Menus.BulkUpdateStock(userID, uint(restID), items)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"items": updated})
 }
@@ -0,0 +1,77 @@
+// controllers/notification_controller.go
+package controllers
+
+import (
+	"backend/pkg/resp"
+	"backend/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	Inbox *services.InboxService
+}
+
+func NewNotificationController(inbox *services.InboxService) *NotificationController {
+	return &NotificationController{Inbox: inbox}
+}
+
+// GET /notifications?page=&limit=&unread=true
+func (ctl *NotificationController) List(c *gin.Context) {
+	userID := c.GetUint("userId")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	items, total, err := ctl.Inbox.List(userID, unreadOnly, page, limit)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	unread, _ := ctl.Inbox.UnreadCount(userID)
+
+	resp.OK(c, gin.H{"items": items, "total": total, "page": page, "limit": limit, "unread": unread})
+}
+
+// GET /notifications/unread-count
+func (ctl *NotificationController) UnreadCount(c *gin.Context) {
+	n, err := ctl.Inbox.UnreadCount(c.GetUint("userId"))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"unread": n})
+}
+
+// PATCH /notifications/:id/read
+func (ctl *NotificationController) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		resp.BadRequest(c, "invalid notification id")
+		return
+	}
+
+	if err := ctl.Inbox.MarkRead(c.GetUint("userId"), uint(id)); err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, nil)
+}
+
+// POST /notifications/read-all
+func (ctl *NotificationController) MarkAllRead(c *gin.Context) {
+	n, err := ctl.Inbox.MarkAllRead(c.GetUint("userId"))
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"updated": n})
+}
@@ -2,53 +2,66 @@ package controllers
 
 import (
 	"backend/entity"
+	"backend/pkg/resp"
+	"backend/services"
 	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-type OrderController struct{ DB *gorm.DB }
+// OrderController = ชั้น HTTP บาง ๆ (business rule อยู่ใน services.OrderService)
+type OrderController struct {
+	Orders *services.OrderService
+}
 
-func NewOrderController(db *gorm.DB) *OrderController { return &OrderController{DB: db} }
+func NewOrderController(orders *services.OrderService) *OrderController {
+	return &OrderController{Orders: orders}
+}
 
 // ---------------- DTO ----------------
 type OrderItemIn struct {
 	MenuID uint   `json:"menuId"`
-	Qty    int    `json:"qty"`
+	Qty    int    `json:"qty" binding:"required,min=1"`
 	Note   string `json:"note"` // ✅ รับ note จาก FE
 }
 
 type CreateOrderReq struct {
 	RestaurantID  uint          `json:"restaurantId"`
-	Items         []OrderItemIn `json:"items"`
+	Items         []OrderItemIn `json:"items" binding:"dive"`
 	Address       string        `json:"address"`
-	PaymentMethod string        `json:"paymentMethod"`            // "PromptPay" | "Cash on Delivery"
-	Discount      *int64        `json:"discount,omitempty"`       // ✅ optional
-	DeliveryFee   *int64        `json:"deliveryFee,omitempty"`    // ✅ optional
+	PaymentMethod string        `json:"paymentMethod"`          // "PromptPay" | "Cash on Delivery"
+	Discount      *int64        `json:"discount,omitempty"`     // ✅ optional
+	DeliveryFee   *int64        `json:"deliveryFee,omitempty"`  // ✅ optional
+	ScheduledFor  *time.Time    `json:"scheduledFor,omitempty"` // สั่งล่วงหน้า (nil = ส่งทันที)
 }
 
 type CreateOrderRes struct {
-	ID    uint  `json:"id"`
-	Total int64 `json:"total"`
+	ID           uint       `json:"id"`
+	Total        int64      `json:"total"`
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty"`
 }
 
 type OrderSummary struct {
-	ID            uint      `json:"id"`
-	RestaurantID  uint      `json:"restaurantId"`
-	Total         int64     `json:"total"`
-	OrderStatusID uint      `json:"orderStatusId"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            uint       `json:"id"`
+	RestaurantID  uint       `json:"restaurantId"`
+	Total         int64      `json:"total"`
+	OrderStatusID uint       `json:"orderStatusId"`
+	ScheduledFor  *time.Time `json:"scheduledFor,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
 
 type CheckoutFromCartReq struct {
-	Address       string `json:"address"`
-	PaymentMethod string `json:"paymentMethod"`
-	Discount      *int64 `json:"discount,omitempty"`    // ✅ optional
-	DeliveryFee   *int64 `json:"deliveryFee,omitempty"` // ✅ optional
+	Address       string     `json:"address"`
+	PaymentMethod string     `json:"paymentMethod"`
+	Discount      *int64     `json:"discount,omitempty"`     // ✅ optional
+	DeliveryFee   *int64     `json:"deliveryFee,omitempty"`  // ✅ optional
+	ScheduledFor  *time.Time `json:"scheduledFor,omitempty"` // สั่งล่วงหน้า (nil = ส่งทันที)
+
+	// ลูกค้ายืนยันการเปลี่ยนแปลงจากผลตรวจ cart แล้ว (ราคาเปลี่ยน / ของหมด / เมนูถูกลบ)
+	ConfirmChanges bool `json:"confirmChanges"`
 }
 
 // ---- PaymentSummary DTO ----
@@ -70,6 +83,7 @@ type OrderDetailRes struct {
 	Address        string             `json:"address"`
 	RestaurantID   uint               `json:"restaurantId"`
 	OrderStatusID  uint               `json:"orderStatusId"`
+	ScheduledFor   *time.Time         `json:"scheduledFor,omitempty"`
 	Items          []entity.OrderItem `json:"items"`
 	PaymentSummary *PaymentSummary    `json:"paymentSummary,omitempty"`
 }
@@ -82,112 +96,46 @@ func (h *OrderController) Create(c *gin.Context) {
 
 	var req CreateOrderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if len(req.Items) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "items required"})
+		resp.Invalid(c, err)
 		return
 	}
 
-	// คำนวณ subtotal จากเมนูล่าสุด
-	var subtotal int64
+	lines := make([]services.OrderLine, 0, len(req.Items))
 	for _, it := range req.Items {
-		var menu entity.Menu
-		if err := h.DB.Select("id, price").First(&menu, it.MenuID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "menu not found"})
-			return
-		}
-		subtotal += menu.Price * int64(it.Qty)
+		lines = append(lines, services.OrderLine{MenuID: it.MenuID, Qty: it.Qty, Note: it.Note})
 	}
-	discount := int64(0)
-	if req.Discount != nil {
-		discount = *req.Discount
-	}
-	delivery := int64(0)
-	if req.DeliveryFee != nil {
-		delivery = *req.DeliveryFee
-	}
-	total := subtotal - discount + delivery
-	if total < 0 { total = 0 }
-
-	var out CreateOrderRes
-	if err := h.DB.Transaction(func(tx *gorm.DB) error {
-		order := entity.Order{
-			UserID:        userID,
-			RestaurantID:  req.RestaurantID,
-			Subtotal:      subtotal,
-			Discount:      discount,   // ✅ เก็บส่วนลด
-			DeliveryFee:   delivery,   // ✅ เก็บค่าส่ง
-			Total:         total,
-			Address:       req.Address,
-			OrderStatusID: 1, // Pending
-		}
-		if err := tx.Create(&order).Error; err != nil {
-			return err
-		}
-
-		for _, it := range req.Items {
-			var menu entity.Menu
-			if err := tx.Select("id, price").First(&menu, it.MenuID).Error; err != nil {
-				return err
-			}
-			oi := entity.OrderItem{
-				OrderID:   order.ID,
-				MenuID:    it.MenuID,
-				Qty:       it.Qty,
-				UnitPrice: menu.Price,
-				Total:     menu.Price * int64(it.Qty),
-				Note:      it.Note, // ✅ เก็บ note
-			}
-			if err := tx.Create(&oi).Error; err != nil {
-				return err
-			}
-		}
-
-		if req.PaymentMethod != "" {
-			pmID := uint(0)
-			switch req.PaymentMethod {
-			case "PromptPay":
-				pmID = 1
-			case "Cash on Delivery":
-				pmID = 2
-			}
-			if pmID != 0 {
-				p := entity.Payment{
-					Amount:          order.Total,
-					OrderID:         order.ID,
-					PaymentMethodID: pmID,
-					PaymentStatusID: 1, // Pending
-				}
-				if err := tx.Create(&p).Error; err != nil {
-					return err
-				}
-			}
-		}
 
-		out = CreateOrderRes{ID: order.ID, Total: order.Total}
-		return nil
-	}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	order, err := h.Orders.Create(c.Request.Context(), services.PlaceOrderInput{
+		UserID:        userID,
+		RestaurantID:  req.RestaurantID,
+		Lines:         lines,
+		Address:       req.Address,
+		PaymentMethod: req.PaymentMethod,
+		Discount:      req.Discount,
+		DeliveryFee:   req.DeliveryFee,
+		ScheduledFor:  req.ScheduledFor,
+	})
+	if err != nil {
+		writeOrderError(c, err, nil)
 		return
 	}
-
-	c.JSON(http.StatusCreated, out)
+	resp.Created(c, CreateOrderRes{ID: order.ID, Total: order.Total, ScheduledFor: order.ScheduledFor})
 }
 
 // GET /orders/profile
 func (h *OrderController) ListForMe(c *gin.Context) {
 	userID := c.MustGet("userId").(uint)
 
-	var orders []OrderSummary
-	h.DB.Model(&entity.Order{}).
-		Select("id, restaurant_id, total, order_status_id, created_at").
-		Where("user_id = ?", userID).
-		Order("id DESC").
-		Scan(&orders)
-
-	c.JSON(http.StatusOK, gin.H{"items": orders})
+	orders, err := h.Orders.ListForUser(userID)
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	out := make([]OrderSummary, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, OrderSummary(o))
+	}
+	resp.OK(c, gin.H{"items": out})
 }
 
 // GET /orders/:id
@@ -195,42 +143,24 @@ func (h *OrderController) Detail(c *gin.Context) {
 	userID := c.MustGet("userId").(uint)
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	var order entity.Order
-	if err := h.DB.Where("id = ? AND user_id = ?", id, userID).
-		First(&order).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	d, err := h.Orders.Detail(userID, uint(id))
+	if err != nil {
+		writeOrderError(c, err, nil)
 		return
 	}
 
-	var items []entity.OrderItem
-	// ดึง fields ที่ FE ใช้ + note
-	h.DB.Model(&entity.OrderItem{}).
-		Select("id, qty, unit_price, total, menu_id, order_id, note").
-		Where("order_id = ?", order.ID).Find(&items)
-
-	// payment ล่าสุด (อาจไม่มี)
-	var payment entity.Payment
-	err := h.DB.Preload("PaymentMethod").
-		Preload("PaymentStatus").
-		Where("order_id = ?", order.ID).
-		Order("id DESC").
-		First(&payment).Error
-
 	var paySummary *PaymentSummary
-	if err == nil {
+	if p := d.Payment; p != nil {
 		paySummary = &PaymentSummary{
-			MethodId:   payment.PaymentMethodID,
-			MethodName: payment.PaymentMethod.MethodName,
-			StatusId:   payment.PaymentStatusID,
-			StatusName: payment.PaymentStatus.StatusName,
-			PaidAt:     payment.PaidAt,
+			MethodId:   p.PaymentMethodID,
+			MethodName: p.PaymentMethod.MethodName,
+			StatusId:   p.PaymentStatusID,
+			StatusName: p.PaymentStatus.StatusName,
+			PaidAt:     p.PaidAt,
 		}
 	}
 
+	order := d.Order
 	res := OrderDetailRes{
 		ID:             order.ID,
 		Subtotal:       order.Subtotal,
@@ -240,10 +170,11 @@ func (h *OrderController) Detail(c *gin.Context) {
 		Address:        order.Address,
 		RestaurantID:   order.RestaurantID,
 		OrderStatusID:  order.OrderStatusID,
-		Items:          items,
+		ScheduledFor:   order.ScheduledFor,
+		Items:          d.Items,
 		PaymentSummary: paySummary,
 	}
-	c.JSON(http.StatusOK, res)
+	resp.OK(c, res)
 }
 
 // POST /orders/checkout-from-cart
@@ -252,103 +183,87 @@ func (h *OrderController) CheckoutFromCart(c *gin.Context) {
 
 	var req CheckoutFromCartReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
-	var cart entity.Cart
-	// อ้างอิงคอลัมน์ carts.user_id ตรง (schema ของคุณใช้ชื่อ user_id อยู่แล้ว)
-	if err := h.DB.Preload("Items").Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cart empty"})
+	order, validation, err := h.Orders.Checkout(c.Request.Context(), services.PlaceOrderInput{
+		UserID:         userID,
+		Address:        req.Address,
+		PaymentMethod:  req.PaymentMethod,
+		Discount:       req.Discount,
+		DeliveryFee:    req.DeliveryFee,
+		ScheduledFor:   req.ScheduledFor,
+		ConfirmChanges: req.ConfirmChanges,
+	})
+	if err != nil {
+		writeOrderError(c, err, validation)
 		return
 	}
-	if len(cart.Items) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cart empty"})
+	resp.Created(c, CreateOrderRes{ID: order.ID, Total: order.Total, ScheduledFor: order.ScheduledFor})
+}
+
+// POST /orders/:id/cancel — ลูกค้ายกเลิกได้เฉพาะ order สั่งล่วงหน้าที่ยังไม่ถูกปล่อยเข้าคิวร้าน
+func (h *OrderController) Cancel(c *gin.Context) {
+	userID := c.MustGet("userId").(uint)
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := h.Orders.CancelScheduled(userID, uint(id)); err != nil {
+		writeOrderError(c, err, nil)
 		return
 	}
+	c.Status(http.StatusNoContent)
+}
 
-	// คำนวณราคาจาก snapshot ใน cart
-	var subtotal int64
-	for _, it := range cart.Items {
-		subtotal += it.Total
-	}
-	discount := int64(0)
-	if req.Discount != nil {
-		discount = *req.Discount
-	}
-	delivery := int64(0)
-	if req.DeliveryFee != nil {
-		delivery = *req.DeliveryFee
-	}
-	total := subtotal - discount + delivery
-	if total < 0 { total = 0 }
-
-	var out CreateOrderRes
-	if err := h.DB.Transaction(func(tx *gorm.DB) error {
-		order := entity.Order{
-			UserID:        userID,
-			RestaurantID:  cart.RestaurantID,
-			Subtotal:      subtotal,
-			Discount:      discount,  // ✅
-			DeliveryFee:   delivery,  // ✅
-			Total:         total,
-			Address:       req.Address,
-			OrderStatusID: 1,
-		}
-		if err := tx.Create(&order).Error; err != nil {
-			return err
-		}
+// ---- Reorder DTO ----
+type ReorderReq struct {
+	// true = ล้างตะกร้าที่มีของร้านอื่นแล้วแทนที่ด้วยรายการจาก order เก่า
+	ReplaceCart bool `json:"replaceCart"`
+}
 
-		// copy รายการจาก cart → order (รวม note)
-		for _, it := range cart.Items {
-			oi := entity.OrderItem{
-				OrderID:   order.ID,
-				MenuID:    it.MenuID,
-				Qty:       it.Qty,
-				UnitPrice: it.UnitPrice,
-				Total:     it.Total,
-				Note:      it.Note, // ✅ copy note จาก cart item
-			}
-			if err := tx.Create(&oi).Error; err != nil {
-				return err
-			}
-		}
+type ReorderRes = services.ReorderResult
 
-		// payment pending ถ้ามีวิธีจ่าย
-		if req.PaymentMethod != "" {
-			pmID := uint(0)
-			switch req.PaymentMethod {
-			case "PromptPay":
-				pmID = 1
-			case "Cash on Delivery":
-				pmID = 2
-			}
-			if pmID != 0 {
-				p := entity.Payment{
-					Amount:          order.Total,
-					OrderID:         order.ID,
-					PaymentMethodID: pmID,
-					PaymentStatusID: 1, // Pending
-				}
-				if err := tx.Create(&p).Error; err != nil {
-					return err
-				}
-			}
-		}
+// POST /orders/:id/reorder — สร้างตะกร้าใหม่จากรายการใน order เก่า (ราคาปัจจุบัน)
+func (h *OrderController) Reorder(c *gin.Context) {
+	userID := c.MustGet("userId").(uint)
+	id, _ := strconv.Atoi(c.Param("id"))
 
-		// เคลียร์ cart items ของ user นี้ (subquery หา cart_id ที่เป็นของ user)
-		if err := tx.Where("cart_id IN (?)",
-			tx.Table("carts").Select("id").Where("user_id = ?", userID),
-		).Delete(&entity.CartItem{}).Error; err != nil {
-			return err
+	var req ReorderReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.Invalid(c, err)
+			return
 		}
+	}
 
-		out = CreateOrderRes{ID: order.ID, Total: order.Total}
-		return nil
-	}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	res, err := h.Orders.Reorder(userID, uint(id), req.ReplaceCart)
+	if errors.Is(err, services.ErrNothingToReorder) {
+		resp.ErrorWith(c, err, gin.H{"dropped": res.Dropped})
+		return
+	}
+	if err != nil {
+		writeOrderError(c, err, nil)
 		return
 	}
+	resp.OK(c, res)
+}
 
-	c.JSON(http.StatusCreated, out)
+// ---------------- Helper ----------------
+
+// writeOrderError ตอบ error จาก OrderService (status มาจากตารางใน errors.go) พร้อมข้อมูลประกอบ
+func writeOrderError(c *gin.Context, err error, validation *services.CartValidation) {
+	var conflict *services.CartConflictError
+	switch {
+	case errors.As(err, &conflict):
+		resp.ErrorWith(c, err, gin.H{
+			"cartRestaurantId": conflict.RestaurantID,
+			"hint":             "resend with replaceCart=true to replace the current cart",
+		})
+	case validation != nil && (errors.Is(err, services.ErrRestaurantClosed) ||
+		errors.Is(err, services.ErrCartChanged) ||
+		errors.Is(err, services.ErrNoItemsAvailable)):
+		resp.ErrorWith(c, err, gin.H{"validation": validation})
+	default:
+		resp.Error(c, err)
+	}
 }
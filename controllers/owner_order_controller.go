@@ -3,6 +3,11 @@ package controllers
 
 import (
 	"backend/entity"
+	"backend/lookups"
+	"backend/pkg/resp"
+	"backend/repository"
+	"backend/services"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,11 +18,13 @@ import (
 )
 
 type OwnerOrderController struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Webhooks *services.WebhookService
+	Push     *services.PushService
 }
 
-func NewOwnerOrderController(db *gorm.DB) *OwnerOrderController {
-	return &OwnerOrderController{DB: db}
+func NewOwnerOrderController(db *gorm.DB, webhooks *services.WebhookService, push *services.PushService) *OwnerOrderController {
+	return &OwnerOrderController{DB: db, Webhooks: webhooks, Push: push}
 }
 
 // ---------------- DTO ----------------
@@ -35,6 +42,10 @@ type OwnerOrderSummary struct {
 	OrderStatusID uint      `json:"orderStatusId"`
 	CreatedAt     time.Time `json:"createdAt"`
 }
+type OwnerScheduledOrder struct {
+	OwnerOrderSummary
+	ScheduledFor *time.Time `json:"scheduledFor"`
+}
 type OwnerOrderDetail struct {
 	Order entity.Order       `json:"order"`
 	Items []entity.OrderItem `json:"items"`
@@ -52,7 +63,7 @@ func (ctl *OwnerOrderController) List(c *gin.Context) {
 	if err := ctl.DB.Model(&entity.Restaurant{}).
 		Where("id = ? AND user_id = ?", restID, userID).
 		Count(&count).Error; err != nil || count == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		resp.Forbidden(c, "forbidden")
 		return
 	}
 
@@ -76,12 +87,17 @@ func (ctl *OwnerOrderController) List(c *gin.Context) {
 
 	// count
 	var total int64
+	// คิวปกติไม่รวม order สั่งล่วงหน้าที่ยังไม่ถึงเวลา (ดูที่ /orders/scheduled)
+	scheduledID := lookups.ID(lookups.OrderScheduled)
+
 	qCount := ctl.DB.Model(&entity.Order{}).Where("restaurant_id = ?", restID)
 	if statusID != nil {
 		qCount = qCount.Where("order_status_id = ?", *statusID)
+	} else {
+		qCount = qCount.Where("order_status_id <> ?", scheduledID)
 	}
 	if err := qCount.Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -98,9 +114,11 @@ func (ctl *OwnerOrderController) List(c *gin.Context) {
 		Where("o.restaurant_id = ?", restID)
 	if statusID != nil {
 		q = q.Where("o.order_status_id = ?", *statusID)
+	} else {
+		q = q.Where("o.order_status_id <> ?", scheduledID)
 	}
 	if err := q.Order("o.id DESC").Limit(limit).Offset(offset).Scan(&rows).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -117,7 +135,57 @@ func (ctl *OwnerOrderController) List(c *gin.Context) {
 			CreatedAt:     r.CreatedAt,
 		})
 	}
-	c.JSON(http.StatusOK, &OwnerOrderListOut{Items: items, Total: total, Page: page, Limit: limit})
+	resp.OK(c, &OwnerOrderListOut{Items: items, Total: total, Page: page, Limit: limit})
+}
+
+// GET /owner/restaurants/:id/orders/scheduled
+// order สั่งล่วงหน้าที่ยังไม่ถูกปล่อยเข้าคิว เรียงตามเวลาที่ลูกค้าต้องการ
+func (ctl *OwnerOrderController) Scheduled(c *gin.Context) {
+	userID := c.GetUint("userId")
+	restID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	// ✅ ตรวจสิทธิ์ร้าน
+	var count int64
+	if err := ctl.DB.Model(&entity.Restaurant{}).
+		Where("id = ? AND user_id = ?", restID, userID).
+		Count(&count).Error; err != nil || count == 0 {
+		resp.Forbidden(c, "forbidden")
+		return
+	}
+
+	var rows []struct {
+		ID, UserID, OrderStatusID uint
+		Total                     int64
+		CreatedAt                 time.Time
+		ScheduledFor              *time.Time
+		FirstName, LastName       string
+	}
+	if err := ctl.DB.Table("orders AS o").
+		Select("o.id, o.user_id, o.total, o.order_status_id, o.created_at, o.scheduled_for, u.first_name, u.last_name").
+		Joins("JOIN users u ON u.id = o.user_id").
+		Where("o.restaurant_id = ? AND o.order_status_id = ? AND o.deleted_at IS NULL",
+			restID, lookups.ID(lookups.OrderScheduled)).
+		Order("o.scheduled_for ASC").
+		Scan(&rows).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	items := make([]OwnerScheduledOrder, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, OwnerScheduledOrder{
+			OwnerOrderSummary: OwnerOrderSummary{
+				ID:            r.ID,
+				UserID:        r.UserID,
+				CustomerName:  strings.TrimSpace(r.FirstName + " " + r.LastName),
+				Total:         r.Total,
+				OrderStatusID: r.OrderStatusID,
+				CreatedAt:     r.CreatedAt,
+			},
+			ScheduledFor: r.ScheduledFor,
+		})
+	}
+	resp.OK(c, gin.H{"items": items})
 }
 
 // GET /owner/restaurants/:id/orders/:orderId
@@ -131,57 +199,65 @@ func (ctl *OwnerOrderController) Detail(c *gin.Context) {
 	if err := ctl.DB.Model(&entity.Restaurant{}).
 		Where("id = ? AND user_id = ?", restID, userID).
 		Count(&count).Error; err != nil || count == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		resp.Forbidden(c, "forbidden")
 		return
 	}
 
 	var order entity.Order
 	if err := ctl.DB.Where("id = ? AND restaurant_id = ?", orderID, restID).First(&order).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		resp.NotFound(c, "order not found")
 		return
 	}
 
 	var items []entity.OrderItem
 	ctl.DB.Where("order_id = ?", order.ID).Find(&items)
 
-	c.JSON(http.StatusOK, &OwnerOrderDetail{Order: order, Items: items})
+	resp.OK(c, &OwnerOrderDetail{Order: order, Items: items})
 }
 
 // ---------------- Actions (เปลี่ยนสถานะ) ----------------
-func (ctl *OwnerOrderController) Accept(c *gin.Context)   { ctl.updateStatus(c, "Pending", "Preparing") }
-func (ctl *OwnerOrderController) Handoff(c *gin.Context)  { ctl.updateStatus(c, "Preparing", "Delivering") }
-func (ctl *OwnerOrderController) Complete(c *gin.Context) { ctl.updateStatus(c, "Delivering", "Completed") }
-func (ctl *OwnerOrderController) Cancel(c *gin.Context)   { ctl.updateStatus(c, "Pending", "Cancelled") }
+func (ctl *OwnerOrderController) Accept(c *gin.Context) {
+	if ctl.updateStatus(c, lookups.OrderPending, lookups.OrderPreparing, services.WebhookOrderAccepted) {
+		orderID, _ := strconv.ParseUint(c.Param("orderId"), 10, 64)
+		ctl.Push.NotifyOrder(c.Request.Context(), uint(orderID), services.NotifyOrderAccepted)
+	}
+}
+func (ctl *OwnerOrderController) Handoff(c *gin.Context) {
+	ctl.updateStatus(c, lookups.OrderPreparing, lookups.OrderDelivering, services.WebhookOrderDelivering)
+}
+func (ctl *OwnerOrderController) Complete(c *gin.Context) {
+	ctl.updateStatus(c, lookups.OrderDelivering, lookups.OrderCompleted, services.WebhookOrderCompleted)
+}
+func (ctl *OwnerOrderController) Cancel(c *gin.Context) {
+	if ctl.updateStatus(c, lookups.OrderPending, lookups.OrderCancelled, services.WebhookOrderCancelled) {
+		orderID, _ := strconv.ParseUint(c.Param("orderId"), 10, 64)
+		// คืนสต็อกของเมนูที่ track ไว้
+		if err := services.RestoreOrderStock(repository.NewGormStore(ctl.DB), uint(orderID)); err != nil {
+			slog.ErrorContext(c.Request.Context(), "stock: restore failed", "orderId", orderID, "error", err)
+		}
+		ctl.Push.NotifyOrder(c.Request.Context(), uint(orderID), services.NotifyOrderCancelled)
+	}
+}
 
 // ---------------- Helper ----------------
-func (ctl *OwnerOrderController) updateStatus(c *gin.Context, fromName, toName string) {
+// คืน true เมื่อเปลี่ยนสถานะสำเร็จ
+func (ctl *OwnerOrderController) updateStatus(c *gin.Context, from, to lookups.OrderStatus, event string) bool {
 	userID := c.GetUint("userId")
 	orderID, _ := strconv.ParseUint(c.Param("orderId"), 10, 64)
 
-	// หา status id
-	var fromID, toID uint
-	if err := ctl.DB.Model(&entity.OrderStatus{}).
-		Select("id").Where("status_name = ?", fromName).First(&fromID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "status not found"})
-		return
-	}
-	if err := ctl.DB.Model(&entity.OrderStatus{}).
-		Select("id").Where("status_name = ?", toName).First(&toID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "status not found"})
-		return
-	}
+	fromID, toID := lookups.ID(from), lookups.ID(to)
 
 	// ✅ guard update
 	tx := ctl.DB.Model(&entity.Order{}).
 		Where("id = ? AND order_status_id = ?", orderID, fromID).
 		Update("order_status_id", toID)
 	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": tx.Error.Error()})
-		return
+		resp.Error(c, tx.Error)
+		return false
 	}
 	if tx.RowsAffected == 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "invalid state or already updated"})
-		return
+		resp.Conflict(c, "invalid state or already updated")
+		return false
 	}
 
 	// ตรวจว่า order belong กับร้านนี้
@@ -190,9 +266,11 @@ func (ctl *OwnerOrderController) updateStatus(c *gin.Context, fromName, toName s
 		Joins("JOIN orders o ON o.restaurant_id = r.id").
 		Where("o.id = ? AND r.user_id = ?", orderID, userID).
 		First(&rest).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
-		return
+		resp.Forbidden(c, "forbidden")
+		return false
 	}
 
+	ctl.Webhooks.DispatchOrderEvent(c.Request.Context(), uint(orderID), event)
 	c.Status(http.StatusNoContent)
+	return true
 }
@@ -1,10 +1,10 @@
 package controllers
 
 import (
-	"net/http"
 	"time"
 
 	"backend/entity"
+	"backend/pkg/resp"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -24,10 +24,10 @@ func ListActivePromotions(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := q.Order("id DESC").Find(&rows).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch promotions"})
+			resp.Error(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, rows)
+		resp.OK(c, rows)
 	}
 }
@@ -3,8 +3,9 @@ package controllers
 
 import (
 	"backend/entity"
+	"backend/pkg/resp"
+	"backend/services"
 	"fmt"
-	"net/http"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -14,31 +15,35 @@ import (
 )
 
 type ReportController struct {
-	DB *gorm.DB
+	DB   *gorm.DB
+	Push *services.PushService
 }
 
-func NewReportController(db *gorm.DB) *ReportController {
-	return &ReportController{DB: db}
+func NewReportController(db *gorm.DB, push *services.PushService) *ReportController {
+	return &ReportController{DB: db, Push: push}
+}
+
+// multipart form (แนบรูปใน field pictures ได้)
+type CreateReportReq struct {
+	Name        string `form:"name"`
+	Email       string `form:"email"`
+	PhoneNumber string `form:"phoneNumber"`
+	Description string `form:"description"`
+	IssueTypeID uint   `form:"issueTypeId"`
 }
 
 // ---------- Create ----------
 func (rc *ReportController) CreateReport(c *gin.Context) {
 	userIDAny, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := userIDAny.(uint)
 
-	var req struct {
-		Name        string `form:"name"`
-		Email       string `form:"email"`
-		PhoneNumber string `form:"phoneNumber"`
-		Description string `form:"description"`
-		IssueTypeID uint   `form:"issueTypeId"`
-	}
+	var req CreateReportReq
 	if err := c.ShouldBind(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
@@ -49,7 +54,7 @@ func (rc *ReportController) CreateReport(c *gin.Context) {
 		filename := fmt.Sprintf("report_%d_%d%s", userID, time.Now().UnixNano(), filepath.Ext(file.Filename))
 		savePath := filepath.Join("uploads", "reports", filename)
 		if err := c.SaveUploadedFile(file, savePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot save file"})
+			resp.Error(c, err)
 			return
 		}
 		picturePath = savePath
@@ -69,11 +74,11 @@ func (rc *ReportController) CreateReport(c *gin.Context) {
 	}
 
 	if err := rc.DB.Create(report).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot save report"})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"ok": true, "report": report})
+	resp.Created(c, gin.H{"report": report})
 }
 
 // ---------- User: GET /reports ----------
@@ -83,10 +88,10 @@ func (rc *ReportController) ListReports(c *gin.Context) {
 
 	var reports []entity.Report
 	if err := rc.DB.Where("user_id = ?", userID).Find(&reports).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot fetch reports"})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true, "reports": reports})
+	resp.OK(c, gin.H{"reports": reports})
 }
 
 // ---------- User: GET /reports/:id ----------
@@ -97,20 +102,24 @@ func (rc *ReportController) GetReportByID(c *gin.Context) {
 	id := c.Param("id")
 	var report entity.Report
 	if err := rc.DB.Where("id = ? AND user_id = ?", id, userID).First(&report).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+		resp.NotFound(c, "report not found")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true, "report": report})
+	resp.OK(c, gin.H{"report": report})
 }
 
 // ---------- Admin: GET /admin/reports ----------
 func (rc *ReportController) ListAllReports(c *gin.Context) {
 	var reports []entity.Report
 	if err := rc.DB.Find(&reports).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot fetch all reports"})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true, "reports": reports})
+	resp.OK(c, gin.H{"reports": reports})
+}
+
+type ReportStatusReq struct {
+	Status string `json:"status"`
 }
 
 // ---------- Admin: PATCH /admin/reports/:id/status ----------
@@ -118,15 +127,13 @@ func (rc *ReportController) UpdateReportStatus(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		resp.BadRequest(c, "invalid report id")
 		return
 	}
 
-	var req struct {
-		Status string `json:"status"`
-	}
+	var req ReportStatusReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		resp.BadRequest(c, "invalid request")
 		return
 	}
 
@@ -138,18 +145,32 @@ func (rc *ReportController) UpdateReportStatus(c *gin.Context) {
 		"closed":      true,
 	}
 	if !validStatuses[req.Status] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		resp.BadRequest(c, "invalid status")
 		return
 	}
 
-	if err := rc.DB.Model(&entity.Report{}).
-		Where("id = ?", id).
+	var report entity.Report
+	if err := rc.DB.First(&report, id).Error; err != nil {
+		resp.NotFound(c, "report not found")
+		return
+	}
+
+	prevStatus := report.Status
+	if err := rc.DB.Model(&report).
 		Update("status", req.Status).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update status"})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true, "message": "status updated"})
+	// แจ้งผู้แจ้งเรื่องเมื่อสถานะเปลี่ยนจริง
+	if prevStatus != req.Status {
+		rc.Push.Notify(c.Request.Context(), report.UserID, services.NotifyReportStatusChanged, map[string]string{
+			"reportId": strconv.Itoa(id),
+			"status":   req.Status,
+		})
+	}
+
+	resp.OK(c, gin.H{"message": "status updated"})
 }
 
 // ---------- Admin: DELETE /admin/reports/:id ----------
@@ -157,21 +178,21 @@ func (rc *ReportController) DeleteReport(c *gin.Context) {
 	// ดึง role จาก context
 	roleAny, ok := c.Get("role")
 	if !ok || roleAny.(string) != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: admin only"})
+		resp.Forbidden(c, "forbidden: admin only")
 		return
 	}
 
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		resp.BadRequest(c, "invalid report id")
 		return
 	}
 
 	if err := rc.DB.Delete(&entity.Report{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete report"})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true, "message": "report deleted"})
+	resp.OK(c, gin.H{"message": "report deleted"})
 }
@@ -4,8 +4,10 @@ package controllers
 import (
 	"backend/configs"
 	"backend/entity"
+	"backend/lookups"
+	"backend/pkg/resp"
+	"backend/services"
 	"backend/utils"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -15,12 +17,13 @@ import (
 )
 
 type RestaurantApplicationController struct {
-	DB *gorm.DB
+	DB     *gorm.DB
 	Config *configs.Config
+	Push   *services.PushService
 }
 
-func NewRestaurantApplicationController(db *gorm.DB, cfg *configs.Config) *RestaurantApplicationController {
-	return &RestaurantApplicationController{DB: db, Config: cfg}
+func NewRestaurantApplicationController(db *gorm.DB, cfg *configs.Config, push *services.PushService) *RestaurantApplicationController {
+	return &RestaurantApplicationController{DB: db, Config: cfg, Push: push}
 }
 
 // ====== Request DTO ======
@@ -33,7 +36,7 @@ type ApplyRestaurantReq struct {
 	OpeningTime          string `json:"openingTime" binding:"required"`
 	ClosingTime          string `json:"closingTime" binding:"required"`
 	RestaurantCategoryID uint   `json:"restaurantCategoryId" binding:"required"`
-	PromptPay string `json:"promptPay" binding:"required"`
+	PromptPay            string `json:"promptPay" binding:"required"`
 }
 
 // ====== Response DTO ======
@@ -102,15 +105,13 @@ func isValidPromptPay(digits string) bool {
 func (ctl *RestaurantApplicationController) Apply(c *gin.Context) {
 	var req ApplyRestaurantReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	pp := onlyDigits(req.PromptPay)
 	if !isValidPromptPay(pp) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid promptPay: ต้องเป็นเบอร์ 10 หลัก หรือเลขบัตรประชาชน 13 หลัก",
-		})
+		resp.BadRequest(c, "invalid promptPay: ต้องเป็นเบอร์ 10 หลัก หรือเลขบัตรประชาชน 13 หลัก")
 		return
 	}
 
@@ -129,11 +130,11 @@ func (ctl *RestaurantApplicationController) Apply(c *gin.Context) {
 	}
 
 	if err := ctl.DB.Create(&app).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, ApplyResponse{ID: app.ID, Status: "pending"})
+	resp.Created(c, ApplyResponse{ID: app.ID, Status: "pending"})
 }
 
 // ====== Admin ดูรายการ ======
@@ -147,11 +148,11 @@ func (ctl *RestaurantApplicationController) List(c *gin.Context) {
 		Where("status = ?", status).
 		Order("id DESC").
 		Find(&apps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	var resp []RestaurantApplicationResponse
+	var out []RestaurantApplicationResponse
 	for _, app := range apps {
 		item := RestaurantApplicationResponse{
 			ID:          app.ID,
@@ -172,10 +173,10 @@ func (ctl *RestaurantApplicationController) List(c *gin.Context) {
 		item.OwnerUser.Email = app.OwnerUser.Email
 		item.OwnerUser.PhoneNumber = app.OwnerUser.PhoneNumber
 
-		resp = append(resp, item)
+		out = append(out, item)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": resp})
+	resp.OK(c, gin.H{"items": out})
 }
 
 // ====== Admin อนุมัติ ======
@@ -191,30 +192,29 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 	// --- ตรวจสอบว่าเป็น Admin ---
 	uidAny, ok := c.Get("userId")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := uidAny.(uint)
 
 	var admin entity.Admin
 	if err := ctl.DB.Where("user_id = ?", userID).First(&admin).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not an admin"})
+		resp.Forbidden(c, "not an admin")
 		return
 	}
 
 	// --- หาใบสมัคร ---
 	var app entity.RestaurantApplication
 	if err := ctl.DB.First(&app, uint(appID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "application not found"})
+		resp.NotFound(c, "application not found")
 		return
 	}
 	if app.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "application is not pending"})
+		resp.BadRequest(c, "application is not pending")
 		return
 	}
 
 	// --- เตรียมสร้างร้าน ---
-	statusID := uint(1) // สมมติ 1 = "open"
 	now := time.Now()
 	rest := entity.Restaurant{
 		Name:                 app.Name,
@@ -224,7 +224,7 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 		OpeningTime:          app.OpeningTime,
 		ClosingTime:          app.ClosingTime,
 		RestaurantCategoryID: app.RestaurantCategoryID,
-		RestaurantStatusID:   statusID,
+		RestaurantStatusID:   lookups.ID(lookups.RestaurantOpen),
 		UserID:               app.OwnerUserID,
 		AdminID:              &admin.ID,
 		PromptPay:            app.PromptPay,
@@ -236,7 +236,7 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 	// สร้างร้าน
 	if err := tx.Create(&rest).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -246,7 +246,7 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 		Where("role = '' OR role = 'customer'").
 		Update("role", "owner").Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -256,7 +256,7 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 	app.AdminID = &admin.ID
 	if err := tx.Save(&app).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -269,19 +269,24 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 	// --- Generate token ใหม่ ---
 	accessToken, err := utils.GenerateToken(owner.ID, owner.Role, ctl.Config.JWTSecret, ctl.Config.JWTTTL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot generate new token"})
+		resp.Error(c, err)
 		return
 	}
 
 	// (ถ้าอยากมี refreshToken ด้วย → GenerateToken อีกตัวด้วย TTL ยาวกว่า)
-	refreshToken, err := utils.GenerateToken(owner.ID, owner.Role, ctl.Config.JWTSecret, time.Hour*24*7)
+	refreshToken, err := utils.GenerateToken(owner.ID, owner.Role, ctl.Config.JWTSecret, ctl.Config.RefreshTTL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot generate refresh token"})
+		resp.Error(c, err)
 		return
 	}
 
+	ctl.Push.Notify(c.Request.Context(), owner.ID, services.NotifyApplicationApproved, map[string]string{
+		"applicationType": "ร้านอาหาร",
+		"applicationId":   strconv.FormatUint(uint64(app.ID), 10),
+	})
+
 	// --- ส่งกลับ FE ---
-	c.JSON(http.StatusOK, gin.H{
+	resp.OK(c, gin.H{
 		"applicationId": app.ID,
 		"restaurantId":  rest.ID,
 		"status":        "approved",
@@ -292,8 +297,6 @@ func (ctl *RestaurantApplicationController) Approve(c *gin.Context) {
 	})
 }
 
-
-
 // ====== Admin ปฏิเสธ ======
 type RejectReq struct {
 	Reason  string `json:"reason" binding:"required"`
@@ -305,17 +308,17 @@ func (ctl *RestaurantApplicationController) Reject(c *gin.Context) {
 
 	var req RejectReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	var app entity.RestaurantApplication
 	if err := ctl.DB.First(&app, appID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "application not found"})
+		resp.NotFound(c, "application not found")
 		return
 	}
 	if app.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot reject with status " + app.Status})
+		resp.BadRequest(c, "cannot reject with status "+app.Status)
 		return
 	}
 
@@ -326,11 +329,17 @@ func (ctl *RestaurantApplicationController) Reject(c *gin.Context) {
 	app.RejectReason = &req.Reason
 
 	if err := ctl.DB.Save(&app).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, RejectResponse{
+	ctl.Push.Notify(c.Request.Context(), app.OwnerUserID, services.NotifyApplicationRejected, map[string]string{
+		"applicationType": "ร้านอาหาร",
+		"applicationId":   strconv.FormatUint(uint64(app.ID), 10),
+		"reason":          req.Reason,
+	})
+
+	resp.OK(c, RejectResponse{
 		ApplicationID: uint(appID),
 		Status:        "rejected",
 		Reason:        req.Reason,
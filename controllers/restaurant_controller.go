@@ -3,7 +3,7 @@ package controllers
 
 import (
 	"backend/entity"
-	"net/http"
+	"backend/pkg/resp"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -64,15 +64,15 @@ func (ctl *RestaurantController) List(c *gin.Context) {
 		Preload("RestaurantStatus").
 		Preload("User").
 		Find(&rests).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	var resp []RestaurantResponse
+	var out []RestaurantResponse
 	for _, r := range rests {
-		resp = append(resp, mapToRestaurantResponse(&r))
+		out = append(out, mapToRestaurantResponse(&r))
 	}
-	c.JSON(http.StatusOK, gin.H{"items": resp})
+	resp.OK(c, gin.H{"items": out})
 }
 
 // ====== Public: ดูร้านเดี่ยว ======
@@ -83,24 +83,37 @@ func (ctl *RestaurantController) Get(c *gin.Context) {
 		Preload("RestaurantStatus").
 		Preload("User").
 		First(&rest, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "restaurant not found"})
+		resp.NotFound(c, "restaurant not found")
 		return
 	}
-	resp := mapToRestaurantResponse(&rest)
-	c.JSON(http.StatusOK, resp)
+	out := mapToRestaurantResponse(&rest)
+	resp.OK(c, out)
+}
+
+// field ที่ไม่ส่ง = ไม่แก้
+type UpdateRestaurantReq struct {
+	Name                 *string `json:"name"`
+	Address              *string `json:"address"`
+	Description          *string `json:"description"`
+	PictureBase64        *string `json:"pictureBase64"`
+	OpeningTime          *string `json:"openingTime"`
+	ClosingTime          *string `json:"closingTime"`
+	LeadTimeMinutes      *int    `json:"leadTimeMinutes"`
+	RestaurantCategoryID *uint   `json:"restaurantCategoryId"`
+	RestaurantStatusID   *uint   `json:"restaurantStatusId"`
 }
 
 // ====== Owner: อัปเดตร้านของตัวเอง ======
 func (ctl *RestaurantController) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		resp.BadRequest(c, "invalid id")
 		return
 	}
 
 	uidAny, ok := c.Get("userId")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := uidAny.(uint)
@@ -110,27 +123,18 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 	if err := ctl.DB.Model(&entity.Restaurant{}).
 		Where("id = ? AND user_id = ?", id, userID).
 		Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	if count == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		resp.Forbidden(c, "forbidden")
 		return
 	}
 
 	// bind updates
-	var in struct {
-		Name                 *string `json:"name"`
-		Address              *string `json:"address"`
-		Description          *string `json:"description"`
-		PictureBase64        *string `json:"pictureBase64"`
-		OpeningTime          *string `json:"openingTime"`
-		ClosingTime          *string `json:"closingTime"`
-		RestaurantCategoryID *uint   `json:"restaurantCategoryId"`
-		RestaurantStatusID   *uint   `json:"restaurantStatusId"`
-	}
+	var in UpdateRestaurantReq
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
@@ -153,6 +157,13 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 	if in.ClosingTime != nil {
 		updates["closing_time"] = *in.ClosingTime
 	}
+	if in.LeadTimeMinutes != nil {
+		if *in.LeadTimeMinutes < 0 || *in.LeadTimeMinutes > 24*60 {
+			resp.BadRequest(c, "invalid leadTimeMinutes")
+			return
+		}
+		updates["lead_time_minutes"] = *in.LeadTimeMinutes
+	}
 	if in.RestaurantCategoryID != nil {
 		updates["restaurant_category_id"] = *in.RestaurantCategoryID
 	}
@@ -161,18 +172,18 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 	}
 
 	if len(updates) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
+		resp.BadRequest(c, "no fields to update")
 		return
 	}
 
 	if err := ctl.DB.Model(&entity.Restaurant{}).
 		Where("id = ? AND user_id = ?", id, userID).
 		Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "restaurant updated"})
+	resp.OK(c, gin.H{"message": "restaurant updated"})
 }
 
 // ====== Helper ======
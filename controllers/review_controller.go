@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"errors"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"backend/entity"
+	"backend/pkg/resp"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -86,13 +86,13 @@ func (rc *ReviewController) presentReview(r entity.Review) gin.H {
 func (rc *ReviewController) Create(c *gin.Context) {
 	uid, ok := mustUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var req CreateReviewReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
@@ -104,9 +104,9 @@ func (rc *ReviewController) Create(c *gin.Context) {
 		First(&ord).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "order not found or not belong to user"})
+			resp.BadRequest(c, "order not found or not belong to user")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+			resp.Error(c, err)
 		}
 		return
 	}
@@ -114,18 +114,18 @@ func (rc *ReviewController) Create(c *gin.Context) {
 	// 2) สถานะต้องรีวิวได้ (ตาม ID ที่กำหนด)
 	if _, ok := reviewableStatusIDs[ord.OrderStatusID]; !ok && len(reviewableStatusIDs) > 0 {
 		// ถ้า map ว่าง จะข้ามการบังคับ (เผื่อ DEV); ถ้าไม่ว่าง → ต้องอยู่ในลิสต์
-		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "order is not in a reviewable status"})
+		resp.BadRequest(c, "order is not in a reviewable status")
 		return
 	}
 
 	// 3) กัน owner รีวิวร้านตัวเอง
 	var rs entity.Restaurant
 	if err := rc.DB.Select("id, user_id").First(&rs, ord.RestaurantID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	if rs.UserID == uid {
-		c.JSON(http.StatusForbidden, gin.H{"ok": false, "error": "owners cannot review their own restaurant"})
+		resp.Forbidden(c, "owners cannot review their own restaurant")
 		return
 	}
 
@@ -142,7 +142,7 @@ func (rc *ReviewController) Create(c *gin.Context) {
 		Columns:   []clause.Column{{Name: "order_id"}}, // ชนด้วย order_id → update
 		DoUpdates: clause.AssignmentColumns([]string{"rating", "comments", "review_date"}),
 	}).Create(&rev).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -151,7 +151,7 @@ func (rc *ReviewController) Create(c *gin.Context) {
 		return db.Select("id, first_name, last_name")
 	}).First(&rev, "order_id = ?", req.OrderID).Error
 
-	c.JSON(http.StatusOK, gin.H{"ok": true, "review": rc.presentReview(rev)})
+	resp.OK(c, gin.H{"review": rc.presentReview(rev)})
 }
 
 // GET /restaurants/:id/reviews?limit=20&offset=0[&rating=4]
@@ -159,7 +159,7 @@ func (rc *ReviewController) Create(c *gin.Context) {
 func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 	rid, err := strconv.Atoi(c.Param("id"))
 	if err != nil || rid <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant id"})
+		resp.BadRequest(c, "invalid restaurant id")
 		return
 	}
 
@@ -187,7 +187,7 @@ func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 		ct = ct.Where("rating = ?", *ratingFilter)
 	}
 	if err := ct.Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "count failed"})
+		resp.Error(c, err)
 		return
 	}
 
@@ -204,7 +204,7 @@ func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 	if err := q.Order("review_date DESC").
 		Limit(limit).Offset(offset).
 		Find(&reviews).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -214,7 +214,7 @@ func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 		Where("restaurant_id = ?", rid).
 		Select("COALESCE(AVG(rating), 0) AS avg").
 		Scan(&avgRow).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "aggregate failed"})
+		resp.Error(c, err)
 		return
 	}
 
@@ -224,7 +224,7 @@ func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 		items = append(items, rc.presentReview(r))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp.OK(c, gin.H{
 		"rows":  items,
 		"avg":   avgRow.Avg, // ค่าเฉลี่ยทั้งร้าน
 		"total": total,      // จำนวนรีวิวที่ตรงกับ filter (สำหรับ paginate)
@@ -235,7 +235,7 @@ func (rc *ReviewController) ListForRestaurant(c *gin.Context) {
 func (rc *ReviewController) ListForMe(c *gin.Context) {
 	uid, ok := mustUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -258,7 +258,7 @@ func (rc *ReviewController) ListForMe(c *gin.Context) {
 			Group("r.restaurant_id, rs.name").
 			Order("last_review_date DESC").
 			Scan(&rows).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+			resp.Error(c, err)
 			return
 		}
 
@@ -271,7 +271,7 @@ func (rc *ReviewController) ListForMe(c *gin.Context) {
 				"lastReview": v.LastReviewDate,
 			})
 		}
-		c.JSON(http.StatusOK, gin.H{"ok": true, "items": items})
+		resp.OK(c, gin.H{"items": items})
 		return
 	}
 
@@ -293,7 +293,7 @@ func (rc *ReviewController) ListForMe(c *gin.Context) {
 		Order("review_date DESC").
 		Limit(limit).Offset(offset).
 		Find(&reviews).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -315,8 +315,7 @@ func (rc *ReviewController) ListForMe(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"ok":    true,
+	resp.OK(c, gin.H{
 		"items": items,
 		"meta":  gin.H{"limit": limit, "offset": offset},
 	})
@@ -326,7 +325,7 @@ func (rc *ReviewController) ListForMe(c *gin.Context) {
 func (rc *ReviewController) DetailForMe(c *gin.Context) {
 	uid, ok := mustUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	id, _ := strconv.Atoi(c.Param("id"))
@@ -340,20 +339,20 @@ func (rc *ReviewController) DetailForMe(c *gin.Context) {
 		First(&rev).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "review not found"})
+			resp.NotFound(c, "review not found")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+			resp.Error(c, err)
 		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true, "review": rc.presentReview(rev)})
+	resp.OK(c, gin.H{"review": rc.presentReview(rev)})
 }
 
 // DELETE /reviews/:id (Protected) — ลบรีวิวของตัวเองเท่านั้น
 func (rc *ReviewController) Delete(c *gin.Context) {
 	uid, ok := mustUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -362,9 +361,9 @@ func (rc *ReviewController) Delete(c *gin.Context) {
 	// ตรวจว่าเป็นของตัวเอง
 	if err := rc.DB.Where("id = ? AND user_id = ?", id, uid).
 		Delete(&entity.Review{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	resp.OK(c, nil)
 }
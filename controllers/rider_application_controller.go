@@ -3,7 +3,9 @@ package controllers
 
 import (
 	"backend/entity"
-	"net/http"
+	"backend/lookups"
+	"backend/pkg/resp"
+	"backend/services"
 	"strconv"
 	"time"
 
@@ -12,11 +14,12 @@ import (
 )
 
 type RiderApplicationController struct {
-	DB *gorm.DB
+	DB   *gorm.DB
+	Push *services.PushService
 }
 
-func NewRiderApplicationController(db *gorm.DB) *RiderApplicationController {
-	return &RiderApplicationController{DB: db}
+func NewRiderApplicationController(db *gorm.DB, push *services.PushService) *RiderApplicationController {
+	return &RiderApplicationController{DB: db, Push: push}
 }
 
 // -------- Request DTO --------
@@ -35,7 +38,7 @@ type RiderApplicationResponse struct {
 	License      string `json:"license"`
 	NationalID   string `json:"nationalId"`
 	Zone         string `json:"zone"`
-	DriveCard    string `json:"driveCard"` 
+	DriveCard    string `json:"driveCard"`
 	Status       string `json:"status"`
 	SubmittedAt  string `json:"submittedAt"`
 	User         struct {
@@ -70,13 +73,13 @@ type RejectRiderResponse struct {
 func (ctl *RiderApplicationController) Apply(c *gin.Context) {
 	var req ApplyRiderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	uidAny, ok := c.Get("userId")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := uidAny.(uint)
@@ -92,18 +95,18 @@ func (ctl *RiderApplicationController) Apply(c *gin.Context) {
 	}
 
 	if err := ctl.DB.Create(&app).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, ApplyRiderResponse{ID: app.ID, Status: "pending"})
+	resp.Created(c, ApplyRiderResponse{ID: app.ID, Status: "pending"})
 }
 
 // ========== User ดูใบสมัครของตัวเอง ==========
 func (ctl *RiderApplicationController) ListMine(c *gin.Context) {
 	uidAny, ok := c.Get("userId")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := uidAny.(uint)
@@ -116,11 +119,11 @@ func (ctl *RiderApplicationController) ListMine(c *gin.Context) {
 		q = q.Where("status = ?", status)
 	}
 	if err := q.Find(&apps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	resp := []RiderApplicationResponse{}
+	out := []RiderApplicationResponse{}
 	for _, app := range apps {
 		item := RiderApplicationResponse{
 			ID:           app.ID,
@@ -137,10 +140,10 @@ func (ctl *RiderApplicationController) ListMine(c *gin.Context) {
 		item.User.LastName = app.User.LastName
 		item.User.Email = app.User.Email
 		item.User.PhoneNumber = app.User.PhoneNumber
-		resp = append(resp, item)
+		out = append(out, item)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": resp})
+	resp.OK(c, gin.H{"items": out})
 }
 
 // ========== Admin ดูรายการ ==========
@@ -149,11 +152,11 @@ func (ctl *RiderApplicationController) List(c *gin.Context) {
 
 	var apps []entity.RiderApplication
 	if err := ctl.DB.Preload("User").Where("status = ?", status).Order("id DESC").Find(&apps).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	resp := []RiderApplicationResponse{}
+	out := []RiderApplicationResponse{}
 	for _, app := range apps {
 		item := RiderApplicationResponse{
 			ID:           app.ID,
@@ -170,10 +173,10 @@ func (ctl *RiderApplicationController) List(c *gin.Context) {
 		item.User.LastName = app.User.LastName
 		item.User.Email = app.User.Email
 		item.User.PhoneNumber = app.User.PhoneNumber
-		resp = append(resp, item)
+		out = append(out, item)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": resp})
+	resp.OK(c, gin.H{"items": out})
 }
 
 // ========== Admin อนุมัติ ==========
@@ -182,7 +185,7 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 
 	uidAny, ok := c.Get("userId")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 	userID := uidAny.(uint)
@@ -190,32 +193,20 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 	// หา admin
 	var admin entity.Admin
 	if err := ctl.DB.Where("user_id = ?", userID).First(&admin).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not an admin"})
+		resp.Forbidden(c, "not an admin")
 		return
 	}
 
 	var app entity.RiderApplication
 	if err := ctl.DB.First(&app, appID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "application not found"})
+		resp.NotFound(c, "application not found")
 		return
 	}
 	if app.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "application is not pending"})
+		resp.BadRequest(c, "application is not pending")
 		return
 	}
 
-	// หา id ของสถานะ ONLINE
-	var statusID uint
-	ctl.DB.Model(&entity.RiderStatus{}).
-		Select("id").Where("status_name = ?", "ONLINE").
-		Scan(&statusID)
-	if statusID == 0 {
-		// fallback: OFFLINE
-		ctl.DB.Model(&entity.RiderStatus{}).
-			Select("id").Where("status_name = ?", "OFFLINE").
-			Scan(&statusID)
-	}
-
 	rider := entity.Rider{
 		UserID:        app.UserID,
 		VehiclePlate:  app.VehiclePlate,
@@ -223,7 +214,7 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 		NationalID:    app.NationalID,
 		Zone:          app.Zone,
 		DriveCard:     app.DriveCard,
-		RiderStatusID: statusID,
+		RiderStatusID: lookups.ID(lookups.RiderOnline),
 		AdminID:       &admin.ID,
 	}
 
@@ -231,7 +222,7 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 	tx := ctl.DB.Begin()
 	if err := tx.Create(&rider).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -241,7 +232,7 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 		Where("role = '' OR role = 'customer' OR role IS NULL").
 		Update("role", "rider").Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
@@ -250,7 +241,7 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 	app.AdminID = &admin.ID
 	if err := tx.Save(&app).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 	tx.Commit()
@@ -258,7 +249,12 @@ func (ctl *RiderApplicationController) Approve(c *gin.Context) {
 	var user entity.User
 	ctl.DB.First(&user, app.UserID)
 
-	c.JSON(http.StatusOK, ApproveRiderResponse{
+	ctl.Push.Notify(c.Request.Context(), app.UserID, services.NotifyApplicationApproved, map[string]string{
+		"applicationType": "ไรเดอร์",
+		"applicationId":   strconv.FormatUint(uint64(app.ID), 10),
+	})
+
+	resp.OK(c, ApproveRiderResponse{
 		ApplicationID: uint(appID),
 		RiderID:       rider.ID,
 		Status:        "approved",
@@ -277,17 +273,17 @@ func (ctl *RiderApplicationController) Reject(c *gin.Context) {
 
 	var req RejectRiderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	var app entity.RiderApplication
 	if err := ctl.DB.First(&app, appID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "application not found"})
+		resp.NotFound(c, "application not found")
 		return
 	}
 	if app.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot reject with status " + app.Status})
+		resp.BadRequest(c, "cannot reject with status "+app.Status)
 		return
 	}
 
@@ -298,11 +294,17 @@ func (ctl *RiderApplicationController) Reject(c *gin.Context) {
 	app.RejectReason = &req.Reason
 
 	if err := ctl.DB.Save(&app).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, RejectRiderResponse{
+	ctl.Push.Notify(c.Request.Context(), app.UserID, services.NotifyApplicationRejected, map[string]string{
+		"applicationType": "ไรเดอร์",
+		"applicationId":   strconv.FormatUint(uint64(app.ID), 10),
+		"reason":          req.Reason,
+	})
+
+	resp.OK(c, RejectRiderResponse{
 		ApplicationID: uint(appID),
 		Status:        "rejected",
 		Reason:        req.Reason,
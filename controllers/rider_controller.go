@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"backend/entity"
-	"fmt"
-	"net/http"
+	"backend/lookups"
+	"backend/metrics"
+	"backend/pkg/resp"
+	"backend/services"
+	"backend/utils"
 	"strconv"
 	"strings"
 	"time"
@@ -13,10 +16,15 @@ import (
 )
 
 type RiderController struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Webhooks *services.WebhookService
+	Push     *services.PushService
+	Metrics  *metrics.Metrics // nil = ไม่เก็บ
 }
 
-func NewRiderController(db *gorm.DB) *RiderController { return &RiderController{DB: db} }
+func NewRiderController(db *gorm.DB, webhooks *services.WebhookService, push *services.PushService) *RiderController {
+	return &RiderController{DB: db, Webhooks: webhooks, Push: push}
+}
 
 /* =========================
    WORK HISTORIES (รวม service เข้ามา)
@@ -25,36 +33,57 @@ func NewRiderController(db *gorm.DB) *RiderController { return &RiderController{
    ส่งคืน: items, total, summary{totalTrips,totalFare}
 ========================= */
 
+type RiderWorkItem struct {
+	ID              uint       `json:"id"`
+	OrderID         uint       `json:"orderId"`
+	Address         string     `json:"address"`
+	Subtotal        int64      `json:"subtotal"`
+	Discount        int64      `json:"discount"`
+	DeliveryFee     int64      `json:"deliveryFee"`
+	Total           int64      `json:"total"`
+	WorkAt          *time.Time `json:"workAt,omitempty"`
+	FinishAt        *time.Time `json:"finishAt,omitempty"`
+	OrderStatusName string     `json:"orderStatusName,omitempty"`
+}
+
 // GET /rider/works
 func (h *RiderController) ListWorks(c *gin.Context) {
 	uid := c.GetUint("userId")
 	if uid == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		resp.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	// หา rider จาก user_id
 	var rider entity.Rider
 	if err := h.DB.Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
 	// query params
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if page <= 0 { page = 1 }
+	if page <= 0 {
+		page = 1
+	}
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	if pageSize <= 0 || pageSize > 100 { pageSize = 10 }
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
 
 	orderQ := strings.TrimSpace(c.Query("orderId"))
 	statusQ := strings.TrimSpace(c.Query("status"))
 	var dateFrom *time.Time
 	var dateTo *time.Time
 	if s := c.Query("dateFrom"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil { dateFrom = &t }
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			dateFrom = &t
+		}
 	}
 	if s := c.Query("dateTo"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil { dateTo = &t }
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			dateTo = &t
+		}
 	}
 
 	// base query — เลือกเฉพาะฟิลด์ที่มีจริง
@@ -65,7 +94,7 @@ func (h *RiderController) ListWorks(c *gin.Context) {
 
 	// filters
 	if orderQ != "" {
-		base = base.Where("CAST(o.id AS CHAR) LIKE ?", "%"+orderQ+"%")
+		base = base.Where(utils.CastToText(h.DB, "o.id")+" LIKE ?", "%"+orderQ+"%")
 	}
 	if statusQ != "" {
 		// ให้เทียบกับชื่อ status ตรง ๆ เช่น Pending/Preparing/Delivering/Completed/Cancelled
@@ -81,22 +110,22 @@ func (h *RiderController) ListWorks(c *gin.Context) {
 	// count
 	var total int64
 	if err := base.Select("rw.id").Count(&total).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
 	// row shape ที่จะ scan
 	type row struct {
-		ID               uint       `json:"id"`                // rider_works.id
-		WorkAt           *time.Time `json:"workAt"`
-		FinishAt         *time.Time `json:"finishAt"`
-		OrderID          uint       `json:"orderId"`
-		Address          string     `json:"address"`
-		Subtotal         int64      `json:"subtotal"`
-		Discount         int64      `json:"discount"`
-		DeliveryFee      int64      `json:"deliveryFee"`
-		Total            int64      `json:"total"`
-		OrderStatusName  string     `json:"orderStatusName"`
+		ID              uint       `json:"id"` // rider_works.id
+		WorkAt          *time.Time `json:"workAt"`
+		FinishAt        *time.Time `json:"finishAt"`
+		OrderID         uint       `json:"orderId"`
+		Address         string     `json:"address"`
+		Subtotal        int64      `json:"subtotal"`
+		Discount        int64      `json:"discount"`
+		DeliveryFee     int64      `json:"deliveryFee"`
+		Total           int64      `json:"total"`
+		OrderStatusName string     `json:"orderStatusName"`
 	}
 
 	var rows []row
@@ -110,31 +139,19 @@ func (h *RiderController) ListWorks(c *gin.Context) {
 			os.status_name AS order_status_name
 		`).
 		Order("rw.work_at DESC, rw.id DESC").
-		Offset((page-1)*pageSize).
+		Offset((page - 1) * pageSize).
 		Limit(pageSize).
 		Scan(&rows).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
 	// map → items ที่ FE ใช้
-	type item struct {
-		ID              uint       `json:"id"`
-		OrderID         uint       `json:"orderId"`
-		Address         string     `json:"address"`
-		Subtotal        int64      `json:"subtotal"`
-		Discount        int64      `json:"discount"`
-		DeliveryFee     int64      `json:"deliveryFee"`
-		Total           int64      `json:"total"`
-		WorkAt          *time.Time `json:"workAt,omitempty"`
-		FinishAt        *time.Time `json:"finishAt,omitempty"`
-		OrderStatusName string     `json:"orderStatusName,omitempty"`
-	}
 
-	items := make([]item, 0, len(rows))
+	items := make([]RiderWorkItem, 0, len(rows))
 	var sumTotal int64 = 0
 	for _, r := range rows {
-		items = append(items, item{
+		items = append(items, RiderWorkItem{
 			ID:              r.ID,
 			OrderID:         r.OrderID,
 			Address:         r.Address,
@@ -149,7 +166,7 @@ func (h *RiderController) ListWorks(c *gin.Context) {
 		sumTotal += r.Total
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp.OK(c, gin.H{
 		"items":   items,
 		"total":   total,
 		"summary": gin.H{"totalTrips": total, "totalFare": sumTotal},
@@ -157,26 +174,29 @@ func (h *RiderController) ListWorks(c *gin.Context) {
 }
 
 func fallback(s, alt string) string {
-	if strings.TrimSpace(s) == "" { return alt }
+	if strings.TrimSpace(s) == "" {
+		return alt
+	}
 	return s
 }
 func derefOr(t *time.Time, zero time.Time) time.Time {
-	if t != nil { return *t }
+	if t != nil {
+		return *t
+	}
 	return zero
 }
 
 // map order_status_id → FE code
 func mapOrderStatus(id uint) string {
-	switch id {
-	case getOrderStatusID(nil, "Pending"):
-		return "PENDING"
-	case getOrderStatusID(nil, "Preparing"):
+	code, _ := lookups.CodeOf[lookups.OrderStatus](id)
+	switch code {
+	case lookups.OrderPreparing:
 		return "PICKED_UP" // หรือสถานะที่ตรงที่สุดในระบบพี่
-	case getOrderStatusID(nil, "Delivering"):
+	case lookups.OrderDelivering:
 		return "PICKED_UP"
-	case getOrderStatusID(nil, "Completed"):
+	case lookups.OrderCompleted:
 		return "DELIVERED"
-	case getOrderStatusID(nil, "Cancelled"):
+	case lookups.OrderCancelled:
 		return "CANCELLED"
 	default:
 		return "PENDING"
@@ -185,7 +205,9 @@ func mapOrderStatus(id uint) string {
 
 // map payment method code/name → FE code
 func normalizePM(code *string) string {
-	if code == nil { return "CASH" }
+	if code == nil {
+		return "CASH"
+	}
 	v := strings.ToUpper(strings.TrimSpace(*code))
 	switch v {
 	case "CASH", "COD", "CASH_ON_DELIVERY":
@@ -203,44 +225,46 @@ func normalizePM(code *string) string {
    ONLINE / OFFLINE (มีอยู่แล้ว)
 ========================= */
 
+type RiderAvailabilityReq struct {
+	Status string `json:"status" binding:"required"`
+}
+
 func (h *RiderController) SetAvailability(c *gin.Context) {
 	uid := c.GetUint("userId")
-	var req struct {
-		Status string `json:"status" binding:"required"`
-	}
+	var req RiderAvailabilityReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
 	var rider entity.Rider
 	if err := h.DB.Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
 	status := strings.ToUpper(strings.TrimSpace(req.Status))
 	var statusID uint
 	if status == "ONLINE" {
-		statusID = getRiderStatusID(h.DB, "ONLINE")
+		statusID = lookups.ID(lookups.RiderOnline)
 	} else if status == "OFFLINE" {
 		var cnt int64
 		h.DB.Model(&entity.RiderWork{}).
 			Where("rider_id=? AND finish_at IS NULL", rider.ID).
 			Count(&cnt)
 		if cnt > 0 {
-			c.JSON(http.StatusConflict, gin.H{"error": "cannot go offline with active work"})
+			resp.Conflict(c, "cannot go offline with active work")
 			return
 		}
-		statusID = getRiderStatusID(h.DB, "OFFLINE")
+		statusID = lookups.ID(lookups.RiderOffline)
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		resp.BadRequest(c, "invalid status")
 		return
 	}
 
 	h.DB.Model(&entity.Rider{}).Where("id=?", rider.ID).
 		Update("rider_status_id", statusID)
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	resp.OK(c, nil)
 }
 
 /* =========================
@@ -253,17 +277,17 @@ func (h *RiderController) Accept(c *gin.Context) {
 
 	var rider entity.Rider
 	if err := h.DB.Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
-	onlineID := getRiderStatusID(h.DB, "ONLINE")
-	assignedID := getRiderStatusID(h.DB, "ASSIGNED")
-	preparingID := getOrderStatusID(h.DB, "Preparing")
-	deliveringID := getOrderStatusID(h.DB, "Delivering")
+	onlineID := lookups.ID(lookups.RiderOnline)
+	assignedID := lookups.ID(lookups.RiderAssigned)
+	preparingID := lookups.ID(lookups.OrderPreparing)
+	deliveringID := lookups.ID(lookups.OrderDelivering)
 
 	if rider.RiderStatusID != onlineID {
-		c.JSON(http.StatusConflict, gin.H{"error": "rider not online"})
+		resp.Conflict(c, "rider not online")
 		return
 	}
 
@@ -282,15 +306,31 @@ func (h *RiderController) Accept(c *gin.Context) {
 		res := tx.Model(&entity.Order{}).
 			Where("id=? AND order_status_id=?", oid, preparingID).
 			Update("order_status_id", deliveringID)
-		if res.Error != nil { return res.Error }
-		if res.RowsAffected == 0 { return fmt.Errorf("order not in preparing state") }
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return services.ErrOrderNotPreparing
+		}
 		return nil
 	})
 	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		resp.Error(c, err) // ErrOrderNotPreparing = 409; DB error = 500 ไม่ส่งข้อความจริงออกไป
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+
+	// dispatch latency: นับจากตอน order เข้าคิวร้าน (order สั่งล่วงหน้า = ตอนถูกปล่อย)
+	var queued entity.Order
+	if h.Metrics != nil && h.DB.Select("id, created_at, released_at").First(&queued, oid).Error == nil {
+		since := queued.CreatedAt
+		if queued.ReleasedAt != nil {
+			since = *queued.ReleasedAt
+		}
+		h.Metrics.ObserveDispatch(time.Since(since))
+	}
+	h.Webhooks.DispatchOrderEvent(c.Request.Context(), uint(oid), services.WebhookOrderDelivering)
+	h.Push.NotifyOrder(c.Request.Context(), uint(oid), services.NotifyRiderAssigned)
+	resp.OK(c, nil)
 }
 
 /* =========================
@@ -303,30 +343,31 @@ func (h *RiderController) Complete(c *gin.Context) {
 
 	var rider entity.Rider
 	if err := h.DB.Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
 	var order entity.Order
 	if err := h.DB.First(&order, oid).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		resp.NotFound(c, "order not found")
 		return
 	}
 
-	assignedID := getRiderStatusID(h.DB, "ASSIGNED")
-	deliveringID := getOrderStatusID(h.DB, "Delivering")
-	completedID := getOrderStatusID(h.DB, "Completed")
-	onlineID := getRiderStatusID(h.DB, "ONLINE")
+	assignedID := lookups.ID(lookups.RiderAssigned)
+	deliveringID := lookups.ID(lookups.OrderDelivering)
+	completedID := lookups.ID(lookups.OrderCompleted)
+	onlineID := lookups.ID(lookups.RiderOnline)
 
 	if rider.RiderStatusID != assignedID {
-		c.JSON(http.StatusConflict, gin.H{"error": "not assigned"})
+		resp.Conflict(c, "not assigned")
 		return
 	}
 	if order.OrderStatusID != deliveringID {
-		c.JSON(http.StatusConflict, gin.H{"error": "order not delivering"})
+		resp.Conflict(c, "order not delivering")
 		return
 	}
 
+	codPaid := false
 	err := h.DB.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
 		if err := tx.Model(&entity.RiderWork{}).
@@ -343,16 +384,13 @@ func (h *RiderController) Complete(c *gin.Context) {
 		// COD → mark paid
 		var payment entity.Payment
 		if err := tx.Where("order_id=?", order.ID).First(&payment).Error; err == nil {
-			var method entity.PaymentMethod
-			if err := tx.First(&method, payment.PaymentMethodID).Error; err == nil {
-				if strings.EqualFold(method.MethodName, "Cash on Delivery") {
-					paidID := getPaymentStatusID(tx, "Paid")
-					if err := tx.Model(&entity.Payment{}).
-						Where("id=?", payment.ID).
-						Update("payment_status_id", paidID).Error; err != nil {
-						return err
-					}
+			if payment.PaymentMethodID == lookups.ID(lookups.MethodCashOnDelivery) {
+				if err := tx.Model(&entity.Payment{}).
+					Where("id=?", payment.ID).
+					Update("payment_status_id", lookups.ID(lookups.PaymentPaid)).Error; err != nil {
+					return err
 				}
+				codPaid = true
 			}
 		}
 		return tx.Model(&entity.Rider{}).
@@ -360,36 +398,38 @@ func (h *RiderController) Complete(c *gin.Context) {
 			Update("rider_status_id", onlineID).Error
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete"})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true})
+	if codPaid {
+		h.Metrics.PaymentVerified("cod")
+	}
+	h.Webhooks.DispatchOrderEvent(c.Request.Context(), order.ID, services.WebhookOrderCompleted)
+	h.Push.NotifyOrder(c.Request.Context(), order.ID, services.NotifyOrderDelivered)
+	resp.OK(c, nil)
 }
 
-
-// ---------- HELPER ----------
-func getPaymentStatusID(db *gorm.DB, name string) uint {
-	var ps entity.PaymentStatus
-	db.Where("status_name=?", name).First(&ps)
-	return ps.ID
+// งานที่ไรเดอร์เห็น (ใช้ทั้งรายการงานว่างและงานปัจจุบัน)
+type RiderJobRow struct {
+	ID             uint      `json:"id"`
+	CreatedAt      time.Time `json:"createdAt"`
+	RestaurantName string    `json:"restaurantName"`
+	CustomerName   string    `json:"customerName"`
+	Address        string    `json:"address"`
+	Total          int64     `json:"total"`
+	FirstName      string    `json:"-"`
+	LastName       string    `json:"-"`
 }
 
 // ---------- LIST AVAILABLE ----------
 func (h *RiderController) ListAvailable(c *gin.Context) {
-	preparingID := getOrderStatusID(h.DB, "Preparing")
-	var rows []struct {
-		ID             uint      `json:"id"`
-		CreatedAt      time.Time `json:"createdAt"`
-		RestaurantName string    `json:"restaurantName"`
-		CustomerName   string    `json:"customerName"`
-		Address        string    `json:"address"`
-		Total          int64     `json:"total"`
-	}
+	preparingID := lookups.ID(lookups.OrderPreparing)
+	var rows []RiderJobRow
 
 	err := h.DB.
 		Table("orders AS o").
 		Select(`o.id, o.created_at, r.name AS restaurant_name,
-		        CONCAT(u.first_name,' ',u.last_name) AS customer_name,
+		        u.first_name, u.last_name,
 		        o.address, o.total`).
 		Joins("JOIN users u ON u.id=o.user_id").
 		Joins("JOIN restaurants r ON r.id=o.restaurant_id").
@@ -399,10 +439,14 @@ func (h *RiderController) ListAvailable(c *gin.Context) {
 		Scan(&rows).Error
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"items": rows})
+	// ต่อชื่อใน Go แทน CONCAT (แต่ละ DB ใช้ syntax ต่างกัน)
+	for i := range rows {
+		rows[i].CustomerName = strings.TrimSpace(rows[i].FirstName + " " + rows[i].LastName)
+	}
+	resp.OK(c, gin.H{"items": rows})
 }
 
 func (h *RiderController) GetStatus(c *gin.Context) {
@@ -410,12 +454,12 @@ func (h *RiderController) GetStatus(c *gin.Context) {
 	var rider entity.Rider
 	if err := h.DB.Preload("RiderStatus").
 		Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
+	resp.OK(c, gin.H{
 		"status":    rider.RiderStatus.StatusName,
-		"isWorking": rider.RiderStatus.StatusName != "OFFLINE",
+		"isWorking": rider.RiderStatusID != lookups.ID(lookups.RiderOffline),
 	})
 }
 
@@ -423,21 +467,14 @@ func (h *RiderController) GetCurrentWork(c *gin.Context) {
 	uid := c.GetUint("userId")
 	var rider entity.Rider
 	if err := h.DB.Where("user_id=?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
-	var row struct {
-		ID             uint      `json:"id"`
-		CreatedAt      time.Time `json:"createdAt"`
-		RestaurantName string    `json:"restaurantName"`
-		CustomerName   string    `json:"customerName"`
-		Address        string    `json:"address"`
-		Total          int64     `json:"total"`
-	}
+	var row RiderJobRow
 	h.DB.Table("rider_works rw").
 		Select(`o.id, o.created_at, r.name AS restaurant_name,
-		        CONCAT(u.first_name,' ',u.last_name) AS customer_name,
+		        u.first_name, u.last_name,
 		        o.address, o.total`).
 		Joins("JOIN orders o ON o.id=rw.order_id").
 		Joins("JOIN users u ON u.id=o.user_id").
@@ -446,10 +483,11 @@ func (h *RiderController) GetCurrentWork(c *gin.Context) {
 		Limit(1).Scan(&row)
 
 	if row.ID == 0 {
-		c.JSON(http.StatusOK, gin.H{"work": nil})
+		resp.OK(c, gin.H{"work": nil})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"work": row})
+	row.CustomerName = strings.TrimSpace(row.FirstName + " " + row.LastName)
+	resp.OK(c, gin.H{"work": row})
 }
 
 type RiderProfileResponse struct {
@@ -477,11 +515,11 @@ func (h *RiderController) GetProfile(c *gin.Context) {
 		Preload("RiderStatus").
 		Where("user_id = ?", uid).
 		First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
-	resp := gin.H{
+	out := gin.H{
 		"userId":       rider.User.ID,
 		"firstName":    rider.User.FirstName,
 		"lastName":     rider.User.LastName,
@@ -497,7 +535,15 @@ func (h *RiderController) GetProfile(c *gin.Context) {
 		"status":       rider.RiderStatus.StatusName,
 	}
 
-	c.JSON(http.StatusOK, resp)
+	resp.OK(c, out)
+}
+
+type UpdateRiderReq struct {
+	NationalID      string  `json:"nationalId"`
+	VehiclePlate    string  `json:"vehiclePlate"`
+	Zone            string  `json:"zone"`
+	License         string  `json:"license"`
+	DriveCardBase64 *string `json:"driveCardBase64"` // undefined = ไม่แตะ, "" = ลบ, dataURL = อัปเดต
 }
 
 // PUT /rider/me
@@ -507,20 +553,14 @@ func (h *RiderController) UpdateMe(c *gin.Context) {
 	// หา rider ของ user
 	var rider entity.Rider
 	if err := h.DB.Where("user_id = ?", uid).First(&rider).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rider not found"})
+		resp.NotFound(c, "rider not found")
 		return
 	}
 
 	// รับ request
-	var req struct {
-		NationalID      string  `json:"nationalId"`
-		VehiclePlate    string  `json:"vehiclePlate"`
-		Zone            string  `json:"zone"`
-		License         string  `json:"license"`
-		DriveCardBase64 *string `json:"driveCardBase64"` // undefined = ไม่แตะ, "" = ลบ, dataURL = อัปเดต
-	}
+	var req UpdateRiderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		resp.Invalid(c, err)
 		return
 	}
 
@@ -549,25 +589,9 @@ func (h *RiderController) UpdateMe(c *gin.Context) {
 
 	// บันทึก
 	if err := h.DB.Model(&rider).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		resp.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true})
-}
-
-
-// ---------- HELPER ----------
-func getRiderStatusID(db *gorm.DB, name string) uint {
-	var rs entity.RiderStatus
-	db.Where("status_name=?", name).First(&rs)
-	return rs.ID
-}
-func getOrderStatusID(db *gorm.DB, name string) uint {
-	var os entity.OrderStatus
-	if db != nil {
-		db.Where("status_name=?", name).First(&os)
-	}
-	// หมายเหตุ: mapOrderStatus เรียกแบบ db=nil เพื่อเทียบค่าแบบ lazy; ถ้าอยากชัวร์ให้ hardcode mapping
-	return os.ID
+	resp.OK(c, nil)
 }
@@ -0,0 +1,372 @@
+// controllers/webhook_controller.go
+package controllers
+
+import (
+	"backend/entity"
+	"backend/pkg/resp"
+	"backend/services"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookController struct {
+	DB      *gorm.DB
+	Service *services.WebhookService
+}
+
+func NewWebhookController(db *gorm.DB, s *services.WebhookService) *WebhookController {
+	return &WebhookController{DB: db, Service: s}
+}
+
+// ---------------- DTO ----------------
+type WebhookEndpointReq struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required"`
+	IsActive    *bool    `json:"isActive,omitempty"`
+}
+
+type WebhookEndpointUpdateReq struct {
+	URL         *string  `json:"url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+type WebhookEndpointRes struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	IsActive    bool     `json:"isActive"`
+	// secret แสดงแค่ตอนสร้าง / rotate เท่านั้น
+	Secret string `json:"secret,omitempty"`
+}
+
+// ---------------- Helpers ----------------
+
+// ตรวจว่า user เป็นเจ้าของร้าน :id
+func (ctl *WebhookController) ownRestaurant(c *gin.Context) (uint, bool) {
+	userID := c.GetUint("userId")
+	restID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || restID == 0 {
+		resp.BadRequest(c, "invalid restaurant id")
+		return 0, false
+	}
+
+	var count int64
+	if err := ctl.DB.Model(&entity.Restaurant{}).
+		Where("id = ? AND user_id = ?", restID, userID).
+		Count(&count).Error; err != nil || count == 0 {
+		resp.Forbidden(c, "forbidden")
+		return 0, false
+	}
+	return uint(restID), true
+}
+
+// โหลด endpoint :webhookId ของร้าน
+func (ctl *WebhookController) findEndpoint(c *gin.Context, restID uint) (*entity.WebhookEndpoint, bool) {
+	whID, _ := strconv.ParseUint(c.Param("webhookId"), 10, 64)
+
+	var ep entity.WebhookEndpoint
+	if err := ctl.DB.Where("id = ? AND restaurant_id = ?", whID, restID).First(&ep).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			resp.NotFound(c, "webhook not found")
+		} else {
+			resp.Error(c, err)
+		}
+		return nil, false
+	}
+	return &ep, true
+}
+
+// รับเฉพาะ http/https ที่มี host และ host ต้องไม่ชี้เข้าเครือข่ายภายใน (กัน SSRF)
+func checkWebhookURL(c *gin.Context, raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		resp.BadRequest(c, "invalid url")
+		return false
+	}
+	if err := services.CheckWebhookURL(c.Request.Context(), raw); err != nil {
+		resp.Error(c, err)
+		return false
+	}
+	return true
+}
+
+// ตรวจ event ที่ส่งมาว่ารู้จักทั้งหมด แล้วรวมเป็น string เดียวสำหรับเก็บลง DB
+func normalizeWebhookEvents(events []string) (string, bool) {
+	known := map[string]bool{"*": true}
+	for _, e := range services.WebhookEvents {
+		known[e] = true
+	}
+
+	seen := map[string]bool{}
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if !known[e] {
+			return "", false
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return "", false
+	}
+	return strings.Join(out, ","), true
+}
+
+func toWebhookEndpointRes(ep *entity.WebhookEndpoint) WebhookEndpointRes {
+	events := []string{}
+	for _, e := range strings.Split(ep.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return WebhookEndpointRes{
+		ID:          ep.ID,
+		URL:         ep.URL,
+		Description: ep.Description,
+		Events:      events,
+		IsActive:    ep.IsActive,
+	}
+}
+
+// ---------------- Handlers ----------------
+
+// GET /owner/restaurants/:id/webhooks
+func (ctl *WebhookController) List(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+
+	var eps []entity.WebhookEndpoint
+	if err := ctl.DB.Where("restaurant_id = ?", restID).Order("id DESC").Find(&eps).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	items := make([]WebhookEndpointRes, 0, len(eps))
+	for i := range eps {
+		items = append(items, toWebhookEndpointRes(&eps[i]))
+	}
+	resp.OK(c, gin.H{"items": items, "events": services.WebhookEvents})
+}
+
+// POST /owner/restaurants/:id/webhooks
+func (ctl *WebhookController) Create(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+	if !checkWebhookURL(c, req.URL) {
+		return
+	}
+	events, ok := normalizeWebhookEvents(req.Events)
+	if !ok {
+		resp.BadRequest(c, "invalid events")
+		return
+	}
+
+	secret, err := services.GenerateWebhookSecret()
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	ep := entity.WebhookEndpoint{
+		URL:          strings.TrimSpace(req.URL),
+		Description:  req.Description,
+		Secret:       secret,
+		Events:       events,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+		RestaurantID: restID,
+	}
+	if err := ctl.DB.Create(&ep).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+	// IsActive=false ต้อง update แยก เพราะ default:true จะทับค่า zero ตอน Create
+	if !ep.IsActive {
+		ctl.DB.Model(&ep).Update("is_active", false)
+	}
+
+	res := toWebhookEndpointRes(&ep)
+	res.Secret = secret
+	resp.Created(c, res)
+}
+
+// PATCH /owner/restaurants/:id/webhooks/:webhookId
+func (ctl *WebhookController) Update(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+	ep, ok := ctl.findEndpoint(c, restID)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.Invalid(c, err)
+		return
+	}
+
+	updates := map[string]any{}
+	if req.URL != nil {
+		if !checkWebhookURL(c, *req.URL) {
+			return
+		}
+		updates["url"] = strings.TrimSpace(*req.URL)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Events != nil {
+		events, ok := normalizeWebhookEvents(req.Events)
+		if !ok {
+			resp.BadRequest(c, "invalid events")
+			return
+		}
+		updates["events"] = events
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if len(updates) == 0 {
+		resp.BadRequest(c, "no fields to update")
+		return
+	}
+
+	if err := ctl.DB.Model(ep).Updates(updates).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+	ctl.DB.First(ep, ep.ID)
+	resp.OK(c, toWebhookEndpointRes(ep))
+}
+
+// DELETE /owner/restaurants/:id/webhooks/:webhookId
+func (ctl *WebhookController) Delete(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+	ep, ok := ctl.findEndpoint(c, restID)
+	if !ok {
+		return
+	}
+
+	if err := ctl.DB.Delete(ep).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+	resp.OK(c, gin.H{"message": "webhook deleted"})
+}
+
+// POST /owner/restaurants/:id/webhooks/:webhookId/rotate-secret
+func (ctl *WebhookController) RotateSecret(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+	ep, ok := ctl.findEndpoint(c, restID)
+	if !ok {
+		return
+	}
+
+	secret, err := services.GenerateWebhookSecret()
+	if err != nil {
+		resp.Error(c, err)
+		return
+	}
+	if err := ctl.DB.Model(ep).Update("secret", secret).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	res := toWebhookEndpointRes(ep)
+	res.Secret = secret
+	resp.OK(c, res)
+}
+
+// GET /owner/restaurants/:id/webhooks/:webhookId/deliveries?status=&page=&limit=
+func (ctl *WebhookController) Deliveries(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+	ep, ok := ctl.findEndpoint(c, restID)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	q := ctl.DB.Model(&entity.WebhookDelivery{}).Where("endpoint_id = ?", ep.ID)
+	if s := c.Query("status"); s != "" {
+		q = q.Where("status = ?", s)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	var rows []entity.WebhookDelivery
+	if err := q.Order("id DESC").Limit(limit).Offset((page - 1) * limit).Find(&rows).Error; err != nil {
+		resp.Error(c, err)
+		return
+	}
+
+	resp.OK(c, gin.H{"items": rows, "total": total, "page": page, "limit": limit})
+}
+
+// POST /owner/restaurants/:id/webhooks/deliveries/:deliveryId/redeliver
+func (ctl *WebhookController) Redeliver(c *gin.Context) {
+	restID, ok := ctl.ownRestaurant(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 64)
+	if err != nil || deliveryID == 0 {
+		resp.BadRequest(c, "invalid delivery id")
+		return
+	}
+
+	d, err := ctl.Service.Redeliver(c.Request.Context(), restID, uint(deliveryID))
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookDeliveryNotFound) {
+			resp.NotFound(c, "delivery not found")
+			return
+		}
+		resp.Error(c, err)
+		return
+	}
+	resp.JSON(c, http.StatusAccepted, gin.H{"deliveryId": d.ID, "status": d.Status})
+}
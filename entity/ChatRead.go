@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// read receipt: ข้อความล่าสุดที่ user อ่านแล้วในห้อง (1 แถวต่อคนต่อห้อง, เลื่อนไปข้างหน้าอย่างเดียว)
+// ข้อความที่ id มากกว่า LastReadMessageID และไม่ใช่ของตัวเอง = ยังไม่อ่าน
+type ChatRead struct {
+	ID                uint      `json:"-" gorm:"primaryKey"`
+	RoomID            uint      `json:"roomId" gorm:"not null;uniqueIndex:idx_chat_read_room_user"`
+	UserID            uint      `json:"userId" gorm:"not null;uniqueIndex:idx_chat_read_room_user"`
+	LastReadMessageID uint      `json:"lastReadMessageId" gorm:"not null;default:0"`
+	ReadAt            time.Time `json:"readAt"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// token ของอุปกรณ์สำหรับส่ง push (1 user มีได้หลายเครื่อง)
+type DeviceToken struct {
+	gorm.Model
+	// android / ios / web
+	Platform string `json:"platform" gorm:"size:20;not null"`
+	Token    string `json:"token" gorm:"size:512;uniqueIndex;not null"`
+
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+
+	UserID uint `json:"userId" gorm:"index;not null"`
+	User   User `json:"-"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ผลของ request ที่มี Idempotency-Key (ใช้ตอบซ้ำเมื่อ client retry)
+type IdempotencyKey struct {
+	gorm.Model
+	UserID uint   `json:"userId" gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key    string `json:"key" gorm:"column:idempotency_key;size:255;uniqueIndex:idx_idempotency_user_key"`
+
+	Method      string `json:"method" gorm:"size:10"`
+	Path        string `json:"path"`
+	RequestHash string `json:"requestHash" gorm:"size:64"` // sha256 ของ method + path + body
+
+	// Completed = false ระหว่างที่ request แรกยังทำงานอยู่
+	Completed    bool   `json:"completed" gorm:"not null;default:false"`
+	StatusCode   int    `json:"statusCode"`
+	ContentType  string `json:"contentType"`
+	ResponseBody string `json:"-" gorm:"type:text"`
+
+	ExpiresAt time.Time `json:"expiresAt" gorm:"index"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+// จำนวนครั้งที่ login ผิดติดกันต่อ email (ใช้ล็อกบัญชีชั่วคราว)
+// เก็บทุก email ที่ถูกลอง แม้ไม่มี user จริง เพื่อไม่ให้เดาได้ว่า email ไหนมีบัญชี
+type LoginAttempt struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Email        string     `json:"email" gorm:"size:255;uniqueIndex"`
+	Failures     int        `json:"failures" gorm:"not null;default:0"`
+	LastFailedAt time.Time  `json:"lastFailedAt"`
+	LockedUntil  *time.Time `json:"lockedUntil"`
+}
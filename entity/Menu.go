@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,7 +12,7 @@ type Menu struct {
 	Detail string `json:"detail"`
 	Price  int64  `json:"price"`
 
-	Image string `json:"image" gorm:"type:longtext"`
+	Image string `json:"image"`
 
 	MenuTypeID uint     `json:"menuTypeId"`
 	MenuType   MenuType `json:"-"`
@@ -21,5 +23,12 @@ type Menu struct {
 	MenuStatusID uint       `json:"menuStatusId"`
 	MenuStatus   MenuStatus `json:"-"`
 
+	// สต็อกรายวัน (DailyQuota = nil คือไม่ track stock)
+	DailyQuota     *int       `json:"dailyQuota"`
+	StockRemaining int        `json:"stockRemaining" gorm:"not null;default:0"`
+	StockResetAt   *time.Time `json:"stockResetAt,omitempty"` // reset ล่าสุด (ตอนร้านเปิด)
+	// ระบบเป็นคนเปลี่ยนเป็น Out of Stock เอง (เปิดกลับอัตโนมัติได้)
+	AutoOutOfStock bool `json:"-" gorm:"not null;default:false"`
+
 	OrderItems []OrderItem `json:"-"`
 }
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// การแจ้งเตือนในแอป (กล่องข้อความ) เก็บถาวรแยกจาก push
+type Notification struct {
+	gorm.Model
+	Type  string `json:"type" gorm:"size:50;index"`
+	Title string `json:"title"`
+	Body  string `json:"body" gorm:"type:text"`
+
+	// deep link ให้ FE เปิดหน้าที่เกี่ยวข้อง เช่น /orders/12
+	Link string `json:"link"`
+	// ข้อมูลประกอบ (JSON object) เช่น {"orderId":"12"}
+	Data string `json:"data" gorm:"type:text"`
+
+	IsRead bool       `json:"isRead" gorm:"not null;default:false;index:idx_notification_user_read,priority:2"`
+	ReadAt *time.Time `json:"readAt,omitempty"`
+
+	UserID uint `json:"userId" gorm:"index:idx_notification_user_read,priority:1"`
+	User   User `json:"-"` // preload เฉพาะตอนต้องการข้อมูล user
+}
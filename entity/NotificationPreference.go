@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+// การตั้งค่าการแจ้งเตือนของผู้ใช้ (ไม่มีแถว = เปิดทุกอย่าง ภาษาไทย)
+type NotificationPreference struct {
+	gorm.Model
+	UserID uint `json:"userId" gorm:"uniqueIndex;not null"`
+	User   User `json:"-"`
+
+	// th / en
+	Locale string `json:"locale" gorm:"size:5;not null;default:th"`
+
+	PushEnabled  bool `json:"pushEnabled" gorm:"not null;default:true"`
+	OrderUpdates bool `json:"orderUpdates" gorm:"not null;default:true"`
+	ChatMessages bool `json:"chatMessages" gorm:"not null;default:true"`
+	Applications bool `json:"applications" gorm:"not null;default:true"`
+}
@@ -1,21 +1,27 @@
 package entity
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Order struct {
 	gorm.Model
-	Subtotal    int64 `json:"subtotal"`
-	Discount    int64 `json:"discount"`
-	DeliveryFee int64 `json:"deliveryFee"`
-	Total       int64 `json:"total"`
-	Address string `json:"address" gorm:"type:text"`
+	Subtotal    int64  `json:"subtotal"`
+	Discount    int64  `json:"discount"`
+	DeliveryFee int64  `json:"deliveryFee"`
+	Total       int64  `json:"total"`
+	Address     string `json:"address" gorm:"type:text"`
+
+	// สั่งล่วงหน้า: เวลาที่ลูกค้าต้องการรับ (nil = ส่งทันที)
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty" gorm:"index"`
+	ReleasedAt   *time.Time `json:"releasedAt,omitempty"` // เวลาที่ scheduler ปล่อยเข้าคิวร้าน
 
 	UserID uint `json:"userId"`
 	User   User `json:"-"` // preload เฉพาะตอนต้องการ user detail
 
-	RestaurantID uint `json:"restaurantId"`
+	RestaurantID uint       `json:"restaurantId"`
 	Restaurant   Restaurant `json:"-"` // preload เมื่อจำเป็น
 
 	OrderStatusID uint        `json:"orderStatusId"`
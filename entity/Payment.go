@@ -8,11 +8,11 @@ import (
 
 type Payment struct {
 	gorm.Model
-	Amount          int64      `json:"amount"` 
+	Amount          int64      `json:"amount"`
 	PaidAt          *time.Time `json:"paidAt,omitempty"`
 	SlipContentType string     `gorm:"type:varchar(64)" json:"slipContentType,omitempty"`
-	SlipBase64      string     `gorm:"type:longtext" json:"slipBase64,omitempty"` //เก็บ base64
-	TransRef         *string `gorm:"size:100;uniqueIndex" json:"transRef,omitempty"`
+	SlipBase64      string     `json:"slipBase64,omitempty"` //เก็บ base64
+	TransRef        *string    `gorm:"size:100;uniqueIndex" json:"transRef,omitempty"`
 
 	PaymentMethodID uint          `json:"paymentMethodId"`
 	PaymentMethod   PaymentMethod `json:"-"`
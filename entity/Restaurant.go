@@ -9,11 +9,14 @@ type Restaurant struct {
 	Name        string `json:"name"`
 	Address     string `json:"address"`
 	Description string `json:"description"`
-	Picture     string `json:"pictureBase64,omitempty" gorm:"column:picture_base64;type:longtext"`
+	Picture     string `json:"pictureBase64,omitempty" gorm:"column:picture_base64"`
 
 	OpeningTime string `json:"openingTime"`
 	ClosingTime string `json:"closingTime"`
 
+	// เวลาเตรียมอาหารขั้นต่ำ (นาที) ใช้กับ order สั่งล่วงหน้า
+	LeadTimeMinutes int `json:"leadTimeMinutes" gorm:"not null;default:30"`
+
 	RestaurantCategoryID uint               `json:"restaurantCategoryId"`
 	RestaurantCategory   RestaurantCategory `json:"-"` // preload เฉพาะตอนต้องการ
 
@@ -21,9 +24,9 @@ type Restaurant struct {
 	RestaurantStatus   RestaurantStatus `json:"-"` // preload เฉพาะตอน detail
 
 	PromptPay string `json:"promptPay" gorm:"column:prompt_pay;type:varchar(32)"` // promptPay จะเป็นเบอร์ 10 หลัก หรือเลขบัตรประชาชน 13 หลัก
-	
+
 	UserID uint `json:"userId"` // owner
-	User   User `json:"-"` // preload เฉพาะตอนต้องการข้อมูลเจ้าของร้าน
+	User   User `json:"-"`      // preload เฉพาะตอนต้องการข้อมูลเจ้าของร้าน
 
 	AdminID *uint  `json:"adminId,omitempty"`
 	Admin   *Admin `json:"-"` // preload เฉพาะตอนที่ admin ต้องการจัดการ
@@ -32,4 +35,3 @@ type Restaurant struct {
 	Orders  []Order  `json:"-"` // preload แค่ endpoint /restaurants/:id/orders
 	Reviews []Review `json:"-"` // preload แค่ endpoint /restaurants/:id/reviews
 }
-
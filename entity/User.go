@@ -11,18 +11,18 @@ type User struct {
 	FirstName   string `json:"firstName"`
 	LastName    string `json:"lastName"`
 	PhoneNumber string `json:"phoneNumber"`
-	Address			string `json:"address"`
+	Address     string `json:"address"`
 	Role        string `gorm:"not null;default:customer" json:"role"`
 
 	// เก็บรูป
-	AvatarBase64 string `json:"avatarBase64,omitempty" gorm:"column:avatar_base64;type:longtext"`
+	AvatarBase64 string `json:"avatarBase64,omitempty" gorm:"column:avatar_base64"`
 
 	// Relations — preload เฉพาะตอนจำเป็น
-	RestaurantsOwned []Restaurant   `gorm:"foreignKey:UserID" json:"-"`
-	Orders           []Order        `json:"-"`
-	Reviews          []Review       `json:"-"`
-	MessagesSent     []Message      `gorm:"foreignKey:UserSenderID" json:"-"`
+	RestaurantsOwned []Restaurant    `gorm:"foreignKey:UserID" json:"-"`
+	Orders           []Order         `json:"-"`
+	Reviews          []Review        `json:"-"`
+	MessagesSent     []Message       `gorm:"foreignKey:UserSenderID" json:"-"`
 	UserPromotions   []UserPromotion `json:"-"`
-	RiderProfile     *Rider         `gorm:"foreignKey:UserID" json:"-"`
-	Reports          []Report       `json:"-"`
+	RiderProfile     *Rider          `gorm:"foreignKey:UserID" json:"-"`
+	Reports          []Report        `json:"-"`
 }
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ปลายทาง webhook ของร้าน (เช่น ระบบ POS ของร้าน)
+type WebhookEndpoint struct {
+	gorm.Model
+	URL         string `json:"url" gorm:"type:text;not null"`
+	Description string `json:"description"`
+	Secret      string `json:"-" gorm:"type:varchar(100);not null"` // ใช้ sign payload ไม่ส่งออกไปกับ response
+
+	// event ที่สมัครไว้ คั่นด้วย comma เช่น "order.created,order.accepted" ("*" = ทุก event)
+	Events   string `json:"events" gorm:"type:text"`
+	IsActive bool   `json:"isActive" gorm:"not null;default:true"`
+
+	RestaurantID uint       `json:"restaurantId" gorm:"index"`
+	Restaurant   Restaurant `json:"-"`
+
+	Deliveries []WebhookDelivery `json:"-" gorm:"foreignKey:EndpointID"`
+}
+
+// log การส่ง webhook แต่ละครั้ง (รวม retry)
+type WebhookDelivery struct {
+	gorm.Model
+	Event   string `json:"event" gorm:"size:100;index"`
+	Payload string `json:"payload" gorm:"type:text"`
+
+	// pending / success / failed
+	Status        string     `json:"status" gorm:"size:20;not null;default:pending;index"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"responseCode"`
+	ResponseBody  string     `json:"responseBody,omitempty" gorm:"type:text"`
+	LastError     string     `json:"lastError,omitempty" gorm:"type:text"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+
+	EndpointID uint            `json:"endpointId" gorm:"index"`
+	Endpoint   WebhookEndpoint `json:"-"`
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/configs"
+)
+
+// ตารางที่ export ได้ → คอลัมน์ที่ตัดทิ้ง (ความลับ / รูป base64 ขนาดใหญ่)
+var exportTables = map[string][]string{
+	"users":         {"password", "avatar_base64"},
+	"restaurants":   {"picture_base64"},
+	"menus":         {"image"},
+	"orders":        nil,
+	"order_items":   nil,
+	"payments":      {"slip_base64"},
+	"reviews":       nil,
+	"reports":       {"picture"},
+	"riders":        {"drive_card"},
+	"rider_works":   nil,
+	"promotions":    nil,
+	"notifications": nil,
+}
+
+// runExport = backend export -table orders [-format csv|json] [-out file] [-since 2006-01-02]
+func runExport(args []string) {
+	names := make([]string, 0, len(exportTables))
+	for t := range exportTables {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	table := fs.String("table", "", "table to export: "+strings.Join(names, ", "))
+	format := fs.String("format", "csv", "csv or json (one object per line)")
+	out := fs.String("out", "", "output file (default stdout)")
+	since := fs.String("since", "", "only rows created on/after this date (YYYY-MM-DD)")
+	fs.Parse(args)
+
+	hidden, ok := exportTables[*table]
+	if !ok {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("export: unknown format %q", *format)
+	}
+
+	bootstrap()
+	q := configs.DB().Table(*table).Order("id")
+	if *since != "" {
+		t, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			log.Fatalf("export: invalid -since: %v", err)
+		}
+		q = q.Where("created_at >= ?", t)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	rows, err := q.Rows()
+	if err != nil {
+		log.Fatalf("export: %v", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		log.Fatalf("export: %v", err)
+	}
+	skip := make(map[string]bool, len(hidden))
+	for _, c := range hidden {
+		skip[c] = true
+	}
+	var keep []int
+	var header []string
+	for i, c := range cols {
+		if !skip[c] {
+			keep = append(keep, i)
+			header = append(header, c)
+		}
+	}
+
+	var (
+		csvW  *csv.Writer
+		jsonE *json.Encoder
+	)
+	if *format == "csv" {
+		csvW = csv.NewWriter(w)
+		csvW.Write(header)
+	} else {
+		jsonE = json.NewEncoder(w)
+	}
+
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		if csvW != nil {
+			record := make([]string, len(keep))
+			for j, i := range keep {
+				record[j] = exportString(values[i])
+			}
+			csvW.Write(record)
+		} else {
+			obj := make(map[string]any, len(keep))
+			for j, i := range keep {
+				if b, ok := values[i].([]byte); ok {
+					obj[header[j]] = string(b)
+				} else {
+					obj[header[j]] = values[i]
+				}
+			}
+			if err := jsonE.Encode(obj); err != nil {
+				log.Fatalf("export: %v", err)
+			}
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("export: %v", err)
+	}
+	if csvW != nil {
+		csvW.Flush()
+		if err := csvW.Error(); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "exported %d rows from %s\n", count, *table)
+}
+
+func exportString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,220 @@
+// Package lookups แปลง code ของตาราง lookup (สถานะ / วิธีชำระ / ประเภท) เป็น id
+//
+// โหลดครั้งเดียวตอนบูต (Load) แล้ว cache ไว้; ถ้า row ที่โค้ดต้องใช้หายไปจะ error ทันที
+// แทนที่จะได้ id = 0 ไปเงียบ ๆ ตอนรันจริง
+//
+//	lookups.ID(lookups.OrderPending)      // → id ของ "Pending" ใน order_statuses
+//	lookups.CodeOf[lookups.OrderStatus](order.OrderStatusID)
+package lookups
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// Code = ชนิดของ code ที่มีตาราง lookup รองรับ
+type Code interface {
+	~string
+	table() string
+}
+
+type OrderStatus string
+
+const (
+	OrderPending    OrderStatus = "Pending"
+	OrderPreparing  OrderStatus = "Preparing"
+	OrderDelivering OrderStatus = "Delivering"
+	OrderCompleted  OrderStatus = "Completed"
+	OrderCancelled  OrderStatus = "Cancelled"
+	OrderScheduled  OrderStatus = "Scheduled"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentPending PaymentStatus = "Pending"
+	PaymentPaid    PaymentStatus = "Paid"
+	PaymentFailed  PaymentStatus = "Failed"
+)
+
+type PaymentMethod string
+
+const (
+	MethodPromptPay      PaymentMethod = "PromptPay"
+	MethodCashOnDelivery PaymentMethod = "Cash on Delivery"
+)
+
+type MenuStatus string
+
+const (
+	MenuAvailable  MenuStatus = "Available"
+	MenuOutOfStock MenuStatus = "Out of Stock"
+)
+
+type MenuType string
+
+const (
+	MenuTypeMain    MenuType = "เมนูหลัก"
+	MenuTypeSide    MenuType = "ของทานเล่น"
+	MenuTypeDessert MenuType = "ของหวาน"
+	MenuTypeDrink   MenuType = "เครื่องดื่ม"
+)
+
+type RestaurantStatus string
+
+const (
+	RestaurantOpen   RestaurantStatus = "Open"
+	RestaurantClosed RestaurantStatus = "Closed"
+)
+
+type RiderStatus string
+
+const (
+	RiderOffline   RiderStatus = "OFFLINE"
+	RiderOnline    RiderStatus = "ONLINE"
+	RiderAssigned  RiderStatus = "ASSIGNED"
+	RiderCompleted RiderStatus = "COMPLETED"
+)
+
+type MessageType string
+
+const (
+	MessageText   MessageType = "TEXT"
+	MessageImage  MessageType = "IMAGE"
+	MessageSystem MessageType = "SYSTEM"
+)
+
+type PromoType string
+
+const (
+	PromoDiscount PromoType = "Discount" // ลดเป็นบาท
+	PromoPercent  PromoType = "Percent"  // ลดเป็น %
+)
+
+func (OrderStatus) table() string      { return "order_statuses" }
+func (PaymentStatus) table() string    { return "payment_statuses" }
+func (PaymentMethod) table() string    { return "payment_methods" }
+func (MenuStatus) table() string       { return "menu_statuses" }
+func (MenuType) table() string         { return "menu_types" }
+func (RestaurantStatus) table() string { return "restaurant_statuses" }
+func (RiderStatus) table() string      { return "rider_statuses" }
+func (MessageType) table() string      { return "message_types" }
+func (PromoType) table() string        { return "promo_types" }
+
+// spec = ตาราง lookup หนึ่งตาราง + code ที่โค้ดต้องใช้ (ต้องมีครบตอนบูต)
+type spec struct {
+	table    string
+	column   string
+	required []string
+}
+
+func codes[C Code](cs ...C) []string {
+	out := make([]string, len(cs))
+	for i, c := range cs {
+		out[i] = string(c)
+	}
+	return out
+}
+
+var specs = []spec{
+	{"order_statuses", "status_name", codes(OrderPending, OrderPreparing, OrderDelivering, OrderCompleted, OrderCancelled, OrderScheduled)},
+	{"payment_statuses", "status_name", codes(PaymentPending, PaymentPaid, PaymentFailed)},
+	{"payment_methods", "method_name", codes(MethodPromptPay, MethodCashOnDelivery)},
+	{"menu_statuses", "status_name", codes(MenuAvailable, MenuOutOfStock)},
+	{"menu_types", "type_name", codes(MenuTypeMain, MenuTypeSide, MenuTypeDessert, MenuTypeDrink)},
+	{"restaurant_statuses", "status_name", codes(RestaurantOpen, RestaurantClosed)},
+	{"rider_statuses", "status_name", codes(RiderOffline, RiderOnline, RiderAssigned, RiderCompleted)},
+	{"message_types", "name", codes(MessageText, MessageImage, MessageSystem)},
+	{"promo_types", "name_type", codes(PromoDiscount, PromoPercent)},
+}
+
+// Registry = id ของทุก row ในตาราง lookup ที่รู้จัก (table → code → id)
+type Registry struct {
+	ids   map[string]map[string]uint
+	codes map[string]map[uint]string
+}
+
+var current atomic.Pointer[Registry]
+
+// Load อ่านตาราง lookup ทั้งหมด ตรวจว่า code ที่ต้องใช้มีครบ แล้วตั้งเป็น registry ที่ใช้งาน
+func Load(db *gorm.DB) (*Registry, error) {
+	reg := &Registry{
+		ids:   make(map[string]map[string]uint, len(specs)),
+		codes: make(map[string]map[uint]string, len(specs)),
+	}
+	var missing []string
+	for _, s := range specs {
+		var rows []struct {
+			ID   uint
+			Code string
+		}
+		if err := db.Table(s.table).
+			Select("id, " + s.column + " AS code").
+			Where("deleted_at IS NULL").
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("lookups: read %s: %w", s.table, err)
+		}
+		ids := make(map[string]uint, len(rows))
+		byID := make(map[uint]string, len(rows))
+		for _, r := range rows {
+			ids[r.Code] = r.ID
+			byID[r.ID] = r.Code
+		}
+		for _, code := range s.required {
+			if _, ok := ids[code]; !ok {
+				missing = append(missing, s.table+"."+code)
+			}
+		}
+		reg.ids[s.table] = ids
+		reg.codes[s.table] = byID
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("lookups: missing required rows: %s (run `backend seed lookups`)", strings.Join(missing, ", "))
+	}
+	current.Store(reg)
+	return reg, nil
+}
+
+// LoadDefaults ตั้ง registry จาก code ที่โค้ดต้องใช้ โดยให้ id เรียง 1..n ต่อตาราง
+// สำหรับ unit test ที่ไม่มี DB (เช่นคู่กับ repository.MemoryStore)
+func LoadDefaults() *Registry {
+	reg := &Registry{
+		ids:   make(map[string]map[string]uint, len(specs)),
+		codes: make(map[string]map[uint]string, len(specs)),
+	}
+	for _, s := range specs {
+		ids := make(map[string]uint, len(s.required))
+		byID := make(map[uint]string, len(s.required))
+		for i, code := range s.required {
+			ids[code] = uint(i + 1)
+			byID[uint(i+1)] = code
+		}
+		reg.ids[s.table] = ids
+		reg.codes[s.table] = byID
+	}
+	current.Store(reg)
+	return reg
+}
+
+func registry() *Registry {
+	reg := current.Load()
+	if reg == nil {
+		panic("lookups: registry not loaded (call lookups.Load at startup)")
+	}
+	return reg
+}
+
+// ID คืน id ของ code (0 = ไม่มี code นี้ในตาราง เช่น ค่าที่รับมาจาก client)
+func ID[C Code](code C) uint {
+	return registry().ids[code.table()][string(code)]
+}
+
+// CodeOf คืน code ของ id ในตารางของ C
+func CodeOf[C Code](id uint) (C, bool) {
+	var zero C
+	code, ok := registry().codes[zero.table()][id]
+	return C(code), ok
+}
@@ -3,47 +3,118 @@ package main
 import (
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"strings"
 
 	"backend/configs"
-	"backend/middlewares"
-	"backend/routes"
-
-	"github.com/gin-gonic/gin"
+	"backend/pkg/logx"
 )
 
-func main() {
-	cfg := configs.LoadConfig()
+const usage = `usage: backend [global flags] [command] [flags]
 
-	log.Printf("[MAIN] EasySlip API Key present=%v len=%d", cfg.EasySlipAPIKey != "", len(cfg.EasySlipAPIKey))
+global flags:
+  -config FILE     read settings from a .env-style file (also CONFIG_FILE)
+  -profile NAME    dev | test | prod (same as APP_ENV)
+  -set KEY=VALUE   override one setting; may be repeated
 
-	if cfg.EasySlipAPIKey == "" {
-		log.Fatal("EASYSLIP_API_KEY is required but not found in environment")
-	}
+commands:
+  serve            start the HTTP server (default)
+  migrate          apply/revert schema migrations (up|down|status)
+  seed             seed lookup tables and/or demo data (lookups|demo|all)
+  create-admin     create an admin user
+  reset-password   set a new password for a user
+  export           dump a table as CSV or JSON
+  reindex          recreate missing indexes and refresh planner statistics
 
-	// DB
-	configs.ConnectionDB()
-	db := configs.DB()
+run "backend <command> -h" for command flags`
+
+// คำสั่งย่อยทั้งหมด: รับ args ที่ตามหลังชื่อคำสั่ง
+var commands = map[string]func(args []string){
+	"serve":          runServe,
+	"migrate":        runMigrate,
+	"seed":           runSeed,
+	"create-admin":   runCreateAdmin,
+	"reset-password": runResetPassword,
+	"export":         runExport,
+	"reindex":        runReindex,
+}
+
+// ค่าจาก global flag (ใช้ตอน bootstrap)
+var loadOpts = configs.LoadOptions{Overrides: map[string]string{}}
+
+func main() {
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s\n", err, usage)
+		os.Exit(2)
+	}
 
-	// migrate
-	configs.SetupDatabase()
+	// ไม่ระบุคำสั่ง = serve (คงพฤติกรรมเดิมของ `go run .`)
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	if err := configs.SeedAdmin(); err != nil {
-		log.Fatalf("seed admin failed: %v", err)
+	if name == "help" {
+		fmt.Println(usage)
+		return
 	}
-	if err := configs.SeedLookups(); err != nil {
-		log.Fatalf("seed lookups failed: %v", err)
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", name, usage)
+		os.Exit(2)
 	}
+	run(args)
+}
+
+// parseGlobalFlags ตัด -config/-profile/-set ที่อยู่หน้าชื่อคำสั่งออก
+// (flag อื่นปล่อยไว้ให้คำสั่งย่อย เช่น `backend -seed-demo` = serve -seed-demo)
+func parseGlobalFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") || (name != "config" && name != "profile" && name != "set") {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("flag -%s needs a value", name)
+			}
+			value, args = args[0], args[1:]
+		}
 
-	// HTTP
-	r := gin.Default()
-	r.Use(middlewares.CORSMiddleware())
-	r.Static("/uploads", "./uploads")
-	routes.RegisterRoutes(r, db, cfg)
+		switch name {
+		case "config":
+			loadOpts.File = value
+		case "profile":
+			loadOpts.Overrides["APP_ENV"] = value
+		case "set":
+			k, v, ok := strings.Cut(value, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("-set expects KEY=VALUE (got %q)", value)
+			}
+			loadOpts.Overrides[k] = v
+		}
+	}
+	return args, nil
+}
 
-	port := configs.LoadConfig().Port
-	addr := fmt.Sprintf(":%s", port)
-	log.Println("🚀 Server running at", addr)
-	if err := r.Run(addr); err != nil {
+// bootstrap โหลด config + ตั้ง logger + เปิด DB แบบเดียวกันทุกคำสั่ง
+func bootstrap() *configs.Config {
+	cfg, err := configs.Load(loadOpts)
+	if err != nil {
 		log.Fatal(err)
 	}
+	if _, err := logx.Setup(os.Stderr, cfg.LogFormat, cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+	configs.ConnectionDB(cfg)
+	return cfg
+}
+
+// fatal = log.Fatal แบบมี attr (เขียนผ่าน slog ที่ตั้งไว้ใน bootstrap)
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
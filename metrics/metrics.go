@@ -0,0 +1,151 @@
+// Package metrics เก็บตัวชี้วัดของแอปแบบ Prometheus
+//
+// สร้างหนึ่งชุดต่อ router (registry ของตัวเอง ไม่ใช้ global) แล้วส่งต่อให้ service ที่ต้องนับ
+// ทุกเมธอดเรียกบน *Metrics ที่เป็น nil ได้ (= ไม่เก็บ) เหมือน service อื่นที่เป็น optional
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpDuration       *prometheus.HistogramVec
+	dbDuration         *prometheus.HistogramVec
+	wsConnections      *prometheus.GaugeVec
+	slipVerify         *prometheus.CounterVec
+	slipVerifyDuration prometheus.Histogram
+	ordersCreated      *prometheus.CounterVec
+	paymentsVerified   *prometheus.CounterVec
+	dispatchLatency    prometheus.Histogram
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query latency by GORM operation and table.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+
+		wsConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ws_connections",
+			Help: "Active chat WebSocket connections per room.",
+		}, []string{"room"}),
+
+		slipVerify: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slip_verify_total",
+			Help: "Slip verification calls to the provider (EasySlip) by outcome.",
+		}, []string{"outcome"}),
+
+		slipVerifyDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "slip_verify_duration_seconds",
+			Help:    "Latency of slip verification calls.",
+			Buckets: []float64{.25, .5, 1, 2, 5, 10, 25},
+		}),
+
+		ordersCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "orders_created_total",
+			Help: "Orders placed by payment method and whether they were scheduled.",
+		}, []string{"payment_method", "scheduled"}),
+
+		paymentsVerified: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payments_verified_total",
+			Help: "Payments marked as paid by method.",
+		}, []string{"method"}),
+
+		dispatchLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "order_dispatch_latency_seconds",
+			Help:    "Time from an order entering the restaurant queue to a rider accepting it.",
+			Buckets: []float64{30, 60, 120, 300, 600, 900, 1800, 3600},
+		}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration, m.dbDuration, m.wsConnections,
+		m.slipVerify, m.slipVerifyDuration,
+		m.ordersCreated, m.paymentsVerified, m.dispatchLatency,
+	)
+	return m
+}
+
+// Handler = endpoint /metrics (รูปแบบ text ของ Prometheus)
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// ObserveHTTP บันทึก request หนึ่งครั้ง (route = path แบบที่ประกาศกับ gin)
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	if route == "" {
+		route = "unmatched" // 404 ของ router: ไม่ใช้ path จริงกัน label บวม
+	}
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// SetWSConnections = จำนวน connection ที่เปิดอยู่ของห้อง (0 = ลบ series ของห้องทิ้ง กัน label ค้าง)
+func (m *Metrics) SetWSConnections(roomID uint, n int) {
+	if m == nil {
+		return
+	}
+	if n == 0 {
+		m.wsConnections.DeleteLabelValues(roomLabel(roomID))
+		return
+	}
+	m.wsConnections.WithLabelValues(roomLabel(roomID)).Set(float64(n))
+}
+
+func roomLabel(id uint) string { return strconv.FormatUint(uint64(id), 10) }
+
+// ObserveSlipVerify บันทึกผลการเรียกผู้ให้บริการตรวจสลิป (outcome = ok หรือ code ของ error)
+func (m *Metrics) ObserveSlipVerify(outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.slipVerify.WithLabelValues(outcome).Inc()
+	m.slipVerifyDuration.Observe(d.Seconds())
+}
+
+func (m *Metrics) OrderCreated(paymentMethod string, scheduled bool) {
+	if m == nil {
+		return
+	}
+	if paymentMethod == "" {
+		paymentMethod = "none"
+	}
+	m.ordersCreated.WithLabelValues(paymentMethod, strconv.FormatBool(scheduled)).Inc()
+}
+
+func (m *Metrics) PaymentVerified(method string) {
+	if m == nil {
+		return
+	}
+	m.paymentsVerified.WithLabelValues(method).Inc()
+}
+
+// ObserveDispatch = เวลาตั้งแต่ order เข้าคิวร้านจนไรเดอร์รับงาน
+func (m *Metrics) ObserveDispatch(d time.Duration) {
+	if m == nil || d < 0 {
+		return
+	}
+	m.dispatchLatency.Observe(d.Seconds())
+}
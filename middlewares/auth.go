@@ -1,8 +1,9 @@
 package middlewares
 
 import (
+	"backend/pkg/logx"
+	"backend/pkg/resp"
 	"backend/utils"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -15,8 +16,7 @@ func AuthMiddleware(secret string, requiredRoles ...string) gin.HandlerFunc {
 		// ---------------- ตรวจ Header ----------------
 		h := c.GetHeader("Authorization")
 		if h == "" || !strings.HasPrefix(h, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "missing or invalid token"})
-			c.Abort()
+			resp.Unauthorized(c, "missing or invalid token")
 			return
 		}
 		tokenStr := strings.TrimPrefix(h, "Bearer ")
@@ -31,21 +31,20 @@ func AuthMiddleware(secret string, requiredRoles ...string) gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "invalid token"})
-			c.Abort()
+			resp.Unauthorized(c, "invalid token")
 			return
 		}
 
 		// ---------------- Extract Claims ----------------
 		if claims.UserID == 0 {
-			c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "invalid userId"})
-			c.Abort()
+			resp.Unauthorized(c, "invalid userId")
 			return
 		}
 
 		// set ค่าไว้ใน context
 		c.Set("userId", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Request = c.Request.WithContext(logx.With(c.Request.Context(), "userId", claims.UserID))
 
 		// ---------------- Role Checking ----------------
 		if len(requiredRoles) > 0 {
@@ -57,8 +56,7 @@ func AuthMiddleware(secret string, requiredRoles ...string) gin.HandlerFunc {
 				}
 			}
 			if !allowed {
-				c.JSON(http.StatusForbidden, gin.H{"ok": false, "error": "forbidden"})
-				c.Abort()
+				resp.Forbidden(c, "forbidden")
 				return
 			}
 		}
@@ -1,19 +1,78 @@
 package middlewares
 
 import (
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
+// OriginAllowList = รายการ origin ที่อนุญาต ใช้ร่วมกันทั้ง CORS และ WebSocket
+//
+// รูปแบบที่รองรับ: "*" (ทุกโดเมน, dev เท่านั้น), "https://app.example.com",
+// "https://*.example.com" (subdomain ใดก็ได้)
+type OriginAllowList struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []wildcardOrigin
+}
+
+// "https://*.example.com" → scheme "https://", suffix ".example.com"
+type wildcardOrigin struct {
+	scheme, suffix string
+}
+
+func NewOriginAllowList(origins []string) *OriginAllowList {
+	l := &OriginAllowList{exact: map[string]bool{}}
+	for _, o := range origins {
+		o = strings.TrimRight(strings.ToLower(strings.TrimSpace(o)), "/")
+		switch {
+		case o == "*":
+			l.any = true
+		case strings.Contains(o, "://*."):
+			scheme, suffix, _ := strings.Cut(o, "://*")
+			l.wildcards = append(l.wildcards, wildcardOrigin{scheme: scheme + "://", suffix: suffix})
+		case o != "":
+			l.exact[o] = true
+		}
+	}
+	return l
+}
+
+// Allowed ตรวจ origin จาก header Origin
+func (l *OriginAllowList) Allowed(origin string) bool {
+	if l.any {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if l.exact[origin] {
+		return true
+	}
+	for _, w := range l.wildcards {
+		if strings.HasPrefix(origin, w.scheme) && strings.HasSuffix(origin, w.suffix) &&
+			len(origin) > len(w.scheme)+len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRequest ใช้เป็น websocket.Upgrader.CheckOrigin
+// (ไม่มี header Origin = client ที่ไม่ใช่ browser เช่นแอปมือถือ → ผ่าน)
+func (l *OriginAllowList) CheckRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || l.Allowed(origin)
+}
+
+func CORSMiddleware(allow *OriginAllowList) gin.HandlerFunc {
 	cfg := cors.Config{
-		AllowOrigins:  []string{"*"}, // dev เท่านั้น; prod ใส่โดเมนจริง
-		AllowMethods:  []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:  []string{"Authorization", "Content-Type"},
-		ExposeHeaders: []string{"Content-Length"},
-		MaxAge:        12 * time.Hour,
+		AllowOriginFunc: allow.Allowed,
+		AllowMethods:    []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:    []string{"Authorization", "Content-Type", IdempotencyHeader},
+		ExposeHeaders:   []string{"Content-Length", RequestIDHeader, "Retry-After"},
+		MaxAge:          12 * time.Hour,
 	}
 	return cors.New(cfg)
 }
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler แปลง error ที่ handler แนบไว้ด้วย c.Error แต่ยังไม่ได้ตอบ ให้เป็น response กลาง
+// (resp.Error ตอบเองทันทีอยู่แล้ว ตัวนี้รับกรณี c.Error / c.AbortWithError)
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		resp.Render(c, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute / NoMethod ตอบในรูปแบบเดียวกับ endpoint อื่น
+func NoRoute(c *gin.Context) {
+	resp.NotFound(c, "route not found")
+}
+
+func NoMethod(c *gin.Context) {
+	resp.Error(c, resp.New(http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed"))
+}
@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"backend/entity"
+	"backend/pkg/resp"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const IdempotencyHeader = "Idempotency-Key"
+
+var errIdempotencyInProgress = resp.New(http.StatusConflict, "idempotency_in_progress", "a request with this Idempotency-Key is still in progress")
+
+// เก็บ response ไว้พร้อมกับส่งให้ client
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency กันสร้างซ้ำเมื่อ client ส่ง request เดิมซ้ำ (เช่น กด checkout สองครั้ง)
+// ใช้ต่อจาก AuthMiddleware เท่านั้น (ผูก key กับ userId) — ไม่มี header = ทำงานปกติ
+//   - key เดิม + payload เดิม → ตอบ response เดิมซ้ำ (header Idempotent-Replayed: true)
+//   - key เดิม + payload ต่าง → 422
+//   - key เดิมที่ request แรกยังไม่เสร็จ → 409
+//
+// response 5xx (รวม handler panic) จะไม่ถูกเก็บ เพื่อให้ retry ได้
+func Idempotency(db *gorm.DB, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			resp.BadRequest(c, "Idempotency-Key too long")
+			return
+		}
+		userID := c.GetUint("userId")
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			resp.BadRequest(c, "cannot read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		h := sha256.New()
+		h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		h.Write(body)
+		hash := hex.EncodeToString(h.Sum(nil))
+
+		now := time.Now()
+		// key หมดอายุแล้วถือว่าใช้ใหม่ได้
+		db.Unscoped().Where("user_id = ? AND expires_at < ?", userID, now).Delete(&entity.IdempotencyKey{})
+
+		var existing entity.IdempotencyKey
+		if err := db.Where("user_id = ? AND idempotency_key = ?", userID, key).Limit(1).Find(&existing).Error; err != nil {
+			resp.ServerError(c, err)
+			return
+		}
+		if existing.ID != 0 {
+			switch {
+			case existing.RequestHash != hash:
+				resp.Error(c, resp.New(http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used with a different request"))
+			case !existing.Completed:
+				resp.Error(c, errIdempotencyInProgress)
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, existing.ContentType, []byte(existing.ResponseBody))
+				c.Abort()
+			}
+			return
+		}
+
+		// จอง key ก่อนทำงาน (unique index กัน request ที่มาพร้อมกัน)
+		record := entity.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: hash,
+			ExpiresAt:   now.Add(ttl),
+		}
+		if err := db.Create(&record).Error; err != nil {
+			resp.Error(c, errIdempotencyInProgress)
+			return
+		}
+
+		// ไม่ได้เก็บผล (5xx / handler panic) = ปล่อย key ให้ retry ได้
+		// defer รันตอน panic ด้วย แล้ว panic เดิมไปต่อถึง Recovery (ซึ่งเขียน 500 นอก middleware นี้)
+		stored := false
+		defer func() {
+			if !stored {
+				db.Unscoped().Delete(&entity.IdempotencyKey{}, record.ID)
+			}
+		}()
+
+		w := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		stored = true
+		db.Model(&record).Updates(map[string]any{
+			"completed":     true,
+			"status_code":   status,
+			"content_type":  w.Header().Get("Content-Type"),
+			"response_body": w.body.String(),
+		})
+	}
+}
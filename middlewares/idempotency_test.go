@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/entity"
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var idempotencyDBSeq atomic.Int64
+
+// idempotencyServer = POST /orders ของ user 1 ผ่าน Idempotency โดยมี handler ของ test อยู่ท้ายสุด
+type idempotencyServer struct {
+	db *gorm.DB
+	r  *gin.Engine
+}
+
+func newIdempotencyServer(t *testing.T, handler gin.HandlerFunc) *idempotencyServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dsn := fmt.Sprintf("file:idempotency_%d?mode=memory&cache=shared", idempotencyDBSeq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&entity.IdempotencyKey{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(Recovery())
+	r.POST("/orders", func(c *gin.Context) { c.Set("userId", uint(1)) }, Idempotency(db, time.Hour), handler)
+	return &idempotencyServer{db: db, r: r}
+}
+
+func (s *idempotencyServer) do(key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(IdempotencyHeader, key)
+	w := httptest.NewRecorder()
+	s.r.ServeHTTP(w, req)
+	return w
+}
+
+func wantErrorCode(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	var env struct {
+		Error *resp.APIError `json:"error"`
+	}
+	if w.Code != status || json.Unmarshal(w.Body.Bytes(), &env) != nil || env.Error == nil || env.Error.Code != code {
+		t.Fatalf("response = %d %s, want %d %s", w.Code, w.Body, status, code)
+	}
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	s := newIdempotencyServer(t, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	first := s.do("key-1", `{"qty":1}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first: %d replayed=%q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+	for i := 0; i < 2; i++ {
+		w := s.do("key-1", `{"qty":1}`)
+		if w.Code != http.StatusCreated || w.Body.String() != first.Body.String() ||
+			w.Header().Get("Content-Type") != first.Header().Get("Content-Type") ||
+			w.Header().Get("Idempotent-Replayed") != "true" {
+			t.Fatalf("replay %d: %d %s replayed=%q, want %s", i, w.Code, w.Body, w.Header().Get("Idempotent-Replayed"), first.Body)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+
+	// key ใหม่ = request ใหม่
+	if w := s.do("key-2", `{"qty":1}`); w.Code != http.StatusCreated || w.Body.String() != `{"id":2}` {
+		t.Fatalf("new key: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyRejectsKeyReusedWithDifferentBody(t *testing.T) {
+	calls := 0
+	s := newIdempotencyServer(t, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	if w := s.do("key-1", `{"qty":1}`); w.Code != http.StatusCreated {
+		t.Fatalf("first: %d %s", w.Code, w.Body)
+	}
+	wantErrorCode(t, s.do("key-1", `{"qty":2}`), http.StatusUnprocessableEntity, "idempotency_key_reused")
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+	// body เดิมยัง replay ได้ตามปกติ
+	if w := s.do("key-1", `{"qty":1}`); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay after 422: %d replayed=%q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyConflictsWhileFirstRequestInFlight(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	s := newIdempotencyServer(t, func(c *gin.Context) {
+		calls.Add(1)
+		close(entered)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	firstDone := make(chan *httptest.ResponseRecorder)
+	go func() { firstDone <- s.do("key-1", `{"qty":1}`) }()
+	select {
+	case <-entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("first request never reached the handler")
+	}
+
+	// request แรกจอง key แล้วแต่ยังไม่เสร็จ → ตัวซ้ำได้ 409 โดยไม่เข้า handler
+	wantErrorCode(t, s.do("key-1", `{"qty":1}`), http.StatusConflict, "idempotency_in_progress")
+
+	close(release)
+	if w := <-firstDone; w.Code != http.StatusCreated {
+		t.Fatalf("first: %d %s", w.Code, w.Body)
+	}
+	if w := s.do("key-1", `{"qty":1}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("after first finished: %d replayed=%q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("handler calls = %d, want 1", n)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	calls := 0
+	s := newIdempotencyServer(t, func(c *gin.Context) {
+		if calls++; calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	if w := s.do("key-1", `{"qty":1}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request: %d %s", w.Code, w.Body)
+	}
+	var n int64
+	s.db.Model(&entity.IdempotencyKey{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("keys after panic = %d, want 0", n)
+	}
+
+	// retry ด้วย key เดิมต้องทำงานจริง ไม่ใช่ 409 in progress
+	if w := s.do("key-1", `{"qty":1}`); w.Code != http.StatusCreated {
+		t.Fatalf("retry: %d %s", w.Code, w.Body)
+	}
+	if w := s.do("key-1", `{"qty":1}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay: %d replayed=%q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog เขียน log หนึ่งบรรทัดต่อ request (แทน gin.Logger) ต้องวางหลัง RequestID
+// ไม่ log query string เพราะอาจมี token (เช่น WebSocket ?token=)
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"path", c.Request.URL.Path,
+			"status", status,
+			"durationMs", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+			"ip", c.ClientIP(),
+		)
+	}
+}
+
+// Recovery กัน panic ไม่ให้ server ล้ม: log stack ผ่าน slog แล้วตอบ 500 แบบ envelope
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"panic", err,
+			"stack", string(debug.Stack()),
+		)
+		resp.Error(c, resp.New(http.StatusInternalServerError, resp.CodeInternal, "internal server error"))
+	})
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"backend/metrics"
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics วัด latency/status ของทุก request แยกตาม route (ไม่ใช่ path จริง กัน label บวม)
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveHTTP(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsAuth ป้องกัน /metrics ด้วย token คงที่ (ว่าง = ไม่ตรวจ ให้จำกัดที่ระดับ network แทน)
+func MetricsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := []byte(c.GetHeader("Authorization"))
+		if token != "" && subtle.ConstantTimeCompare(got, []byte("Bearer "+token)) != 1 {
+			resp.Unauthorized(c, "invalid metrics token")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"backend/pkg/resp"
+	"backend/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit จำกัด request ต่อ route ต่อผู้เรียก
+//
+// key = ชื่อ route + user id (ถ้าผ่าน AuthMiddleware มาแล้ว) หรือ IP ของ client
+// เกินโควตา → 429 + Retry-After; store ล่ม → ปล่อยผ่าน (ไม่ให้ทั้งระบบล่มตาม)
+func RateLimit(store ratelimit.Store, route string, rate ratelimit.Rate) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rate.Unlimited() {
+			c.Next()
+			return
+		}
+
+		who := "ip:" + c.ClientIP()
+		if uid, ok := c.Get("userId"); ok {
+			who = fmt.Sprintf("user:%v", uid)
+		}
+
+		res, err := store.Take(c.Request.Context(), route+"|"+who, rate)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "rate limit store failed", "route", route, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rate.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			secs := int(math.Ceil(res.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(secs))
+			resp.Error(c, resp.New(http.StatusTooManyRequests, resp.CodeRateLimited, "too many requests"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/pkg/resp"
+	"backend/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubRateStore ตอบผลที่ตั้งไว้ และจำ key ล่าสุด
+type stubRateStore struct {
+	res ratelimit.Result
+	err error
+	key string
+}
+
+func (s *stubRateStore) Take(_ context.Context, key string, _ ratelimit.Rate) (ratelimit.Result, error) {
+	s.key = key
+	return s.res, s.err
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rate := ratelimit.Rate{Limit: 5, Per: time.Minute}
+
+	for _, tc := range []struct {
+		name       string
+		store      *stubRateStore
+		status     int
+		retryAfter string
+	}{
+		{"allowed", &stubRateStore{res: ratelimit.Result{Allowed: true, Remaining: 4}}, http.StatusOK, ""},
+		{"denied rounds retry up", &stubRateStore{res: ratelimit.Result{RetryAfter: 1200 * time.Millisecond}}, http.StatusTooManyRequests, "2"},
+		{"denied whole second", &stubRateStore{res: ratelimit.Result{RetryAfter: 3 * time.Second}}, http.StatusTooManyRequests, "3"},
+		{"store error fails open", &stubRateStore{err: errors.New("redis down")}, http.StatusOK, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/auth/login", RateLimit(tc.store, "login", rate), func(c *gin.Context) { c.Status(http.StatusOK) })
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+			req.RemoteAddr = "203.0.113.7:1234"
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d", w.Code, tc.status)
+			}
+			if got := w.Header().Get("Retry-After"); got != tc.retryAfter {
+				t.Fatalf("Retry-After = %q, want %q", got, tc.retryAfter)
+			}
+			if tc.store.key != "login|ip:203.0.113.7" {
+				t.Fatalf("key = %q", tc.store.key)
+			}
+			if tc.status != http.StatusTooManyRequests {
+				return
+			}
+			var env struct {
+				OK    bool           `json:"ok"`
+				Error *resp.APIError `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil || env.OK || env.Error == nil || env.Error.Code != resp.CodeRateLimited {
+				t.Fatalf("body = %s", w.Body)
+			}
+			if w.Header().Get("X-RateLimit-Limit") != "5" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+				t.Fatalf("rate headers = %v", w.Header())
+			}
+		})
+	}
+}
+
+func TestRateLimitKeysByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &stubRateStore{res: ratelimit.Result{Allowed: true}}
+	r := gin.New()
+	r.POST("/orders", func(c *gin.Context) { c.Set("userId", uint(42)) }, RateLimit(store, "orders", ratelimit.Rate{Limit: 1, Per: time.Second}), func(c *gin.Context) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if store.key != "orders|user:42" {
+		t.Fatalf("key = %q, want orders|user:42", store.key)
+	}
+}
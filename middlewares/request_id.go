@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"backend/pkg/logx"
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// รับ id จาก proxy/client ได้เฉพาะรูปแบบที่ปลอดภัยต่อการเขียนลง log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID ใส่ id ให้ทุก request (ใช้ต่อจาก header X-Request-ID ถ้ามี) และส่งกลับใน header เดียวกัน
+// id ผูกไว้กับ c.Request.Context() ด้วย: service ที่ log ผ่าน slog.XxxContext(ctx) จะได้ requestId อัตโนมัติ
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(resp.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logx.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,8 +2,9 @@
 package middlewares
 
 import (
+	"backend/pkg/logx"
+	"backend/pkg/resp"
 	"backend/utils"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -27,7 +28,7 @@ func WSAuthMiddleware(secret string) gin.HandlerFunc {
 		}
 
 		if tokenStr == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+			resp.Unauthorized(c, "missing token")
 			return
 		}
 
@@ -41,7 +42,7 @@ func WSAuthMiddleware(secret string) gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			resp.Unauthorized(c, "invalid token")
 			return
 		}
 
@@ -49,6 +50,7 @@ func WSAuthMiddleware(secret string) gin.HandlerFunc {
 		c.Set("userId", claims.UserID)
 		c.Set("role", claims.Role)
 		c.Set("claims", claims)
+		c.Request = c.Request.WithContext(logx.With(c.Request.Context(), "userId", claims.UserID))
 
 		c.Next()
 	}
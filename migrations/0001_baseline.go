@@ -0,0 +1,649 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// baseline = ตารางทั้งหมด ณ ตอนที่เลิกใช้ AutoMigrate ตอนบูต
+// DB เดิมที่ถูก AutoMigrate ไว้แล้วจะผ่านขั้นนี้โดยไม่เปลี่ยนอะไร (AutoMigrate เป็น idempotent)
+//
+// โครงตารางด้านล่างเป็น snapshot ของ entity ณ ตอนนั้น (ไม่อ้าง entity เพื่อไม่ให้ migration นี้เปลี่ยนตาม entity ในอนาคต)
+// field ความสัมพันธ์ต้องคงไว้ เพราะ AutoMigrate สร้าง foreign key จากมัน — ห้ามแก้ไฟล์นี้
+// entity ใหม่ / คอลัมน์ใหม่หลังจากนี้ต้องเพิ่มเป็น migration ใหม่เสมอ
+
+type user0001 struct {
+	gorm.Model
+	Email       string `gorm:"uniqueIndex;not null"`
+	Password    string
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+	Address     string
+	Role        string `gorm:"not null;default:customer"`
+
+	AvatarBase64 string `gorm:"column:avatar_base64"`
+
+	RestaurantsOwned []restaurant0001    `gorm:"foreignKey:UserID"`
+	Orders           []order0001         `gorm:"foreignKey:UserID"`
+	Reviews          []review0001        `gorm:"foreignKey:UserID"`
+	MessagesSent     []message0001       `gorm:"foreignKey:UserSenderID"`
+	UserPromotions   []userPromotion0001 `gorm:"foreignKey:UserID"`
+	RiderProfile     *rider0001          `gorm:"foreignKey:UserID"`
+	Reports          []report0001        `gorm:"foreignKey:UserID"`
+}
+
+func (user0001) TableName() string { return "users" }
+
+type admin0001 struct {
+	gorm.Model
+	Name string
+
+	UserID uint
+	User   user0001
+
+	Restaurants []restaurant0001 `gorm:"foreignKey:AdminID"`
+	Riders      []rider0001      `gorm:"foreignKey:AdminID"`
+	Promotions  []promotion0001  `gorm:"foreignKey:AdminID"`
+	Reports     []report0001     `gorm:"foreignKey:AdminID"`
+}
+
+func (admin0001) TableName() string { return "admins" }
+
+type restaurantCategory0001 struct {
+	gorm.Model
+	CategoryName string `gorm:"size:100;uniqueIndex;not null"`
+
+	Restaurants []restaurant0001 `gorm:"foreignKey:RestaurantCategoryID"`
+}
+
+func (restaurantCategory0001) TableName() string { return "restaurant_categories" }
+
+type restaurantStatus0001 struct {
+	gorm.Model
+	StatusName string `gorm:"size:100;uniqueIndex;not null"`
+
+	Restaurants []restaurant0001 `gorm:"foreignKey:RestaurantStatusID"`
+}
+
+func (restaurantStatus0001) TableName() string { return "restaurant_statuses" }
+
+type restaurant0001 struct {
+	gorm.Model
+	Name        string
+	Address     string
+	Description string
+	Picture     string `gorm:"column:picture_base64"`
+
+	OpeningTime string
+	ClosingTime string
+
+	LeadTimeMinutes int `gorm:"not null;default:30"`
+
+	RestaurantCategoryID uint
+	RestaurantCategory   restaurantCategory0001
+
+	RestaurantStatusID uint
+	RestaurantStatus   restaurantStatus0001
+
+	PromptPay string `gorm:"column:prompt_pay;type:varchar(32)"`
+
+	UserID uint
+	User   user0001
+
+	AdminID *uint
+	Admin   *admin0001
+
+	Menus   []menu0001   `gorm:"foreignKey:RestaurantID"`
+	Orders  []order0001  `gorm:"foreignKey:RestaurantID"`
+	Reviews []review0001 `gorm:"foreignKey:RestaurantID"`
+}
+
+func (restaurant0001) TableName() string { return "restaurants" }
+
+type menuType0001 struct {
+	gorm.Model
+	TypeName string
+
+	Menus []menu0001 `gorm:"foreignKey:MenuTypeID"`
+}
+
+func (menuType0001) TableName() string { return "menu_types" }
+
+type menuStatus0001 struct {
+	gorm.Model
+	StatusName string
+
+	Menus []menu0001 `gorm:"foreignKey:MenuStatusID"`
+}
+
+func (menuStatus0001) TableName() string { return "menu_statuses" }
+
+type menu0001 struct {
+	gorm.Model
+	Name   string
+	Detail string
+	Price  int64
+
+	Image string
+
+	MenuTypeID uint
+	MenuType   menuType0001
+
+	RestaurantID uint
+	Restaurant   restaurant0001
+
+	MenuStatusID uint
+	MenuStatus   menuStatus0001
+
+	DailyQuota     *int
+	StockRemaining int `gorm:"not null;default:0"`
+	StockResetAt   *time.Time
+	AutoOutOfStock bool `gorm:"not null;default:false"`
+
+	OrderItems []orderItem0001 `gorm:"foreignKey:MenuID"`
+}
+
+func (menu0001) TableName() string { return "menus" }
+
+type orderStatus0001 struct {
+	gorm.Model
+	StatusName string
+
+	Orders []order0001 `gorm:"foreignKey:OrderStatusID"`
+}
+
+func (orderStatus0001) TableName() string { return "order_statuses" }
+
+type order0001 struct {
+	gorm.Model
+	Subtotal    int64
+	Discount    int64
+	DeliveryFee int64
+	Total       int64
+	Address     string `gorm:"type:text"`
+
+	ScheduledFor *time.Time `gorm:"index"`
+	ReleasedAt   *time.Time
+
+	UserID uint
+	User   user0001
+
+	RestaurantID uint
+	Restaurant   restaurant0001
+
+	OrderStatusID uint
+	OrderStatus   orderStatus0001
+
+	OrderItems []orderItem0001 `gorm:"foreignKey:OrderID"`
+	Payments   []payment0001   `gorm:"foreignKey:OrderID"`
+	Reviews    []review0001    `gorm:"foreignKey:OrderID"`
+
+	ChatRoom  *chatRoom0001   `gorm:"foreignKey:OrderID;references:ID"`
+	RiderWork []riderWork0001 `gorm:"foreignKey:OrderID"`
+}
+
+func (order0001) TableName() string { return "orders" }
+
+type orderItem0001 struct {
+	gorm.Model
+	Qty       int
+	UnitPrice int64
+	Total     int64
+	Note      string
+
+	OrderID uint
+	Order   order0001
+
+	MenuID uint
+	Menu   menu0001
+}
+
+func (orderItem0001) TableName() string { return "order_items" }
+
+type cart0001 struct {
+	gorm.Model
+	UserID       uint `gorm:"uniqueIndex"`
+	User         user0001
+	RestaurantID uint
+	Restaurant   restaurant0001
+
+	Items []cartItem0001 `gorm:"foreignKey:CartID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (cart0001) TableName() string { return "carts" }
+
+type cartItem0001 struct {
+	gorm.Model
+	CartID uint
+	Cart   cart0001
+
+	MenuID uint
+	Menu   *menu0001 `gorm:"foreignKey:MenuID;references:ID"`
+
+	Qty       int
+	UnitPrice int64
+	Total     int64
+	Note      string
+}
+
+func (cartItem0001) TableName() string { return "cart_items" }
+
+type paymentMethod0001 struct {
+	gorm.Model
+	MethodName string `gorm:"size:100;uniqueIndex;not null"`
+
+	Payments []payment0001 `gorm:"foreignKey:PaymentMethodID"`
+}
+
+func (paymentMethod0001) TableName() string { return "payment_methods" }
+
+type paymentStatus0001 struct {
+	gorm.Model
+	StatusName string `gorm:"size:100;uniqueIndex;not null"`
+
+	Payments []payment0001 `gorm:"foreignKey:PaymentStatusID"`
+}
+
+func (paymentStatus0001) TableName() string { return "payment_statuses" }
+
+type payment0001 struct {
+	gorm.Model
+	Amount          int64
+	PaidAt          *time.Time
+	SlipContentType string `gorm:"type:varchar(64)"`
+	SlipBase64      string
+	TransRef        *string `gorm:"size:100;uniqueIndex"`
+
+	PaymentMethodID uint
+	PaymentMethod   paymentMethod0001
+
+	OrderID uint `gorm:"uniqueIndex"`
+	Order   order0001
+
+	PaymentStatusID uint
+	PaymentStatus   paymentStatus0001
+}
+
+func (payment0001) TableName() string { return "payments" }
+
+type riderStatus0001 struct {
+	gorm.Model
+	StatusName string `gorm:"size:100;uniqueIndex;not null"`
+
+	Riders []rider0001 `gorm:"foreignKey:RiderStatusID"`
+}
+
+func (riderStatus0001) TableName() string { return "rider_statuses" }
+
+type rider0001 struct {
+	gorm.Model
+	VehiclePlate string
+	License      string
+	NationalID   string
+	Zone         string
+	DriveCard    string
+
+	RiderStatusID uint
+	RiderStatus   riderStatus0001
+
+	AdminID *uint
+	Admin   *admin0001
+
+	UserID uint
+	User   user0001
+
+	Works []riderWork0001 `gorm:"foreignKey:RiderID"`
+}
+
+func (rider0001) TableName() string { return "riders" }
+
+type riderWork0001 struct {
+	gorm.Model
+	WorkAt   *time.Time
+	FinishAt *time.Time `gorm:"index:idx_order_finish;index:idx_rider_finish"`
+
+	OrderID uint `gorm:"index:idx_order_finish"`
+	Order   order0001
+
+	RiderID uint `gorm:"index:idx_rider_finish"`
+	Rider   rider0001
+}
+
+func (riderWork0001) TableName() string { return "rider_works" }
+
+type chatRoom0001 struct {
+	gorm.Model
+	OrderID uint `gorm:"uniqueIndex"`
+
+	Order order0001
+
+	Messages []message0001 `gorm:"foreignKey:RoomID;references:ID"`
+}
+
+func (chatRoom0001) TableName() string { return "chat_rooms" }
+
+type messageType0001 struct {
+	gorm.Model
+	Name     string        `gorm:"size:100;uniqueIndex;not null"`
+	Messages []message0001 `gorm:"foreignKey:TypeMessageID;references:ID"`
+}
+
+func (messageType0001) TableName() string { return "message_types" }
+
+type message0001 struct {
+	gorm.Model
+	Body string `gorm:"type:text;not null"`
+
+	TypeMessageID uint `gorm:"not null"`
+	TypeMessage   messageType0001
+
+	UserSenderID uint `gorm:"not null"`
+	UserSender   user0001
+
+	RoomID uint `gorm:"not null"`
+	Room   chatRoom0001
+}
+
+func (message0001) TableName() string { return "messages" }
+
+type promoType0001 struct {
+	gorm.Model
+	NameType   string          `gorm:"type:text;not null;default:''column:name_type"`
+	Promotions []promotion0001 `gorm:"foreignKey:PromoTypeID"`
+}
+
+func (promoType0001) TableName() string { return "promo_types" }
+
+type promotion0001 struct {
+	gorm.Model
+	PromoCode   string `gorm:"size:50;uniqueIndex;not null"`
+	PromoDetail string
+	Values      uint
+	MinOrder    int64
+	StartAt     *time.Time
+	EndAt       *time.Time
+
+	PromoTypeID uint
+	PromoType   promoType0001
+
+	AdminID uint
+	Admin   admin0001
+
+	UserPromotions []userPromotion0001 `gorm:"foreignKey:PromotionID"`
+}
+
+func (promotion0001) TableName() string { return "promotions" }
+
+type userPromotion0001 struct {
+	gorm.Model
+	PromotionID uint          `gorm:"index:uniq_user_promo,unique"`
+	Promotion   promotion0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	UserID uint `gorm:"index:uniq_user_promo,unique"`
+	User   user0001
+
+	IsUsed bool
+}
+
+func (userPromotion0001) TableName() string { return "user_promotions" }
+
+type review0001 struct {
+	gorm.Model
+	Rating     int
+	Comments   string
+	ReviewDate time.Time
+
+	UserID uint `gorm:"not null;index"`
+	User   user0001
+
+	RestaurantID uint `gorm:"not null;index;index:idx_restaurant_date,priority:1"`
+	Restaurant   restaurant0001
+
+	OrderID uint `gorm:"not null;uniqueIndex"`
+	Order   order0001
+}
+
+func (review0001) TableName() string { return "reviews" }
+
+type issueType0001 struct {
+	gorm.Model
+	TypeName string
+
+	Reports []report0001 `gorm:"foreignKey:IssueTypeID"`
+}
+
+func (issueType0001) TableName() string { return "issue_types" }
+
+type report0001 struct {
+	gorm.Model
+	Name        string
+	Email       string
+	PhoneNumber string
+	Description string
+	DateAt      *time.Time
+	Picture     string
+
+	IssueTypeID uint
+	IssueType   issueType0001
+
+	UserID uint
+	User   user0001
+
+	AdminID uint
+	Admin   admin0001
+
+	Status string `gorm:"type:varchar(50);default:'pending'"`
+}
+
+func (report0001) TableName() string { return "reports" }
+
+type restaurantApplication0001 struct {
+	gorm.Model
+	Name        string
+	Address     string
+	Phone       string
+	Description string
+	Picture     string `gorm:"column:picture_base64"`
+
+	OpeningTime string
+	ClosingTime string
+
+	RestaurantCategoryID uint
+	RestaurantCategory   restaurantCategory0001 `gorm:"foreignKey:RestaurantCategoryID"`
+
+	PromptPay string `gorm:"column:prompt_pay;type:varchar(32)"`
+
+	OwnerUserID uint
+	OwnerUser   user0001
+
+	Status string `gorm:"not null;default:pending"`
+
+	AdminID      *uint
+	ReviewedAt   *time.Time
+	RejectReason *string
+}
+
+func (restaurantApplication0001) TableName() string { return "restaurant_applications" }
+
+type riderApplication0001 struct {
+	gorm.Model
+	VehiclePlate string
+	License      string
+	NationalID   string
+	Zone         string
+	DriveCard    string
+
+	UserID uint     `gorm:"index"`
+	User   user0001 `gorm:"foreignKey:UserID;references:ID"`
+
+	Status string `gorm:"not null;default:pending"`
+
+	AdminID      *uint
+	ReviewedAt   *time.Time
+	RejectReason *string
+}
+
+func (riderApplication0001) TableName() string { return "rider_applications" }
+
+type webhookEndpoint0001 struct {
+	gorm.Model
+	URL         string `gorm:"type:text;not null"`
+	Description string
+	Secret      string `gorm:"type:varchar(100);not null"`
+
+	Events   string `gorm:"type:text"`
+	IsActive bool   `gorm:"not null;default:true"`
+
+	RestaurantID uint `gorm:"index"`
+	Restaurant   restaurant0001
+
+	Deliveries []webhookDelivery0001 `gorm:"foreignKey:EndpointID"`
+}
+
+func (webhookEndpoint0001) TableName() string { return "webhook_endpoints" }
+
+type webhookDelivery0001 struct {
+	gorm.Model
+	Event   string `gorm:"size:100;index"`
+	Payload string `gorm:"type:text"`
+
+	Status        string `gorm:"size:20;not null;default:pending;index"`
+	Attempts      int
+	ResponseCode  int
+	ResponseBody  string `gorm:"type:text"`
+	LastError     string `gorm:"type:text"`
+	NextAttemptAt *time.Time
+	DeliveredAt   *time.Time
+
+	EndpointID uint `gorm:"index"`
+	Endpoint   webhookEndpoint0001
+}
+
+func (webhookDelivery0001) TableName() string { return "webhook_deliveries" }
+
+type deviceToken0001 struct {
+	gorm.Model
+	Platform string `gorm:"size:20;not null"`
+	Token    string `gorm:"size:512;uniqueIndex;not null"`
+
+	LastSeenAt *time.Time
+
+	UserID uint `gorm:"index;not null"`
+	User   user0001
+}
+
+func (deviceToken0001) TableName() string { return "device_tokens" }
+
+type notificationPreference0001 struct {
+	gorm.Model
+	UserID uint `gorm:"uniqueIndex;not null"`
+	User   user0001
+
+	Locale string `gorm:"size:5;not null;default:th"`
+
+	PushEnabled  bool `gorm:"not null;default:true"`
+	OrderUpdates bool `gorm:"not null;default:true"`
+	ChatMessages bool `gorm:"not null;default:true"`
+	Applications bool `gorm:"not null;default:true"`
+}
+
+func (notificationPreference0001) TableName() string { return "notification_preferences" }
+
+type notification0001 struct {
+	gorm.Model
+	Type  string `gorm:"size:50;index"`
+	Title string
+	Body  string `gorm:"type:text"`
+
+	Link string
+	Data string `gorm:"type:text"`
+
+	IsRead bool `gorm:"not null;default:false;index:idx_notification_user_read,priority:2"`
+	ReadAt *time.Time
+
+	UserID uint `gorm:"index:idx_notification_user_read,priority:1"`
+	User   user0001
+}
+
+func (notification0001) TableName() string { return "notifications" }
+
+type idempotencyKey0001 struct {
+	gorm.Model
+	UserID uint   `gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key    string `gorm:"column:idempotency_key;size:255;uniqueIndex:idx_idempotency_user_key"`
+
+	Method      string `gorm:"size:10"`
+	Path        string
+	RequestHash string `gorm:"size:64"`
+
+	Completed    bool `gorm:"not null;default:false"`
+	StatusCode   int
+	ContentType  string
+	ResponseBody string `gorm:"type:text"`
+
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (idempotencyKey0001) TableName() string { return "idempotency_keys" }
+
+func baselineModels() []any {
+	return []any{
+		&user0001{},
+		&admin0001{},
+		&restaurantCategory0001{},
+		&restaurantStatus0001{},
+		&restaurant0001{},
+		&menuType0001{},
+		&menuStatus0001{},
+		&menu0001{},
+		&orderStatus0001{},
+		&order0001{},
+		&orderItem0001{},
+		&cart0001{},
+		&cartItem0001{},
+		&paymentMethod0001{},
+		&paymentStatus0001{},
+		&payment0001{},
+		&riderStatus0001{},
+		&rider0001{},
+		&riderWork0001{},
+		&chatRoom0001{},
+		&messageType0001{},
+		&message0001{},
+		&promoType0001{},
+		&promotion0001{},
+		&userPromotion0001{},
+		&review0001{},
+		&issueType0001{},
+		&report0001{},
+		&restaurantApplication0001{},
+		&riderApplication0001{},
+		&webhookEndpoint0001{},
+		&webhookDelivery0001{},
+		&deviceToken0001{},
+		&notificationPreference0001{},
+		&notification0001{},
+		&idempotencyKey0001{},
+	}
+}
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(baselineModels()...)
+		},
+		Down: func(tx *gorm.DB) error {
+			models := baselineModels()
+			for i := len(models) - 1; i >= 0; i-- {
+				if err := tx.Migrator().DropTable(models[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// โครงตาราง ณ migration นี้ (ไม่อ้าง entity เพื่อไม่ให้เปลี่ยนตาม entity ในอนาคต)
+type loginAttempt0002 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Email        string `gorm:"size:255;uniqueIndex"`
+	Failures     int    `gorm:"not null;default:0"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+func (loginAttempt0002) TableName() string { return "login_attempts" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "login_attempts",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&loginAttempt0002{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("login_attempts")
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// โครงตาราง ณ migration นี้ (ไม่อ้าง entity เพื่อไม่ให้เปลี่ยนตาม entity ในอนาคต)
+type chatRead0003 struct {
+	ID                uint `gorm:"primaryKey"`
+	RoomID            uint `gorm:"not null;uniqueIndex:idx_chat_read_room_user"`
+	UserID            uint `gorm:"not null;uniqueIndex:idx_chat_read_room_user"`
+	LastReadMessageID uint `gorm:"not null;default:0"`
+	ReadAt            time.Time
+}
+
+func (chatRead0003) TableName() string { return "chat_reads" }
+
+// index ใหม่บน messages สำหรับนับข้อความที่ยังไม่อ่านต่อห้อง (room_id, id)
+type message0003 struct {
+	ID     uint `gorm:"primaryKey;index:idx_messages_room_id,priority:2"`
+	RoomID uint `gorm:"index:idx_messages_room_id,priority:1"`
+}
+
+func (message0003) TableName() string { return "messages" }
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "chat_reads",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&chatRead0003{}); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&message0003{}, "idx_messages_room_id")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&message0003{}, "idx_messages_room_id"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("chat_reads")
+		},
+	})
+}
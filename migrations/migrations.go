@@ -0,0 +1,171 @@
+// Package migrations = schema แบบมีเวอร์ชัน (แทน AutoMigrate ตอนบูต)
+//
+// เพิ่ม migration ใหม่: สร้างไฟล์ NNNN_name.go แล้ว register ใน init()
+// ห้ามแก้ migration ที่ถูก apply ไปแล้ว ให้เพิ่มตัวใหม่แทนเสมอ
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrUnknownVersion = errors.New("database has migrations unknown to this build")
+
+// Migration = การเปลี่ยน schema หนึ่งขั้น (Up/Down รันใน transaction เดียวกับการบันทึกเวอร์ชัน)
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SQL สร้าง Up/Down จาก SQL ดิบ (ว่าง = ไม่ทำอะไร)
+func SQL(up, down string) (func(*gorm.DB) error, func(*gorm.DB) error) {
+	run := func(stmt string) func(*gorm.DB) error {
+		return func(tx *gorm.DB) error {
+			if stmt == "" {
+				return nil
+			}
+			return tx.Exec(stmt).Error
+		}
+	}
+	return run(up), run(down)
+}
+
+// schemaMigration = แถวในตาราง schema_migrations
+type schemaMigration struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+var registry []Migration
+
+func register(m Migration) {
+	for _, r := range registry {
+		if r.Version == m.Version {
+			panic(fmt.Sprintf("migrations: duplicate version %d", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All คืน migration ทั้งหมดเรียงตามเวอร์ชัน
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// Status = สถานะของ migration หนึ่งตัวบน DB
+type Status struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+func applied(db *gorm.DB) (map[uint]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uint]schemaMigration, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+// Up apply migration ที่ยังไม่ได้รันทั้งหมดตามลำดับ แล้วคืนตัวที่เพิ่ง apply
+func Up(db *gorm.DB) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	var ran []Migration
+	for _, m := range registry {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Down ย้อน migration ล่าสุด steps ตัว แล้วคืนตัวที่ถูกย้อน
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint]Migration, len(registry))
+	for _, m := range registry {
+		byVersion[m.Version] = m
+	}
+
+	versions := make([]uint, 0, len(done))
+	for v := range done {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	var reverted []Migration
+	for _, v := range versions {
+		if len(reverted) >= steps {
+			break
+		}
+		m, ok := byVersion[v]
+		if !ok {
+			return reverted, fmt.Errorf("%w: version %d", ErrUnknownVersion, v)
+		}
+		if m.Down == nil {
+			return reverted, fmt.Errorf("migration %04d_%s is irreversible", m.Version, m.Name)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// List คืนสถานะของทุก migration (AppliedAt = nil คือยังไม่ได้ apply)
+func List(db *gorm.DB) ([]Status, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(registry))
+	for _, m := range registry {
+		s := Status{Version: m.Version, Name: m.Name}
+		if r, ok := done[m.Version]; ok {
+			at := r.AppliedAt
+			s.AppliedAt = &at
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
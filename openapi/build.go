@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Op = คำอธิบายของ route หนึ่งเส้น (ส่วนที่ gin ไม่รู้)
+type Op struct {
+	Summary     string
+	Description string
+	Public      bool // ไม่ต้องแนบ JWT
+	Hidden      bool // ไม่ใส่ในเอกสาร (เช่น ไฟล์ static)
+
+	Request any  // body: struct ตัวอย่าง / Fields / *Schema (nil = ไม่มี body)
+	Form    bool // body เป็น multipart/form-data แทน JSON
+	Query   any  // struct ที่มี form tag → query parameters
+
+	Status   int // status เมื่อสำเร็จ (0 = 200)
+	Response any // data ใน envelope (nil = ไม่มี data)
+}
+
+// Routes = Op ของทุก route, key = "METHOD /path" แบบที่ประกาศกับ gin เช่น "GET /orders/:id"
+type Routes map[string]Op
+
+// Key = key ของ Routes สำหรับ method + path ของ gin
+func Key(method, path string) string { return method + " " + path }
+
+// Build สร้างเอกสารจาก route ที่ลงทะเบียนจริง
+// problems = route ที่ไม่มี Op และ Op ที่ไม่มี route แล้ว (เอกสารไม่ครบ/ค้าง)
+func Build(info Info, routes gin.RoutesInfo, ops Routes, gen *Generator) (doc *Document, problems []string) {
+	doc = &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	errorBody := errorEnvelope(gen)
+
+	seen := map[string]bool{}
+	usedIDs := map[string]int{}
+	for _, r := range routes {
+		key := Key(r.Method, r.Path)
+		op, ok := ops[key]
+		seen[key] = true
+		if !ok {
+			problems = append(problems, "undocumented route: "+key)
+		}
+		if op.Hidden {
+			continue
+		}
+
+		path, params := convertPath(r.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		o := &Operation{
+			OperationID: operationID(r.Handler, usedIDs),
+			Summary:     op.Summary,
+			Description: op.Description,
+			Tags:        []string{tagOf(r.Path)},
+			Parameters:  params,
+			Responses:   map[string]*Response{"default": {Description: "error", Content: jsonContent(errorBody)}},
+		}
+		if !op.Public {
+			o.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+		if op.Query != nil {
+			o.Parameters = append(o.Parameters, queryParams(gen, op.Query)...)
+		}
+		if op.Request != nil {
+			ct := "application/json"
+			if op.Form {
+				ct = "multipart/form-data"
+			}
+			o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{ct: {Schema: gen.Of(op.Request)}}}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &Response{Description: http.StatusText(status)}
+		if status != http.StatusNoContent && status != http.StatusSwitchingProtocols {
+			success.Content = jsonContent(successEnvelope(gen, op.Response))
+		}
+		o.Responses[strconv.Itoa(status)] = success
+
+		(*item)[strings.ToLower(r.Method)] = o
+	}
+
+	for key := range ops {
+		if !seen[key] {
+			problems = append(problems, "documented route is not registered: "+key)
+		}
+	}
+	sort.Strings(problems)
+	doc.Components.Schemas = gen.Schemas()
+	return doc, problems
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// รูปแบบเดียวกับ resp.Envelope ตอนสำเร็จ
+func successEnvelope(gen *Generator, data any) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"ok":        {Type: "boolean", Enum: []any{true}},
+			"requestId": {Type: "string"},
+		},
+		Required:             []string{"ok"},
+		AdditionalProperties: false,
+	}
+	if data != nil {
+		s.Properties["data"] = gen.Of(data)
+		s.Required = append(s.Required, "data")
+	}
+	return s
+}
+
+func errorEnvelope(gen *Generator) *Schema {
+	gen.schemas["ErrorEnvelope"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"ok":        {Type: "boolean", Enum: []any{false}},
+			"error":     gen.Of(resp.APIError{}),
+			"requestId": {Type: "string"},
+		},
+		Required:             []string{"error", "ok"},
+		AdditionalProperties: false,
+	}
+	return refTo("ErrorEnvelope")
+}
+
+// "/orders/:id/*rest" → "/orders/{id}/{rest}" + path parameters
+func convertPath(p string) (string, []Parameter) {
+	var params []Parameter
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if part == "" || (part[0] != ':' && part[0] != '*') {
+			continue
+		}
+		name := part[1:]
+		schema := &Schema{Type: "string"}
+		if strings.HasSuffix(name, "id") || strings.HasSuffix(name, "Id") {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		}
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		parts[i] = "{" + name + "}"
+	}
+	return strings.Join(parts, "/"), params
+}
+
+func queryParams(gen *Generator, sample any) []Parameter {
+	t := reflect.TypeOf(sample)
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		s := gen.schemaOf(ft)
+		required := applyBinding(s, f.Tag.Get("binding"))
+		params = append(params, Parameter{Name: name, In: "query", Required: required, Schema: s})
+	}
+	return params
+}
+
+// tag = segment แรกของ path (ข้าม /api)
+func tagOf(path string) string {
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && seg != "api" {
+			return seg
+		}
+	}
+	return "root"
+}
+
+var closureSuffix = regexp.MustCompile(`\.func\d+$`)
+
+// "backend/controllers.(*OrderController).Create-fm" → "OrderController.Create"
+func operationID(handler string, used map[string]int) string {
+	id := handler[strings.LastIndex(handler, "/")+1:]
+	id = strings.TrimSuffix(id, "-fm")
+	id = strings.NewReplacer("(*", "", ")", "").Replace(id)
+	if _, rest, ok := strings.Cut(id, "."); ok {
+		id = rest
+	}
+	id = closureSuffix.ReplaceAllString(id, "") // handler ที่คืนจาก func (เช่น ListActivePromotions(db))
+	used[id]++
+	if n := used[id]; n > 1 {
+		id = fmt.Sprintf("%s%d", id, n)
+	}
+	return id
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+
+	"backend/pkg/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UICSP = CSP ของหน้า Swagger UI (โหลด script/style จาก unpkg, อ่านเอกสารจาก origin เดียวกัน)
+const UICSP = "default-src 'none'; script-src https://unpkg.com 'unsafe-inline'; style-src https://unpkg.com; " +
+	"img-src 'self' data: https://unpkg.com; connect-src 'self'; frame-ancestors 'none'"
+
+// Handler เสิร์ฟเอกสารเป็น JSON (ไม่ห่อ envelope)
+// build ถูกเรียกครั้งแรกที่มีคนขอ เพราะต้องรอให้ลงทะเบียน route ครบก่อน
+func Handler(build func() *Document) gin.HandlerFunc {
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { body, err = json.Marshal(build()) })
+		if err != nil {
+			resp.Error(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
+
+var uiPage = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+</script>
+</body>
+</html>
+`))
+
+// UIHandler = หน้า Swagger UI ที่อ่านเอกสารจาก specURL
+func UIHandler(title, specURL string) gin.HandlerFunc {
+	var buf bytes.Buffer
+	if err := uiPage.Execute(&buf, struct{ Title, SpecURL string }{title, specURL}); err != nil {
+		panic(err)
+	}
+	page := buf.Bytes()
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
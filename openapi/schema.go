@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields = object ที่ handler ประกอบเองด้วย gin.H (key → ตัวอย่างค่า หรือ *Schema)
+// ทุก key ถือเป็น required และห้ามมี key อื่น
+type Fields map[string]any
+
+// Generator แปลง Go type เป็น Schema; struct ที่มีชื่อเก็บไว้ใน components แล้วอ้างด้วย $ref
+type Generator struct {
+	schemas   map[string]*Schema
+	names     map[reflect.Type]string
+	overrides map[reflect.Type]*Schema
+}
+
+func NewGenerator() *Generator {
+	g := &Generator{
+		schemas:   map[string]*Schema{},
+		names:     map[reflect.Type]string{},
+		overrides: map[reflect.Type]*Schema{},
+	}
+	g.Define(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+	g.Define(json.RawMessage{}, Any())
+	return g
+}
+
+// Define กำหนด schema ของ type เอง (เช่น type ที่มี MarshalJSON)
+func (g *Generator) Define(sample any, s *Schema) {
+	g.overrides[reflect.TypeOf(sample)] = s
+}
+
+// Schemas = components ที่สร้างไว้แล้ว
+func (g *Generator) Schemas() map[string]*Schema { return g.schemas }
+
+// Of คืน schema ของตัวอย่างค่า (*Schema ใช้ตรง ๆ, Fields = object ประกอบเอง, nil = อะไรก็ได้)
+func (g *Generator) Of(v any) *Schema {
+	switch v := v.(type) {
+	case nil:
+		return Any()
+	case *Schema:
+		return v
+	case Fields:
+		return g.fields(v)
+	}
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+func (g *Generator) fields(f Fields) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	for k, v := range f {
+		s.Properties[k] = g.Of(v)
+		s.Required = append(s.Required, k)
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if s, ok := g.overrides[t]; ok {
+		cp := *s
+		return &cp
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Nullable(g.schemaOf(t.Elem()))
+	case reflect.Interface:
+		return Any()
+	}
+
+	// type ที่ encode เอง: ไม่รู้รูปร่าง
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return Any()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return refTo(g.component(t))
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// nil slice → null ตอน encode
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem()), Nullable: true}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+	return Any()
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// component ลงทะเบียน struct ที่มีชื่อ (ชื่อชนกันข้าม package → เติมชื่อ package ข้างหน้า)
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := unsafeName.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.schemas[name] = &Schema{} // จองชื่อไว้ก่อน กัน type ที่อ้างถึงตัวเอง
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts := fieldName(f)
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// struct ฝัง (เช่น gorm.Model) → field ขึ้นมาอยู่ระดับเดียวกัน
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		ps := g.schemaOf(f.Type)
+		if strings.Contains(opts, "string") {
+			ps = &Schema{Type: "string"}
+		}
+		if applyBinding(ps, f.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = ps
+	}
+}
+
+// ชื่อ field ตาม json tag (struct ที่ bind จาก form ใช้ form tag)
+func fieldName(f reflect.StructField) (name, opts string) {
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		name, opts, _ = strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			return "-", ""
+		}
+		return name, opts
+	}
+	name, _, _ = strings.Cut(f.Tag.Get("form"), ",")
+	return name, ""
+}
+
+// applyBinding แปลง rule ของ validator เป็น constraint ใน schema (คืน true ถ้า required)
+func applyBinding(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			return required // rule หลัง dive ใช้กับสมาชิกใน slice
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "min", "gte":
+			setBound(s, param, true)
+		case "max", "lte":
+			setBound(s, param, false)
+		}
+	}
+	return required
+}
+
+func enumValue(typ, v string) any {
+	if typ == "integer" || typ == "number" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func setBound(s *Schema, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		i := int(n)
+		if lower {
+			s.MinLength = &i
+		} else {
+			s.MaxLength = &i
+		}
+	case "array":
+		if lower {
+			i := int(n)
+			s.MinItems = &i
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package openapi สร้างเอกสาร OpenAPI 3 จาก route ที่ลงทะเบียนกับ gin + struct ของ request/response
+//
+// schema ได้จาก reflect ของ Go type (json tag = ชื่อ field, binding tag = required/min/max/oneof)
+// จึงไม่ต้องเขียน schema ซ้ำ และ Validate ใช้ตรวจว่า response จริงยังตรงกับเอกสาร (contract test)
+package openapi
+
+// Document = ราก OpenAPI 3.0
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem = operation ต่อ method ของ path เดียว (key = get, post, ...)
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path | query | header
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Schema = JSON Schema แบบที่ OpenAPI 3.0 ใช้ (เฉพาะส่วนที่ระบบนี้ต้องการ)
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+
+	// false = ห้ามมี field นอกเหนือจาก Properties, *Schema = ชนิดของ value ใน map
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+// Any = schema ว่าง (ค่าอะไรก็ได้) ใช้กับ field ที่รูปร่างไม่ตายตัว
+func Any() *Schema { return &Schema{} }
+
+// Nullable ห่อ schema ให้รับ null ได้ ($ref ใส่ nullable ตรง ๆ ไม่ได้ใน 3.0 จึงใช้ allOf)
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	cp := *s
+	cp.Nullable = true
+	return &cp
+}
+
+func refTo(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
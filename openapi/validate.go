@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckResponse ตรวจ response จริงของ route (path แบบ gin) กับเอกสาร คืนจุดที่ไม่ตรง
+func (d *Document) CheckResponse(method, ginPath string, status int, body []byte) []string {
+	path, _ := convertPath(ginPath)
+	item := d.Paths[path]
+	if item == nil || (*item)[strings.ToLower(method)] == nil {
+		return []string{fmt.Sprintf("%s %s is not in the document", method, ginPath)}
+	}
+	op := (*item)[strings.ToLower(method)]
+
+	r := op.Responses[strconv.Itoa(status)]
+	if r == nil && status >= http.StatusBadRequest {
+		r = op.Responses["default"]
+	}
+	if r == nil {
+		return []string{fmt.Sprintf("%s %s: status %d is not documented", method, ginPath, status)}
+	}
+	if r.Content == nil {
+		if len(body) > 0 {
+			return []string{fmt.Sprintf("%s %s: expected empty body, got %d bytes", method, ginPath, len(body))}
+		}
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []string{fmt.Sprintf("%s %s: body is not JSON: %v", method, ginPath, err)}
+	}
+	return d.Validate(r.Content["application/json"].Schema, v)
+}
+
+// Validate ตรวจค่าที่ decode จาก JSON แล้ว (map/slice/float64/...) กับ schema
+func (d *Document) Validate(s *Schema, v any) []string {
+	var errs []string
+	d.validate(s, v, "$", &errs)
+	return errs
+}
+
+func (d *Document) validate(s *Schema, v any, at string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, at+": "+fmt.Sprintf(format, args...))
+	}
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if s = d.Components.Schemas[name]; s == nil {
+			fail("unknown schema %s", name)
+			return
+		}
+	}
+
+	if v == nil {
+		if !s.Nullable && (s.Type != "" || len(s.AllOf) > 0) {
+			fail("null is not allowed")
+		}
+		return
+	}
+	for _, sub := range s.AllOf {
+		d.validate(sub, v, at, errs)
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		fail("%v is not one of %v", v, s.Enum)
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]any)
+		if !ok {
+			fail("want object, got %s", kindOf(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				fail("missing field %q", name)
+			}
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				d.validate(ps, m[k], at+"."+k, errs)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					fail("undocumented field %q", k)
+				}
+			case *Schema:
+				d.validate(ap, m[k], at+"."+k, errs)
+			}
+		}
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			fail("want array, got %s", kindOf(v))
+			return
+		}
+		for i, item := range a {
+			d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i), errs)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("want string, got %s", kindOf(v))
+			return
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				fail("%q is not a date-time", str)
+			}
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			fail("want integer, got %s", kindOf(v))
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			fail("want number, got %s", kindOf(v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("want boolean, got %s", kindOf(v))
+		}
+	}
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func kindOf(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(v).Kind().String()
+}
@@ -0,0 +1,108 @@
+// Package lifecycle ดูแล goroutine เบื้องหลังของแอป (chat hub, scheduler, ...) ให้หยุดพร้อมกันตอน shutdown
+//
+// worker ทุกตัวได้ ctx เดียวกันที่ถูก cancel ตอน Shutdown; Shutdown รอจนทุกตัวคืนค่า (หรือ ctx ของ Shutdown หมดเวลา)
+// แล้วจึงเรียก hook ที่ลงทะเบียนด้วย OnStop ย้อนลำดับ (ลงทะเบียนทีหลัง = ปิดก่อน)
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]int // ชื่อ worker ที่ยังไม่คืนค่า (ไว้ log ตอนรอไม่ไหว)
+	hooks   []hook
+	stopped bool
+}
+
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{ctx: ctx, cancel: cancel, running: map[string]int{}}
+}
+
+// Go รัน worker ใน goroutine ใหม่ fn ต้องคืนค่าเมื่อ ctx ถูก cancel
+// เรียกหลัง Shutdown แล้ว = ไม่รัน
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		slog.Warn("lifecycle: worker not started, shutting down", "worker", name)
+		return
+	}
+	m.running[name]++
+	m.wg.Add(1)
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			if m.running[name]--; m.running[name] == 0 {
+				delete(m.running, name)
+			}
+			m.mu.Unlock()
+			m.wg.Done()
+		}()
+		fn(m.ctx)
+	}()
+}
+
+// OnStop ลงทะเบียนงานปิดทรัพยากรหลัง worker หยุดหมดแล้ว (เช่น ปิด DB)
+func (m *Manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Done ปิดเมื่อเริ่ม shutdown
+func (m *Manager) Done() <-chan struct{} { return m.ctx.Done() }
+
+// Shutdown cancel worker ทุกตัว รอให้หยุด แล้วเรียก OnStop hook; เรียกซ้ำได้ (ครั้งหลังไม่ทำอะไร)
+// ctx หมดเวลาก่อน worker หยุดครบ = ข้ามไปเรียก hook เลยและคืน error ที่บอกชื่อ worker ที่ค้าง
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	m.cancel()
+
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		m.mu.Lock()
+		names := make([]string, 0, len(m.running))
+		for name := range m.running {
+			names = append(names, name)
+		}
+		m.mu.Unlock()
+		errs = append(errs, fmt.Errorf("lifecycle: workers still running: %v: %w", names, ctx.Err()))
+	}
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle: stop %s: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+// Package logx = logger กลางของแอป (log/slog) ที่
+//   - ปิดข้อมูลอ่อนไหว (token, เลขบัตรประชาชน, เบอร์โทร, base64) ก่อนเขียนทุกครั้ง
+//   - ใส่ requestId และ attr ที่ผูกไว้กับ context ให้อัตโนมัติเมื่อเรียก slog.XxxContext(ctx, ...)
+//
+// ใช้งาน:
+//
+//	logx.Setup(os.Stderr, "json", "info") // ตอนบูต (ต่อจากนี้ log.Printf ก็ผ่าน handler นี้ด้วย)
+//	ctx = logx.WithRequestID(ctx, id)     // middleware
+//	slog.InfoContext(ctx, "order created", "orderId", id)
+package logx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// NewHandler สร้าง handler ตาม format (text | json) ที่ redact + เติม attr จาก context
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	var h slog.Handler
+	switch format {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text", "":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	return contextHandler{h}, nil
+}
+
+// ParseLevel แปลง debug | info | warn | error
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+	}
+	return l, nil
+}
+
+// Setup ตั้ง logger default ของ slog (และของแพ็กเกจ log) ให้ใช้ handler ของเรา
+func Setup(w io.Writer, format, level string) (*slog.Logger, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	h, err := NewHandler(w, format, l)
+	if err != nil {
+		return nil, err
+	}
+	logger := slog.New(h)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// ---------------- context ----------------
+
+type ctxKey struct{}
+
+// attr ที่ผูกกับ context (requestId, userId, roomId, ...)
+func attrsFrom(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// With คืน context ที่ log ทุกบรรทัด (ผ่าน slog.XxxContext) จะมี attr เหล่านี้ติดไปด้วย
+// args รูปแบบเดียวกับ slog.Logger.With ("key", value, ...)
+func With(ctx context.Context, args ...any) context.Context {
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	parent := attrsFrom(ctx)
+	attrs := make([]slog.Attr, len(parent), len(parent)+r.NumAttrs())
+	copy(attrs, parent)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+// WithRequestID = With(ctx, "requestId", id)
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(ctx, RequestIDKey, id)
+}
+
+// RequestIDKey = ชื่อ attr ของ request id ใน log
+const RequestIDKey = "requestId"
+
+// RequestID คืน request id ที่ผูกไว้กับ ctx ("" ถ้าไม่มี)
+func RequestID(ctx context.Context) string {
+	for _, a := range attrsFrom(ctx) {
+		if a.Key == RequestIDKey {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+// contextHandler เติม attr จาก context ก่อนส่งต่อให้ handler จริง
+type contextHandler struct{ slog.Handler }
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := attrsFrom(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
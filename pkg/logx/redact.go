@@ -0,0 +1,115 @@
+package logx
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// ชื่อ key (ตัวเล็ก ไม่มี _ - .) ที่ค่าต้องปิดเสมอ
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"password":      true,
+	"secret":        true,
+	"apikey":        true,
+	"nationalid":    true,
+	"idcard":        true,
+	"promptpay":     true,
+}
+
+// key ที่ลงท้ายด้วยคำเหล่านี้ก็ถือว่าอ่อนไหว (accessToken, webhookSecret, slipBase64, phoneNumber, ...)
+var sensitiveSuffixes = []string{"token", "password", "secret", "base64", "phone", "phonenumber", "mobile"}
+
+func sensitiveKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+	if sensitiveKeys[k] {
+		return true
+	}
+	for _, s := range sensitiveSuffixes {
+		if strings.HasSuffix(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// รูปแบบในข้อความอิสระ (ลำดับสำคัญ: jwt/base64 ก่อนตัวเลข)
+var patterns = []struct {
+	re    *regexp.Regexp
+	repl  string
+	match func(string) bool // ตรวจซ้ำหลัง regex (nil = ปิดทุกตัวที่ regex เจอ)
+}{
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`), "Bearer " + redacted, nil},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), "[REDACTED:jwt]", nil},
+	{regexp.MustCompile(`(?:data:[\w/+.-]+;base64,)?[A-Za-z0-9+/]{80,}={0,2}`), "[REDACTED:base64]", nil},
+	{regexp.MustCompile(`\b\d{13}\b|\b\d-\d{4}-\d{5}-\d{2}-\d\b`), "[REDACTED:national-id]", validNationalID},
+	{regexp.MustCompile(`(?:\+66|\b0)[1-9]\d?[- ]?\d{3}[- ]?\d{4}\b`), "[REDACTED:phone]", nil},
+}
+
+// validNationalID = check digit ของเลขบัตรประชาชนถูกต้อง (กัน timestamp หน่วย ms ที่ยาว 13 หลักเหมือนกัน)
+func validNationalID(s string) bool {
+	s = strings.ReplaceAll(s, "-", "")
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(s[i]-'0') * (13 - i)
+	}
+	return int(s[12]-'0') == (11-sum%11)%10
+}
+
+// RedactString ปิด token / base64 / เลขบัตร / เบอร์โทร ที่ปนอยู่ในข้อความ
+func RedactString(s string) string {
+	for _, p := range patterns {
+		if p.match == nil {
+			s = p.re.ReplaceAllString(s, p.repl)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.match(m) {
+				return p.repl
+			}
+			return m
+		})
+	}
+	return s
+}
+
+// redactAttr = ReplaceAttr ของ handler: ปิดตามชื่อ key ก่อน แล้วค่อยตรวจเนื้อหา
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch a.Key {
+	case slog.TimeKey, slog.LevelKey, slog.SourceKey:
+		return a
+	}
+	if a.Key != slog.MessageKey && sensitiveKey(a.Key) {
+		if a.Value.Kind() == slog.KindString && a.Value.String() == "" {
+			return a
+		}
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(RedactString(v.Error()))
+		case []byte:
+			a.Value = slog.StringValue(fmt.Sprintf("[REDACTED:%d bytes]", len(v)))
+		case map[string]string:
+			m := make(map[string]string, len(v))
+			for k, s := range v {
+				if sensitiveKey(k) {
+					s = redacted
+				}
+				m[k] = RedactString(s)
+			}
+			a.Value = slog.AnyValue(m)
+		case fmt.Stringer:
+			a.Value = slog.StringValue(RedactString(v.String()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,152 @@
+package resp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// code มาตรฐานตาม status (error เฉพาะทางใช้ code ของตัวเองผ่าน Register)
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidJSON      = "invalid_json"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeUnprocessable    = "unprocessable"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+	CodeUpstream         = "upstream_error"
+	CodeUnavailable      = "unavailable"
+)
+
+var (
+	mappersMu sync.RWMutex
+	mappers   []func(error) *APIError
+)
+
+// Register ผูก error sentinel กับ status + code (ตรวจด้วย errors.Is)
+// message ที่ตอบ client = err.Error() ของ error นั้น
+func Register(target error, status int, code string) {
+	RegisterFunc(func(err error) *APIError {
+		if !errors.Is(err, target) {
+			return nil
+		}
+		return &APIError{Status: status, Code: code, Message: err.Error()}
+	})
+}
+
+// RegisterType ผูก error แบบ struct (ตรวจด้วย errors.As) เช่น RegisterType[*services.AlreadyPaidError](...)
+func RegisterType[T error](status int, code string) {
+	RegisterFunc(func(err error) *APIError {
+		var t T
+		if !errors.As(err, &t) {
+			return nil
+		}
+		return &APIError{Status: status, Code: code, Message: err.Error()}
+	})
+}
+
+// RegisterFunc ผูกด้วยตัวแปลงเอง (คืน nil = ไม่ใช่ error ที่รู้จัก; ตัวที่ลงทะเบียนก่อนถูกตรวจก่อน)
+func RegisterFunc(mapper func(error) *APIError) {
+	mappersMu.Lock()
+	defer mappersMu.Unlock()
+	mappers = append(mappers, mapper)
+}
+
+// Lookup แปลง error เป็น APIError ที่จะตอบ client
+func Lookup(err error) *APIError {
+	var api *APIError
+	if errors.As(err, &api) {
+		return api
+	}
+	if e := bindingError(err); e != nil {
+		return e
+	}
+
+	mappersMu.RLock()
+	defer mappersMu.RUnlock()
+	for _, m := range mappers {
+		if e := m(err); e != nil {
+			return e
+		}
+	}
+	return &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error"}
+}
+
+// Invalid = binding ไม่ผ่าน (ShouldBindJSON / ShouldBindQuery) → 400 พร้อม field ที่ผิด
+func Invalid(c *gin.Context, err error) {
+	if e := bindingError(err); e != nil {
+		Error(c, e)
+		return
+	}
+	Error(c, New(http.StatusBadRequest, CodeBadRequest, err.Error()))
+}
+
+func bindingError(err error) *APIError {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		details := make([]FieldError, 0, len(ve))
+		fields := make([]string, 0, len(ve))
+		for _, fe := range ve {
+			details = append(details, FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()})
+			fields = append(fields, fe.Field())
+		}
+		return &APIError{
+			Status:  http.StatusBadRequest,
+			Code:    CodeValidationFailed,
+			Message: "invalid fields: " + strings.Join(fields, ", "),
+			Details: details,
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return New(http.StatusBadRequest, CodeInvalidJSON, "request body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return New(http.StatusBadRequest, CodeInvalidJSON, "request body must be a JSON object")
+	case errors.As(err, &typeErr):
+		return &APIError{
+			Status:  http.StatusBadRequest,
+			Code:    CodeInvalidJSON,
+			Message: "wrong type for field " + typeErr.Field,
+			Details: []FieldError{{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}},
+		}
+	}
+	return nil
+}
+
+func logInternal(c *gin.Context, err error) {
+	slog.ErrorContext(c.Request.Context(), "internal error", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+}
+
+// ชื่อ field ใน details ใช้ชื่อตาม json tag (เหมือนที่ client ส่งมา)
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
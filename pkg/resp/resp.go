@@ -1,3 +1,10 @@
+// Package resp = รูปแบบ response เดียวของทุก endpoint
+//
+//	สำเร็จ: {"ok": true,  "data": ..., "requestId": "..."}
+//	ผิดพลาด: {"ok": false, "error": {"code": "not_found", "message": "...", "details": [...]}, "requestId": "..."}
+//
+// error จาก service ส่งผ่าน Error แล้ว status/code มาจากตารางที่ Register ไว้
+// error ที่ไม่ได้ Register = 500 internal_error (ไม่ส่งข้อความจริงให้ client)
 package resp
 
 import (
@@ -6,21 +13,102 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RequestIDKey = key ใน gin.Context ที่ middleware RequestID ใส่ไว้
+const RequestIDKey = "requestId"
+
+type Envelope struct {
+	OK        bool      `json:"ok"`
+	Data      any       `json:"data,omitempty"`
+	Error     *APIError `json:"error,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// APIError = error ที่ตอบ client ได้ (ใช้เป็น error ธรรมดาก็ได้)
+type APIError struct {
+	Status  int          `json:"-"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Meta    gin.H        `json:"meta,omitempty"` // ข้อมูลประกอบเฉพาะกรณี เช่น ผลตรวจตะกร้า
+}
+
+func (e *APIError) Error() string { return e.Message }
+
+// FieldError = field ที่ไม่ผ่าน binding/validation
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+// New สร้าง APIError สำหรับกรณีที่ไม่มี error ของ service รองรับ
+func New(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// ---------------- success ----------------
+
+func JSON(c *gin.Context, status int, data any) {
+	c.JSON(status, Envelope{OK: true, Data: data, RequestID: c.GetString(RequestIDKey)})
+}
+
 func OK(c *gin.Context, data any) {
-	c.JSON(http.StatusOK, gin.H{"ok": true, "data": data})
+	JSON(c, http.StatusOK, data)
 }
+
 func Created(c *gin.Context, data any) {
-	c.JSON(http.StatusCreated, gin.H{"ok": true, "data": data})
+	JSON(c, http.StatusCreated, data)
+}
+
+// ---------------- error ----------------
+
+// Error ตอบ error ตามตาราง mapping และบันทึกไว้ใน c.Errors (ให้ logger เห็น)
+func Error(c *gin.Context, err error) {
+	_ = c.Error(err)
+	render(c, err, Lookup(err))
+}
+
+// ErrorWith เหมือน Error แต่แนบ meta ไปกับ error ด้วย
+func ErrorWith(c *gin.Context, err error, meta gin.H) {
+	_ = c.Error(err)
+	e := *Lookup(err)
+	e.Meta = meta
+	render(c, err, &e)
+}
+
+// Render เขียน error response (ใช้จาก middleware ที่เจอ error ค้างใน c.Errors)
+func Render(c *gin.Context, err error) {
+	render(c, err, Lookup(err))
+}
+
+func render(c *gin.Context, err error, e *APIError) {
+	if e.Status >= http.StatusInternalServerError {
+		logInternal(c, err)
+	}
+	c.AbortWithStatusJSON(e.Status, Envelope{Error: e, RequestID: c.GetString(RequestIDKey)})
 }
+
 func BadRequest(c *gin.Context, msg string) {
-	c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": msg})
+	Error(c, New(http.StatusBadRequest, CodeBadRequest, msg))
 }
+
 func Unauthorized(c *gin.Context, msg string) {
-	c.JSON(http.StatusUnauthorized, gin.H{"ok": false, "error": msg})
+	Error(c, New(http.StatusUnauthorized, CodeUnauthorized, msg))
 }
+
 func Forbidden(c *gin.Context, msg string) {
-	c.JSON(http.StatusForbidden, gin.H{"ok": false, "error": msg})
+	Error(c, New(http.StatusForbidden, CodeForbidden, msg))
+}
+
+func NotFound(c *gin.Context, msg string) {
+	Error(c, New(http.StatusNotFound, CodeNotFound, msg))
 }
+
+func Conflict(c *gin.Context, msg string) {
+	Error(c, New(http.StatusConflict, CodeConflict, msg))
+}
+
+// ServerError = 500 โดยไม่ส่งข้อความของ err ให้ client (เช่น error จาก DB)
 func ServerError(c *gin.Context, err error) {
-	c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
-}
\ No newline at end of file
+	Error(c, err)
+}
@@ -0,0 +1,67 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker ส่งข้อความภายใน process เดียว (ใช้ได้เมื่อมี instance เดียว และตอนทดสอบ)
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[string]map[*memorySub]struct{}
+}
+
+type memorySub struct {
+	ch   chan []byte
+	done chan struct{} // ปิดตอนเลิก subscribe (Publish ที่รออยู่จะได้ไม่ค้าง)
+}
+
+// จำนวนข้อความที่ค้างได้ต่อ subscriber ก่อน Publish จะรอ
+const memoryBuffer = 256
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[string]map[*memorySub]struct{}{}}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs[channel] {
+		select {
+		case s.ch <- payload:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, channel string, handler func(payload []byte)) error {
+	s := &memorySub{ch: make(chan []byte, memoryBuffer), done: make(chan struct{})}
+	b.mu.Lock()
+	if b.subs[channel] == nil {
+		b.subs[channel] = map[*memorySub]struct{}{}
+	}
+	b.subs[channel][s] = struct{}{}
+	b.mu.Unlock()
+
+	defer func() {
+		close(s.done)
+		b.mu.Lock()
+		delete(b.subs[channel], s)
+		if len(b.subs[channel]) == 0 {
+			delete(b.subs, channel)
+		}
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case payload := <-s.ch:
+			handler(payload)
+		}
+	}
+}
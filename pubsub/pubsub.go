@@ -0,0 +1,36 @@
+// Package pubsub = ส่งข้อความข้าม instance ของ backend (เช่น กระจายแชทให้ client ที่ต่อกับเครื่องอื่น)
+//
+// ใช้ MemoryBroker ได้เมื่อรัน instance เดียว; หลาย instance ให้ใช้ RedisBroker (Redis PUBLISH/SUBSCRIBE)
+// การส่งเป็นแบบ at-most-once: subscriber ที่ไม่ได้ต่ออยู่ตอน publish จะไม่ได้ข้อความนั้น
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSubscriptionClosed = subscription จบเองทั้งที่ ctx ยังไม่ถูก cancel (เช่น broker ปิด connection) → คนเรียกควร subscribe ใหม่
+var ErrSubscriptionClosed = errors.New("pubsub: subscription closed")
+
+// Broker = ช่องทาง publish/subscribe ตามชื่อ channel
+type Broker interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+
+	// Subscribe เรียก handler กับทุกข้อความใน channel จน ctx ถูก cancel (block จนกว่าจะจบ)
+	// คืน nil เฉพาะเมื่อจบเพราะ ctx; จบด้วยเหตุอื่นต้องคืน error เสมอ (เช่น ErrSubscriptionClosed)
+	// handler ถูกเรียกทีละข้อความตามลำดับ ห้ามค้างนาน
+	Subscribe(ctx context.Context, channel string, handler func(payload []byte)) error
+}
+
+// New สร้าง broker ตามชื่อ: memory | redis (url = redis://[:password@]host:port/db)
+func New(kind, url string) (Broker, error) {
+	switch strings.ToLower(kind) {
+	case "", "memory":
+		return NewMemoryBroker(), nil
+	case "redis":
+		return NewRedisBroker(url)
+	}
+	return nil, fmt.Errorf("unsupported pubsub broker: %s", kind)
+}
@@ -0,0 +1,61 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBroker ใช้ Redis PUBLISH/SUBSCRIBE (client ของ go-redis ต่อใหม่เองเมื่อหลุด)
+type RedisBroker struct {
+	Client *redis.Client
+}
+
+func NewRedisBroker(url string) (*RedisBroker, error) {
+	if url == "" {
+		return nil, fmt.Errorf("pubsub: redis url is empty")
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: redis url: %w", err)
+	}
+	return &RedisBroker{Client: redis.NewClient(opts)}, nil
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	return b.Client.Publish(ctx, channel, payload).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, channel string, handler func(payload []byte)) error {
+	sub := b.Client.Subscribe(ctx, channel)
+	defer sub.Close()
+
+	// รอให้ SUBSCRIBE สำเร็จก่อน (ต่อ Redis ไม่ได้ = คืน error ให้คนเรียกตัดสินใจ)
+	if _, err := sub.Receive(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("pubsub: redis subscribe %s: %w", channel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrSubscriptionClosed, channel)
+			}
+			handler([]byte(msg.Payload))
+		}
+	}
+}
+
+// Ready = ping Redis (ใช้กับ /readyz)
+func (b *RedisBroker) Ready(ctx context.Context) error {
+	return b.Client.Ping(ctx).Err()
+}
+
+func (b *RedisBroker) Close() error { return b.Client.Close() }
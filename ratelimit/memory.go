@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore เก็บ bucket ไว้ใน memory ของ process (ใช้ได้เมื่อมี instance เดียว)
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	per    time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate) (Result, error) {
+	if rate.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+	now := s.now()
+	perToken := float64(rate.Per) / float64(rate.Limit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Limit), last: now}
+		s.buckets[key] = b
+	}
+	b.per = rate.Per
+
+	// เติม token ตามเวลาที่ผ่านไป (ไม่เกิน Limit)
+	b.tokens = math.Min(float64(rate.Limit), b.tokens+float64(now.Sub(b.last))/perToken)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration(math.Ceil((1 - b.tokens) * perToken))
+		return Result{RetryAfter: wait}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// sweep ลบ bucket ที่เต็มแล้ว (ไม่มีใครใช้นานกว่า Per) นาทีละครั้ง
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.last) >= b.per {
+			delete(s.buckets, k)
+		}
+	}
+}
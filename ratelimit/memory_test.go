@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := Rate{Limit: 3, Per: 3 * time.Second} // เติม 1 token ต่อวินาที
+
+	type step struct {
+		at        time.Duration // นับจาก t0
+		key       string
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}
+	for _, tc := range []struct {
+		name  string
+		steps []step
+	}{
+		{"burst then wait", []step{
+			{0, "a", true, 2, 0},
+			{0, "a", true, 1, 0},
+			{0, "a", true, 0, 0},
+			{0, "a", false, 0, time.Second},
+			{500 * time.Millisecond, "a", false, 0, 500 * time.Millisecond},
+			{time.Second, "a", true, 0, 0},
+		}},
+		{"refill capped at limit", []step{
+			{0, "a", true, 2, 0},
+			{time.Hour, "a", true, 2, 0},
+			{time.Hour, "a", true, 1, 0},
+		}},
+		{"keys are independent", []step{
+			{0, "a", true, 2, 0},
+			{0, "a", true, 1, 0},
+			{0, "a", true, 0, 0},
+			{0, "b", true, 2, 0},
+			{0, "a", false, 0, time.Second},
+		}},
+		{"fractional refill", []step{
+			{0, "a", true, 2, 0},
+			{0, "a", true, 1, 0},
+			{0, "a", true, 0, 0},
+			{1500 * time.Millisecond, "a", true, 0, 0},
+			{1500 * time.Millisecond, "a", false, 0, 500 * time.Millisecond},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			for i, st := range tc.steps {
+				s.now = func() time.Time { return t0.Add(st.at) }
+				res, err := s.Take(context.Background(), st.key, rate)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := Result{Allowed: st.allowed, Remaining: st.remaining, RetryAfter: st.retry}
+				if res != want {
+					t.Fatalf("step %d (+%v %s): got %+v, want %+v", i, st.at, st.key, res, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreUnlimited(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		if res, _ := s.Take(context.Background(), "a", Rate{}); !res.Allowed {
+			t.Fatalf("take %d denied on unlimited rate", i)
+		}
+	}
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return t0 }
+	s.Take(context.Background(), "idle", Rate{Limit: 1, Per: time.Second})
+	s.now = func() time.Time { return t0.Add(2 * time.Minute) }
+	s.Take(context.Background(), "busy", Rate{Limit: 1, Per: time.Second})
+	if _, ok := s.buckets["idle"]; ok || len(s.buckets) != 1 {
+		t.Fatalf("buckets = %v, want only busy", s.buckets)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"10/1m", Rate{10, time.Minute}, false},
+		{"5/30s", Rate{5, 30 * time.Second}, false},
+		{"100/h", Rate{100, time.Hour}, false},
+		{"", Rate{}, false},
+		{"0", Rate{}, false},
+		{"10", Rate{}, true},
+		{"x/1m", Rate{}, true},
+		{"-1/1m", Rate{}, true},
+		{"10/0s", Rate{}, true},
+		{"10/forever", Rate{}, true},
+	} {
+		got, err := ParseRate(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseRate(%q) = %+v, %v; want %+v, err=%v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package ratelimit = token bucket สำหรับจำกัดจำนวน request
+//
+// ตัว bucket เก็บไว้หลัง Store: ใช้ MemoryStore ได้เมื่อรัน instance เดียว
+// ถ้ารันหลาย instance ให้ implement Store บน Redis (เช่น Lua script ที่ทำ refill + take ใน EVAL เดียว)
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate = ให้ Limit ครั้งต่อ Per (burst สูงสุด = Limit, เติม token ทีละน้อยตลอดช่วง Per)
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// ParseRate อ่านรูปแบบ "10/1m", "5/30s", "100/h" (ว่าง หรือ "0" = ไม่จำกัด)
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Rate{}, nil
+	}
+	n, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("rate %q: want LIMIT/PERIOD", s)
+	}
+	limit, err := strconv.Atoi(n)
+	if err != nil || limit < 0 {
+		return Rate{}, fmt.Errorf("rate %q: bad limit", s)
+	}
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per // "h" = "1h"
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("rate %q: bad period", s)
+	}
+	return Rate{Limit: limit, Per: d}, nil
+}
+
+// Unlimited = ไม่ได้ตั้งค่า (middleware จะข้ามไป)
+func (r Rate) Unlimited() bool { return r.Limit <= 0 || r.Per <= 0 }
+
+func (r Rate) String() string {
+	if r.Unlimited() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Per)
+}
+
+// UnmarshalText ให้ configs อ่านค่าจาก env ได้ตรง ๆ
+func (r *Rate) UnmarshalText(b []byte) error {
+	v, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Result = ผลของการขอ 1 token
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // รอเท่านี้ก่อนจะมี token ว่าง (เมื่อ Allowed = false)
+}
+
+// Store เก็บ bucket ต่อ key
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"backend/configs"
+
+	"gorm.io/gorm"
+)
+
+// runReindex = backend reindex [-rebuild]
+// สร้าง index ที่ประกาศใน entity แต่หายไปจาก DB แล้วอัปเดตสถิติให้ query planner
+func runReindex(args []string) {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	rebuild := fs.Bool("rebuild", false, "also rebuild existing indexes (REINDEX; locks tables on postgres)")
+	fs.Parse(args)
+
+	bootstrap()
+	db := configs.DB()
+	dialect := db.Dialector.Name()
+
+	created := 0
+	for _, m := range configs.Models() {
+		stmt := db.Session(&gorm.Session{}).Model(m).Statement
+		if err := stmt.Parse(m); err != nil {
+			log.Fatalf("reindex: parse %T: %v", m, err)
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if db.Migrator().HasIndex(m, idx.Name) {
+				continue
+			}
+			if err := db.Migrator().CreateIndex(m, idx.Name); err != nil {
+				log.Fatalf("reindex: create %s.%s: %v", stmt.Schema.Table, idx.Name, err)
+			}
+			fmt.Printf("created index %s.%s\n", stmt.Schema.Table, idx.Name)
+			created++
+		}
+
+		table := stmt.Schema.Table
+		if *rebuild && dialect == "postgres" {
+			if err := db.Exec("REINDEX TABLE " + table).Error; err != nil {
+				log.Fatalf("reindex: %s: %v", table, err)
+			}
+		}
+		if dialect == "mysql" {
+			if err := db.Exec("ANALYZE TABLE " + table).Error; err != nil {
+				log.Fatalf("reindex: analyze %s: %v", table, err)
+			}
+		}
+	}
+
+	switch dialect {
+	case "sqlite":
+		if *rebuild {
+			if err := db.Exec("REINDEX").Error; err != nil {
+				log.Fatalf("reindex: %v", err)
+			}
+		}
+		if err := db.Exec("ANALYZE").Error; err != nil {
+			log.Fatalf("reindex: analyze: %v", err)
+		}
+	case "postgres":
+		if err := db.Exec("ANALYZE").Error; err != nil {
+			log.Fatalf("reindex: analyze: %v", err)
+		}
+	}
+	fmt.Printf("reindex done (%d missing indexes created)\n", created)
+}
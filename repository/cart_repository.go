@@ -40,32 +40,72 @@ func (r *CartRepository) GetOrCreateCart(userID, restaurantID uint) (*entity.Car
 
 // เพิ่มหรือรวม line: ตัวอย่าง merge แบบง่าย (เมนูเดียวกัน + note เดียวกัน)
 // **หมายเหตุ** ถ้าต้องรวมตาม selections ด้วย ให้เก็บ hash selections ไว้คอลัมน์เพิ่ม แล้วใช้ในเงื่อนไข Where
-func (r *CartRepository) UpsertItem(tx *gorm.DB, cartID uint, row *entity.CartItem) error {
+// รวมแล้วใช้ราคาล่าสุดจาก row (ราคาเมนูปัจจุบัน)
+func (r *CartRepository) UpsertItem(cartID uint, row *entity.CartItem) error {
 	var exist entity.CartItem
-	err := tx.Where("cart_id = ? AND menu_id = ? AND note = ?", cartID, row.MenuID, row.Note).
+	err := r.DB.Where("cart_id = ? AND menu_id = ? AND note = ?", cartID, row.MenuID, row.Note).
 		First(&exist).Error
 	if err == nil {
 		exist.Qty += row.Qty
+		exist.UnitPrice = row.UnitPrice
 		exist.Total = int64(exist.Qty) * exist.UnitPrice
-		return tx.Save(&exist).Error
+		return r.DB.Save(&exist).Error
 	}
-	if err != gorm.ErrRecordNotFound {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
 	row.CartID = cartID
-	if err := tx.Create(row).Error; err != nil {
+	if err := r.DB.Create(row).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *CartRepository) UpdateQty(tx *gorm.DB, userID, itemID uint, qty int) error {
+// จำนวนรายการใน cart
+func (r *CartRepository) CountItems(cartID uint) (int64, error) {
+	var n int64
+	err := r.DB.Model(&entity.CartItem{}).Where("cart_id = ?", cartID).Count(&n).Error
+	return n, err
+}
+
+// ตั้งร้านของ cart (0 = ว่าง พร้อมรับร้านใหม่)
+func (r *CartRepository) SetRestaurant(cartID, restaurantID uint) error {
+	return r.DB.Model(&entity.Cart{}).Where("id = ?", cartID).Update("restaurant_id", restaurantID).Error
+}
+
+// ดึง item โดย join carts เพื่อตรวจ ownership ของผู้ใช้
+func (r *CartRepository) FindItemForUser(userID, itemID uint) (*entity.CartItem, error) {
+	var item entity.CartItem
+	if err := r.DB.
+		Joins("JOIN carts ON carts.id = cart_items.cart_id").
+		Where("cart_items.id = ? AND carts.user_id = ?", itemID, userID).
+		Select("cart_items.*").
+		First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *CartRepository) UpdateItemPrice(itemID uint, unitPrice, total int64) error {
+	return r.DB.Model(&entity.CartItem{}).Where("id = ?", itemID).
+		Updates(map[string]any{"unit_price": unitPrice, "total": total}).Error
+}
+
+func (r *CartRepository) DeleteItem(itemID uint) error {
+	return r.DB.Delete(&entity.CartItem{}, itemID).Error
+}
+
+func (r *CartRepository) ClearItems(cartID uint) error {
+	return r.DB.Where("cart_id = ?", cartID).Delete(&entity.CartItem{}).Error
+}
+
+func (r *CartRepository) UpdateQty(userID, itemID uint, qty int) error {
 	if qty <= 0 {
-		return r.RemoveItem(tx, userID, itemID)
+		return r.RemoveItem(userID, itemID)
 	}
 	// ensure item เป็นของ cart ของ user
-	return tx.Exec(`
+	return r.DB.Exec(`
 		UPDATE cart_items
 		   SET qty = ?, total = unit_price * ?
 		 WHERE id = ?
@@ -73,33 +113,35 @@ func (r *CartRepository) UpdateQty(tx *gorm.DB, userID, itemID uint, qty int) er
 	`, qty, qty, itemID, userID).Error
 }
 
-func (r *CartRepository) RemoveItem(tx *gorm.DB, userID, itemID uint) error {
+func (r *CartRepository) RemoveItem(userID, itemID uint) error {
 	// ลบรายการ
-	if err := tx.
+	if err := r.DB.
 		Where("id = ? AND cart_id IN (SELECT id FROM carts WHERE user_id = ?)", itemID, userID).
 		Delete(&entity.CartItem{}).Error; err != nil {
 		return err
 	}
 	// ถ้าตะกร้าว่างแล้ว → รีเซ็ต restaurant_id = 0
-	return tx.Exec(`
+	return r.DB.Exec(`
 		UPDATE carts SET restaurant_id = 0
 		 WHERE user_id = ?
 		   AND NOT EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = carts.id)
 	`, userID).Error
 }
 
-func (r *CartRepository) ClearCart(tx *gorm.DB, userID uint) error {
+func (r *CartRepository) ClearCart(userID uint) error {
 	var c entity.Cart
-	if err := tx.Where("user_id = ?", userID).First(&c).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) { return nil }
+	if err := r.DB.Where("user_id = ?", userID).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
 		return err
 	}
-	if err := tx.Where("cart_id = ?", c.ID).Delete(&entity.CartItem{}).Error; err != nil {
+	if err := r.DB.Where("cart_id = ?", c.ID).Delete(&entity.CartItem{}).Error; err != nil {
 		return err
 	}
 	// รีเซ็ตร้านของตะกร้าให้เป็น 0 เพื่อพร้อมรับร้านใหม่
-  if err := tx.Model(&entity.Cart{}).Where("id = ?", c.ID).Update("restaurant_id", 0).Error; err != nil {
-    return err
-  }
-  return nil
+	if err := r.DB.Model(&entity.Cart{}).Where("id = ?", c.ID).Update("restaurant_id", 0).Error; err != nil {
+		return err
+	}
+	return nil
 }
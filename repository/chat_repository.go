@@ -2,6 +2,8 @@ package repository
 
 import (
 	"backend/entity"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -65,10 +67,146 @@ func (r *ChatRepository) CreateMessage(msg *entity.Message) error {
 
 // ดึง order พร้อม RiderWork เพื่อใช้ตรวจสิทธิ์
 func (r *ChatRepository) FindOrderWithRider(orderID uint) (*entity.Order, error) {
-    var order entity.Order
-    err := r.db.
-        Preload("RiderWork.Rider.User"). // ✅ preload ให้ Rider มี User
-        First(&order, orderID).Error
-    return &order, err
+	var order entity.Order
+	err := r.db.
+		Preload("RiderWork.Rider.User"). // ✅ preload ให้ Rider มี User
+		First(&order, orderID).Error
+	return &order, err
+}
+
+// ---------------------- Read receipts ----------------------
+
+// FindReads = read state ของทุกคนในห้อง
+func (r *ChatRepository) FindReads(roomID uint) ([]entity.ChatRead, error) {
+	var reads []entity.ChatRead
+	err := r.db.Where("room_id = ?", roomID).Order("user_id").Find(&reads).Error
+	return reads, err
+}
+
+// LastReadIDs = last read message id ของ user ต่อห้อง (ห้องที่ยังไม่เคยอ่านจะไม่อยู่ใน map)
+func (r *ChatRepository) LastReadIDs(userID uint, roomIDs []uint) (map[uint]uint, error) {
+	out := map[uint]uint{}
+	if len(roomIDs) == 0 {
+		return out, nil
+	}
+	var reads []entity.ChatRead
+	if err := r.db.Where("user_id = ? AND room_id IN ?", userID, roomIDs).Find(&reads).Error; err != nil {
+		return nil, err
+	}
+	for _, read := range reads {
+		out[read.RoomID] = read.LastReadMessageID
+	}
+	return out, nil
+}
+
+// AdvanceRead เลื่อน last read ของ user ไปที่ messageID (ไม่ถอยหลัง) คืน state ล่าสุด
+// moved = receipt ขยับจริงในครั้งนี้ (อ่านซ้ำ / id เก่ากว่า / อีก device เลื่อนไปก่อนแล้ว = false)
+func (r *ChatRepository) AdvanceRead(roomID, userID, messageID uint, at time.Time) (read *entity.ChatRead, moved bool, err error) {
+	read = &entity.ChatRead{}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		moved = false
+		err := tx.Where(entity.ChatRead{RoomID: roomID, UserID: userID}).First(read).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			*read = entity.ChatRead{RoomID: roomID, UserID: userID, LastReadMessageID: messageID, ReadAt: at}
+			if err := tx.Create(read).Error; err != nil {
+				return err
+			}
+			moved = messageID > 0
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if read.LastReadMessageID >= messageID {
+			return nil
+		}
+		// WHERE กันอีก request ที่เลื่อนไปไกลกว่าแล้ว (อ่านพร้อมกันหลาย device)
+		res := tx.Model(&entity.ChatRead{}).
+			Where("id = ? AND last_read_message_id < ?", read.ID, messageID).
+			Updates(map[string]any{"last_read_message_id": messageID, "read_at": at})
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected > 0
+		return tx.First(read, read.ID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return read, moved, nil
+}
+
+// LatestMessageID = id ข้อความล่าสุดในห้อง (0 = ยังไม่มีข้อความ)
+func (r *ChatRepository) LatestMessageID(roomID uint) (uint, error) {
+	var id uint
+	err := r.db.Model(&entity.Message{}).Where("room_id = ?", roomID).
+		Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
 }
 
+// FindMessageInRoom ดึงข้อความที่ต้องอยู่ในห้องนี้เท่านั้น
+func (r *ChatRepository) FindMessageInRoom(roomID, messageID uint) (*entity.Message, error) {
+	var msg entity.Message
+	if err := r.db.Where("room_id = ?", roomID).First(&msg, messageID).Error; err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// UnreadCounts = จำนวนข้อความจากคนอื่นที่ user ยังไม่อ่าน ต่อห้อง (ห้องที่ไม่มีจะไม่อยู่ใน map)
+func (r *ChatRepository) UnreadCounts(userID uint, roomIDs []uint) (map[uint]int64, error) {
+	out := map[uint]int64{}
+	if len(roomIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		RoomID uint
+		Unread int64
+	}
+	err := r.db.Model(&entity.Message{}).
+		Select("messages.room_id, COUNT(*) AS unread").
+		Joins("LEFT JOIN chat_reads ON chat_reads.room_id = messages.room_id AND chat_reads.user_id = ?", userID).
+		Where("messages.room_id IN ? AND messages.user_sender_id <> ?", roomIDs, userID).
+		Where("messages.id > COALESCE(chat_reads.last_read_message_id, 0)").
+		Group("messages.room_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		out[row.RoomID] = row.Unread
+	}
+	return out, err
+}
+
+// LastMessages = ข้อความล่าสุดของแต่ละห้อง (key = roomID)
+func (r *ChatRepository) LastMessages(roomIDs []uint) (map[uint]entity.Message, error) {
+	out := map[uint]entity.Message{}
+	if len(roomIDs) == 0 {
+		return out, nil
+	}
+	latest := r.db.Model(&entity.Message{}).Select("MAX(id)").Where("room_id IN ?", roomIDs).Group("room_id")
+	var msgs []entity.Message
+	if err := r.db.Where("id IN (?)", latest).Find(&msgs).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range msgs {
+		out[m.RoomID] = m
+	}
+	return out, nil
+}
+
+// FindRoomsForUser = ห้องแชทของ order ที่ user เป็นลูกค้าหรือไรเดอร์
+// excludeStatusIDs = สถานะ order ที่ไม่เอา (เช่น จบแล้ว)
+func (r *ChatRepository) FindRoomsForUser(userID uint, excludeStatusIDs []uint) ([]entity.ChatRoom, error) {
+	asRider := r.db.Model(&entity.RiderWork{}).
+		Select("rider_works.order_id").
+		Joins("JOIN riders ON riders.id = rider_works.rider_id").
+		Where("riders.user_id = ?", userID)
+	orders := r.db.Model(&entity.Order{}).Select("id").
+		Where("user_id = ? OR id IN (?)", userID, asRider)
+	if len(excludeStatusIDs) > 0 {
+		orders = orders.Where("order_status_id NOT IN ?", excludeStatusIDs)
+	}
+
+	var rooms []entity.ChatRoom
+	err := r.db.Where("order_id IN (?)", orders).Order("id DESC").Find(&rooms).Error
+	return rooms, err
+}
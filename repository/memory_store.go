@@ -0,0 +1,606 @@
+package repository
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---------------- In-memory implementation ----------------
+// Store ใน memory สำหรับ unit test ของ service (ไม่ต้องมี DB)
+// พฤติกรรมเลียนแบบ GormStore: ไม่เจอ = gorm.ErrRecordNotFound, เมนูลบแบบ soft delete,
+// preload ชื่อสถานะจาก lookups (ต้อง Load/LoadDefaults ก่อน) และ Transaction rollback เมื่อ fn คืน error
+
+type MemoryStore struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // Transaction ทำทีละอัน (เหมือน SQLite)
+	data memoryData
+}
+
+type memoryData struct {
+	nextID      uint
+	restaurants map[uint]entity.Restaurant
+	menus       map[uint]entity.Menu
+	carts       map[uint]entity.Cart
+	cartItems   map[uint]entity.CartItem
+	orders      map[uint]entity.Order
+	orderItems  map[uint]entity.OrderItem
+	payments    map[uint]entity.Payment
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: memoryData{
+		restaurants: map[uint]entity.Restaurant{},
+		menus:       map[uint]entity.Menu{},
+		carts:       map[uint]entity.Cart{},
+		cartItems:   map[uint]entity.CartItem{},
+		orders:      map[uint]entity.Order{},
+		orderItems:  map[uint]entity.OrderItem{},
+		payments:    map[uint]entity.Payment{},
+	}}
+}
+
+func (d memoryData) clone() memoryData {
+	d.restaurants = maps.Clone(d.restaurants)
+	d.menus = maps.Clone(d.menus)
+	d.carts = maps.Clone(d.carts)
+	d.cartItems = maps.Clone(d.cartItems)
+	d.orders = maps.Clone(d.orders)
+	d.orderItems = maps.Clone(d.orderItems)
+	d.payments = maps.Clone(d.payments)
+	return d
+}
+
+// id ใหม่ (ใช้ร่วมทุกตาราง) + ตั้ง CreatedAt/UpdatedAt แบบที่ gorm ทำ
+func (s *MemoryStore) newModel(m *gorm.Model) {
+	s.data.nextID++
+	now := time.Now()
+	m.ID, m.CreatedAt, m.UpdatedAt = s.data.nextID, now, now
+}
+
+// AddRestaurant ใส่ร้านสำหรับ test (interface ไม่มีการสร้างร้าน)
+func (s *MemoryStore) AddRestaurant(r *entity.Restaurant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newModel(&r.Model)
+	s.data.restaurants[r.ID] = *r
+}
+
+func (s *MemoryStore) Orders() OrderStore           { return memoryOrders{s} }
+func (s *MemoryStore) Menus() MenuStore             { return memoryMenus{s} }
+func (s *MemoryStore) Carts() CartStore             { return memoryCarts{s} }
+func (s *MemoryStore) Payments() PaymentStore       { return memoryPayments{s} }
+func (s *MemoryStore) Restaurants() RestaurantStore { return memoryRestaurants{s} }
+
+// Transaction เก็บ snapshot ไว้ แล้วคืนค่าเดิมทั้งหมดถ้า fn คืน error
+func (s *MemoryStore) Transaction(fn func(tx Store) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.data.clone()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		snapshot.nextID = s.data.nextID // id ที่ใช้ไปแล้วไม่ย้อน (เหมือน auto increment)
+		s.data = snapshot
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ---------------- Orders ----------------
+
+type memoryOrders struct{ s *MemoryStore }
+
+func (r memoryOrders) Create(o *entity.Order) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.newModel(&o.Model)
+	r.s.data.orders[o.ID] = *o
+	return nil
+}
+
+func (r memoryOrders) CreateItem(oi *entity.OrderItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.newModel(&oi.Model)
+	r.s.data.orderItems[oi.ID] = *oi
+	return nil
+}
+
+func (r memoryOrders) GetOrder(orderID uint) (*entity.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	o, ok := r.s.data.orders[orderID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &o, nil
+}
+
+func (r memoryOrders) GetOrderForUser(userID, orderID uint) (*entity.Order, error) {
+	o, err := r.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return o, nil
+}
+
+func (r memoryOrders) ListOrdersForUser(userID uint, limit int) ([]OrderSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var out []OrderSummary
+	for _, id := range sortedKeys(r.s.data.orders) {
+		o := r.s.data.orders[id]
+		if o.UserID != userID {
+			continue
+		}
+		out = append(out, OrderSummary{
+			ID: o.ID, RestaurantID: o.RestaurantID, Total: o.Total,
+			OrderStatusID: o.OrderStatusID, ScheduledFor: o.ScheduledFor, CreatedAt: o.CreatedAt,
+		})
+	}
+	slices.Reverse(out) // id DESC
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r memoryOrders) GetOrderItems(orderID uint) ([]entity.OrderItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var items []entity.OrderItem
+	for _, id := range sortedKeys(r.s.data.orderItems) {
+		if it := r.s.data.orderItems[id]; it.OrderID == orderID {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+func (r memoryOrders) UpdateStatusFromTo(orderID, fromID, toID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	o, ok := r.s.data.orders[orderID]
+	if !ok || o.OrderStatusID != fromID {
+		return false, nil
+	}
+	o.OrderStatusID = toID
+	r.s.data.orders[orderID] = o
+	return true, nil
+}
+
+// ---------------- Menus ----------------
+
+type memoryMenus struct{ s *MemoryStore }
+
+// withStatus = Preload("MenuStatus") (ชื่อจาก lookups)
+func withStatus(m entity.Menu) entity.Menu {
+	if code, ok := lookups.CodeOf[lookups.MenuStatus](m.MenuStatusID); ok {
+		m.MenuStatus = entity.MenuStatus{Model: gorm.Model{ID: m.MenuStatusID}, StatusName: string(code)}
+	}
+	return m
+}
+
+func (r memoryMenus) FindByRestaurant(restID uint) ([]entity.Menu, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var menus []entity.Menu
+	for _, id := range sortedKeys(r.s.data.menus) {
+		if m := r.s.data.menus[id]; m.RestaurantID == restID && !m.DeletedAt.Valid {
+			menus = append(menus, withStatus(m))
+		}
+	}
+	return menus, nil
+}
+
+func (r memoryMenus) FindByID(id uint) (*entity.Menu, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	m, ok := r.s.data.menus[id]
+	if !ok || m.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	m = withStatus(m)
+	return &m, nil
+}
+
+func (r memoryMenus) FindByIDsWithDeleted(ids []uint) ([]entity.Menu, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var menus []entity.Menu
+	for _, id := range ids {
+		if m, ok := r.s.data.menus[id]; ok {
+			menus = append(menus, m)
+		}
+	}
+	return menus, nil
+}
+
+func (r memoryMenus) Create(menu *entity.Menu) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.newModel(&menu.Model)
+	r.s.data.menus[menu.ID] = *menu
+	return nil
+}
+
+func (r memoryMenus) Update(menu *entity.Menu) error {
+	return r.UpdateFields(menu.ID, map[string]any{
+		"name":           menu.Name,
+		"detail":         menu.Detail,
+		"price":          menu.Price,
+		"image":          menu.Image,
+		"menu_type_id":   menu.MenuTypeID,
+		"menu_status_id": menu.MenuStatusID,
+	})
+}
+
+// UpdateFields รองรับเฉพาะคอลัมน์ที่ service ใช้ (คอลัมน์อื่น = error ให้ test เห็นทันที)
+func (r memoryMenus) UpdateFields(id uint, fields map[string]any) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	m, ok := r.s.data.menus[id]
+	if !ok || m.DeletedAt.Valid {
+		return nil // UPDATE ที่ไม่เจอ row ไม่ error
+	}
+	for col, v := range fields {
+		switch col {
+		case "name":
+			m.Name = v.(string)
+		case "detail":
+			m.Detail = v.(string)
+		case "price":
+			m.Price = v.(int64)
+		case "image":
+			m.Image = v.(string)
+		case "menu_type_id":
+			m.MenuTypeID = v.(uint)
+		case "menu_status_id":
+			m.MenuStatusID = v.(uint)
+		case "auto_out_of_stock":
+			m.AutoOutOfStock = v.(bool)
+		case "stock_remaining":
+			m.StockRemaining = v.(int)
+		case "daily_quota":
+			if q, ok := v.(int); ok {
+				m.DailyQuota = &q
+			} else {
+				m.DailyQuota = nil
+			}
+		case "stock_reset_at":
+			t := v.(time.Time)
+			m.StockResetAt = &t
+		default:
+			return fmt.Errorf("memory store: unsupported menu column %q", col)
+		}
+	}
+	m.UpdatedAt = time.Now()
+	r.s.data.menus[id] = m
+	return nil
+}
+
+func (r memoryMenus) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if m, ok := r.s.data.menus[id]; ok && !m.DeletedAt.Valid {
+		m.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		r.s.data.menus[id] = m
+	}
+	return nil
+}
+
+func (r memoryMenus) UpdateStatus(id uint, statusID uint) error {
+	return r.UpdateFields(id, map[string]any{"menu_status_id": statusID})
+}
+
+func (r memoryMenus) DecrementStock(menuID uint, qty int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	m, ok := r.s.data.menus[menuID]
+	if !ok || m.DeletedAt.Valid || m.DailyQuota == nil || m.StockRemaining < qty {
+		return false, nil
+	}
+	m.StockRemaining -= qty
+	r.s.data.menus[menuID] = m
+	return true, nil
+}
+
+func (r memoryMenus) IncrementStock(menuID uint, qty int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	m, ok := r.s.data.menus[menuID]
+	if !ok || m.DeletedAt.Valid || m.DailyQuota == nil {
+		return nil
+	}
+	m.StockRemaining = min(m.StockRemaining+qty, *m.DailyQuota)
+	r.s.data.menus[menuID] = m
+	return nil
+}
+
+// ---------------- Carts ----------------
+
+type memoryCarts struct{ s *MemoryStore }
+
+// cartOf คืน cart ของ user (ต้องถือ lock อยู่แล้ว)
+func (r memoryCarts) cartOf(userID uint) (entity.Cart, bool) {
+	for _, c := range r.s.data.carts {
+		if c.UserID == userID {
+			return c, true
+		}
+	}
+	return entity.Cart{}, false
+}
+
+func (r memoryCarts) GetCartWithItems(userID uint) (*entity.Cart, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.cartOf(userID)
+	if !ok {
+		return &entity.Cart{UserID: userID}, nil
+	}
+	for _, id := range sortedKeys(r.s.data.cartItems) {
+		it := r.s.data.cartItems[id]
+		if it.CartID != c.ID {
+			continue
+		}
+		if m, ok := r.s.data.menus[it.MenuID]; ok && !m.DeletedAt.Valid {
+			m := withStatus(m)
+			it.Menu = &m
+		}
+		c.Items = append(c.Items, it)
+	}
+	return &c, nil
+}
+
+func (r memoryCarts) GetOrCreateCart(userID, restaurantID uint) (*entity.Cart, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c, ok := r.cartOf(userID); ok {
+		return &c, nil
+	}
+	c := entity.Cart{UserID: userID, RestaurantID: restaurantID}
+	r.s.newModel(&c.Model)
+	r.s.data.carts[c.ID] = c
+	return &c, nil
+}
+
+func (r memoryCarts) CountItems(cartID uint) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for _, it := range r.s.data.cartItems {
+		if it.CartID == cartID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r memoryCarts) SetRestaurant(cartID, restaurantID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c, ok := r.s.data.carts[cartID]; ok {
+		c.RestaurantID = restaurantID
+		r.s.data.carts[cartID] = c
+	}
+	return nil
+}
+
+func (r memoryCarts) FindItemForUser(userID, itemID uint) (*entity.CartItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	it, ok := r.s.data.cartItems[itemID]
+	if !ok || r.s.data.carts[it.CartID].UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &it, nil
+}
+
+// UpsertItem รวม line ที่เมนู + note เดียวกัน (ใช้ราคาล่าสุดจาก row)
+func (r memoryCarts) UpsertItem(cartID uint, row *entity.CartItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range sortedKeys(r.s.data.cartItems) {
+		exist := r.s.data.cartItems[id]
+		if exist.CartID == cartID && exist.MenuID == row.MenuID && exist.Note == row.Note {
+			exist.Qty += row.Qty
+			exist.UnitPrice = row.UnitPrice
+			exist.Total = int64(exist.Qty) * exist.UnitPrice
+			r.s.data.cartItems[id] = exist
+			return nil
+		}
+	}
+	row.CartID = cartID
+	r.s.newModel(&row.Model)
+	r.s.data.cartItems[row.ID] = *row
+	return nil
+}
+
+func (r memoryCarts) UpdateItemPrice(itemID uint, unitPrice, total int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if it, ok := r.s.data.cartItems[itemID]; ok {
+		it.UnitPrice, it.Total = unitPrice, total
+		r.s.data.cartItems[itemID] = it
+	}
+	return nil
+}
+
+func (r memoryCarts) UpdateQty(userID, itemID uint, qty int) error {
+	if qty <= 0 {
+		return r.RemoveItem(userID, itemID)
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if it, ok := r.s.data.cartItems[itemID]; ok && r.s.data.carts[it.CartID].UserID == userID {
+		it.Qty, it.Total = qty, it.UnitPrice*int64(qty)
+		r.s.data.cartItems[itemID] = it
+	}
+	return nil
+}
+
+func (r memoryCarts) DeleteItem(itemID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.data.cartItems, itemID)
+	return nil
+}
+
+func (r memoryCarts) RemoveItem(userID, itemID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.cartOf(userID)
+	if !ok {
+		return nil
+	}
+	if it, ok := r.s.data.cartItems[itemID]; ok && it.CartID == c.ID {
+		delete(r.s.data.cartItems, itemID)
+	}
+	// ตะกร้าว่างแล้ว → รีเซ็ต restaurant_id = 0
+	for _, it := range r.s.data.cartItems {
+		if it.CartID == c.ID {
+			return nil
+		}
+	}
+	c.RestaurantID = 0
+	r.s.data.carts[c.ID] = c
+	return nil
+}
+
+func (r memoryCarts) ClearItems(cartID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	maps.DeleteFunc(r.s.data.cartItems, func(_ uint, it entity.CartItem) bool { return it.CartID == cartID })
+	return nil
+}
+
+func (r memoryCarts) ClearCart(userID uint) error {
+	r.s.mu.Lock()
+	c, ok := r.cartOf(userID)
+	r.s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := r.ClearItems(c.ID); err != nil {
+		return err
+	}
+	return r.SetRestaurant(c.ID, 0)
+}
+
+// ---------------- Payments ----------------
+
+type memoryPayments struct{ s *MemoryStore }
+
+func (r memoryPayments) Create(p *entity.Payment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.newModel(&p.Model)
+	r.s.data.payments[p.ID] = *p
+	return nil
+}
+
+func (r memoryPayments) Save(p *entity.Payment) error {
+	if p.ID == 0 {
+		return r.Create(p)
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	p.UpdatedAt = time.Now()
+	r.s.data.payments[p.ID] = *p
+	return nil
+}
+
+// paymentsOf = payment ของ order เรียงตาม id (ต้องถือ lock อยู่แล้ว)
+func (r memoryPayments) paymentsOf(orderID uint) []entity.Payment {
+	var out []entity.Payment
+	for _, id := range sortedKeys(r.s.data.payments) {
+		if p := r.s.data.payments[id]; p.OrderID == orderID {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (r memoryPayments) GetByOrderID(orderID uint) (*entity.Payment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ps := r.paymentsOf(orderID)
+	if len(ps) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &ps[0], nil
+}
+
+func (r memoryPayments) GetLatestByOrderID(orderID uint) (*entity.Payment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ps := r.paymentsOf(orderID)
+	if len(ps) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	p := ps[len(ps)-1]
+	if code, ok := lookups.CodeOf[lookups.PaymentMethod](p.PaymentMethodID); ok {
+		p.PaymentMethod = entity.PaymentMethod{Model: gorm.Model{ID: p.PaymentMethodID}, MethodName: string(code)}
+	}
+	if code, ok := lookups.CodeOf[lookups.PaymentStatus](p.PaymentStatusID); ok {
+		p.PaymentStatus = entity.PaymentStatus{Model: gorm.Model{ID: p.PaymentStatusID}, StatusName: string(code)}
+	}
+	return &p, nil
+}
+
+func (r memoryPayments) UpdateStatus(paymentID, statusID uint, paidAt *time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if p, ok := r.s.data.payments[paymentID]; ok {
+		p.PaymentStatusID = statusID
+		if paidAt != nil {
+			p.PaidAt = paidAt
+		}
+		r.s.data.payments[paymentID] = p
+	}
+	return nil
+}
+
+// ---------------- Restaurants ----------------
+
+type memoryRestaurants struct{ s *MemoryStore }
+
+func (r memoryRestaurants) FindByID(id uint) (*entity.Restaurant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rest, ok := r.s.data.restaurants[id]
+	if !ok || rest.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if code, ok := lookups.CodeOf[lookups.RestaurantStatus](rest.RestaurantStatusID); ok {
+		rest.RestaurantStatus = entity.RestaurantStatus{Model: gorm.Model{ID: rest.RestaurantStatusID}, StatusName: string(code)}
+	}
+	return &rest, nil
+}
+
+func (r memoryRestaurants) IsOwner(restID, userID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rest, ok := r.s.data.restaurants[restID]
+	return ok && !rest.DeletedAt.Valid && rest.UserID == userID, nil
+}
+
+func sortedKeys[V any](m map[uint]V) []uint {
+	return slices.Sorted(maps.Keys(m))
+}
+
+var _ Store = (*MemoryStore)(nil)
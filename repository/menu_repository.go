@@ -3,6 +3,7 @@ package repository
 
 import (
 	"backend/entity"
+
 	"gorm.io/gorm"
 )
 
@@ -46,17 +47,17 @@ func (r *MenuRepository) Create(menu *entity.Menu) error {
 // อัปเดตเมนู
 func (r *MenuRepository) Update(menu *entity.Menu) error {
 	fields := map[string]interface{}{
-			"name":   menu.Name,
-			"detail": menu.Detail,
-			"price":  menu.Price,
-			"image":  menu.Image,
-			"menu_type_id":   menu.MenuTypeID,
-			"menu_status_id": menu.MenuStatusID,
+		"name":           menu.Name,
+		"detail":         menu.Detail,
+		"price":          menu.Price,
+		"image":          menu.Image,
+		"menu_type_id":   menu.MenuTypeID,
+		"menu_status_id": menu.MenuStatusID,
 	}
 
 	return r.DB.Model(&entity.Menu{}).
-			Where("id = ?", menu.ID).
-			Updates(fields).Error
+		Where("id = ?", menu.ID).
+		Updates(fields).Error
 }
 
 // ลบเมนู
@@ -65,7 +66,42 @@ func (r *MenuRepository) Delete(id uint) error {
 }
 
 func (r *MenuRepository) UpdateStatus(id uint, statusID uint) error {
-    return r.DB.Model(&entity.Menu{}).
-        Where("id = ?", id).
-        Update("menu_status_id", statusID).Error
-}
\ No newline at end of file
+	return r.DB.Model(&entity.Menu{}).
+		Where("id = ?", id).
+		Update("menu_status_id", statusID).Error
+}
+
+// เมนูตาม id รวมที่ถูกลบแล้ว (ดูชื่อ/สถานะได้)
+func (r *MenuRepository) FindByIDsWithDeleted(ids []uint) ([]entity.Menu, error) {
+	var menus []entity.Menu
+	if len(ids) == 0 {
+		return menus, nil
+	}
+	err := r.DB.Unscoped().
+		Select("id, name, price, restaurant_id, menu_status_id, daily_quota, stock_remaining, auto_out_of_stock, deleted_at").
+		Where("id IN ?", ids).
+		Find(&menus).Error
+	return menus, err
+}
+
+// อัปเดตบางฟิลด์ (map → ค่า false/0 ไม่หาย)
+func (r *MenuRepository) UpdateFields(id uint, fields map[string]any) error {
+	return r.DB.Model(&entity.Menu{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ตัดสต็อกแบบ atomic: UPDATE ... WHERE stock_remaining >= qty กันขายเกินเมื่อสั่งพร้อมกัน
+func (r *MenuRepository) DecrementStock(menuID uint, qty int) (bool, error) {
+	res := r.DB.Model(&entity.Menu{}).
+		Where("id = ? AND daily_quota IS NOT NULL AND stock_remaining >= ?", menuID, qty).
+		UpdateColumn("stock_remaining", gorm.Expr("stock_remaining - ?", qty))
+	return res.RowsAffected == 1, res.Error
+}
+
+// คืนสต็อก (ไม่เกินโควต้า เผื่อมีการ reset ไปแล้ว)
+func (r *MenuRepository) IncrementStock(menuID uint, qty int) error {
+	return r.DB.Model(&entity.Menu{}).
+		Where("id = ? AND daily_quota IS NOT NULL", menuID).
+		UpdateColumn("stock_remaining", gorm.Expr(
+			"CASE WHEN stock_remaining + ? > daily_quota THEN daily_quota ELSE stock_remaining + ? END",
+			qty, qty)).Error
+}
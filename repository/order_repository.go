@@ -18,9 +18,9 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository {
 
 // ---------------- Orders (CRUD หลัก) ----------------
 
-// POST /orders → สร้าง order
-func (r *OrderRepository) CreateOrder(tx *gorm.DB, o *entity.Order) error {
-	return tx.Create(o).Error
+// POST /orders → สร้าง order (ใช้ใน transaction ผ่าน Store.Transaction)
+func (r *OrderRepository) Create(o *entity.Order) error {
+	return r.DB.Create(o).Error
 }
 
 // GET /orders/:id (ใช้ทั่วไป เช่น admin/owner)
@@ -35,22 +35,24 @@ func (r *OrderRepository) GetOrder(orderID uint) (*entity.Order, error) {
 // GET /orders (ลูกค้า) → รายการ order ของ user
 // ดึงข้อมูลตามนี้ แล้วส่งไป
 type OrderSummary struct {
-	ID            uint      `json:"id"`
-	RestaurantID  uint      `json:"restaurantId"`
-	Total         int64     `json:"total"`
-	OrderStatusID uint      `json:"orderStatusId"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            uint       `json:"id"`
+	RestaurantID  uint       `json:"restaurantId"`
+	Total         int64      `json:"total"`
+	OrderStatusID uint       `json:"orderStatusId"`
+	ScheduledFor  *time.Time `json:"scheduledFor,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
+
 func (r *OrderRepository) ListOrdersForUser(userID uint, limit int) ([]OrderSummary, error) {
-	if limit <= 0 {
-		limit = 50
-	}
 	var out []OrderSummary
-	err := r.DB.Model(&entity.Order{}).
-		Select("id, restaurant_id, total, order_status_id, created_at").
+	q := r.DB.Model(&entity.Order{}).
+		Select("id, restaurant_id, total, order_status_id, scheduled_for, created_at").
 		Where("user_id = ?", userID).
-		Order("id DESC").Limit(limit).
-		Scan(&out).Error
+		Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Scan(&out).Error
 	return out, err
 }
 
@@ -73,6 +75,7 @@ type OwnerOrderSummary struct {
 	OrderStatusID uint      `json:"orderStatusId"`
 	CreatedAt     time.Time `json:"createdAt"`
 }
+
 func (r *OrderRepository) ListOrdersForRestaurant(restID uint, statusID *uint, page, limit int) ([]OwnerOrderSummary, int64, error) {
 	if page <= 0 {
 		page = 1
@@ -138,14 +141,14 @@ func (r *OrderRepository) GetOrderForRestaurant(restID, orderID uint) (*entity.O
 }
 
 // PUT /orders/:id/status → อัปเดตสถานะ (มี guard)
-func (r *OrderRepository) UpdateStatusGuard(tx *gorm.DB, orderID, fromID, toID uint) (int64, error) {
-	res := tx.Model(&entity.Order{}).
+func (r *OrderRepository) UpdateStatusGuard(orderID, fromID, toID uint) (int64, error) {
+	res := r.DB.Model(&entity.Order{}).
 		Where("id = ? AND order_status_id = ?", orderID, fromID).
 		Update("order_status_id", toID)
 	return res.RowsAffected, res.Error
 }
-func (r *OrderRepository) UpdateStatusFromTo(tx *gorm.DB, orderID, fromID, toID uint) (bool, error) {
-	res := tx.Model(&entity.Order{}).
+func (r *OrderRepository) UpdateStatusFromTo(orderID, fromID, toID uint) (bool, error) {
+	res := r.DB.Model(&entity.Order{}).
 		Where("id = ? AND order_status_id = ?", orderID, fromID).
 		Update("order_status_id", toID)
 	if res.Error != nil {
@@ -155,22 +158,19 @@ func (r *OrderRepository) UpdateStatusFromTo(tx *gorm.DB, orderID, fromID, toID
 }
 
 // ---------------- Order Items ----------------
-func (r *OrderRepository) CreateOrderItem(tx *gorm.DB, oi *entity.OrderItem) error {
-	return tx.Create(oi).Error
+func (r *OrderRepository) CreateItem(oi *entity.OrderItem) error {
+	return r.DB.Create(oi).Error
 }
 func (r *OrderRepository) GetOrderItems(orderID uint) ([]entity.OrderItem, error) {
 	var items []entity.OrderItem
 	err := r.DB.Model(&entity.OrderItem{}).
-		Select("id, qty, unit_price, total, menu_id, order_id").
+		Select("id, qty, unit_price, total, menu_id, order_id, note").
 		Where("order_id = ?", orderID).
 		Find(&items).Error
 	return items, err
 }
 
 // ---------------- Payments ----------------
-func (r *OrderRepository) CreatePayment(tx *gorm.DB, p *entity.Payment) error {
-	return tx.Create(p).Error
-}
 func (r *OrderRepository) GetPaymentMethodIDFromKey(key string) (uint, error) {
 	if key == "" {
 		return 0, nil
@@ -225,11 +225,3 @@ func (r *OrderRepository) ValidateMenusBelongToRestaurant(menuIDs []uint, restID
 	}
 	return cnt == int64(len(menuIDs)), nil
 }
-
-// หาค่า status id จากชื่อ
-func (r *OrderRepository) GetStatusIDByName(name string) (uint, error) {
-	var row struct{ ID uint }
-	err := r.DB.Model(&entity.OrderStatus{}).
-		Select("id").Where("status_name = ?", name).First(&row).Error
-	return row.ID, err
-}
@@ -25,27 +25,31 @@ func (r *PaymentRepository) GetByOrderID(orderID uint) (*entity.Payment, error)
 	return &p, nil
 }
 
+// payment ล่าสุดของ order
+func (r *PaymentRepository) GetLatestByOrderID(orderID uint) (*entity.Payment, error) {
+	var p entity.Payment
+	if err := r.DB.Preload("PaymentMethod").Preload("PaymentStatus").
+		Where("order_id = ?", orderID).Order("id DESC").First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PaymentRepository) Create(p *entity.Payment) error {
+	return r.DB.Create(p).Error
+}
+
+func (r *PaymentRepository) Save(p *entity.Payment) error {
+	return r.DB.Save(p).Error
+}
+
 // อัปเดตสถานะ Payment (+ optional PaidAt)
-func (r *PaymentRepository) UpdateStatus(tx *gorm.DB, paymentID, statusID uint, paidAt *time.Time) error {
+func (r *PaymentRepository) UpdateStatus(paymentID, statusID uint, paidAt *time.Time) error {
 	updates := map[string]any{
 		"payment_status_id": statusID,
 	}
 	if paidAt != nil {
 		updates["paid_at"] = paidAt
 	}
-	return tx.Model(&entity.Payment{}).Where("id = ?", paymentID).Updates(updates).Error
-}
-
-// คืนค่า PaymentMethodID ตามชื่อ
-func (r *PaymentRepository) GetMethodIDByName(name string) (uint, error) {
-    var id uint
-    err := r.DB.Model(&entity.PaymentMethod{}).Select("id").Where("method_name = ?", name).Scan(&id).Error
-    return id, err
-}
-
-// คืนค่า PaymentStatusID ตามชื่อ
-func (r *PaymentRepository) GetStatusIDByName(name string) (uint, error) {
-    var id uint
-    err := r.DB.Model(&entity.PaymentStatus{}).Select("id").Where("status_name = ?", name).Scan(&id).Error
-    return id, err
+	return r.DB.Model(&entity.Payment{}).Where("id = ?", paymentID).Updates(updates).Error
 }
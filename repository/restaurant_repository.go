@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"backend/entity"
+
+	"gorm.io/gorm"
+)
+
+type RestaurantRepository struct {
+	DB *gorm.DB
+}
+
+func NewRestaurantRepository(db *gorm.DB) *RestaurantRepository {
+	return &RestaurantRepository{DB: db}
+}
+
+// ดึงร้าน (พร้อมสถานะร้าน)
+func (r *RestaurantRepository) FindByID(id uint) (*entity.Restaurant, error) {
+	var rest entity.Restaurant
+	if err := r.DB.Preload("RestaurantStatus").First(&rest, id).Error; err != nil {
+		return nil, err
+	}
+	return &rest, nil
+}
+
+// เช็คว่า user เป็นเจ้าของร้าน
+func (r *RestaurantRepository) IsOwner(restID, userID uint) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&entity.Restaurant{}).
+		Where("id = ? AND user_id = ?", restID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
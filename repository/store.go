@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"backend/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---------------- Interfaces ----------------
+// service ใช้งานผ่าน interface เหล่านี้ → ทดสอบ business rule ด้วย fake ใน memory ได้ (MemoryStore)
+
+type OrderStore interface {
+	Create(o *entity.Order) error
+	CreateItem(oi *entity.OrderItem) error
+	GetOrder(orderID uint) (*entity.Order, error)
+	GetOrderForUser(userID, orderID uint) (*entity.Order, error)
+	ListOrdersForUser(userID uint, limit int) ([]OrderSummary, error)
+	GetOrderItems(orderID uint) ([]entity.OrderItem, error)
+	UpdateStatusFromTo(orderID, fromID, toID uint) (bool, error)
+}
+
+type MenuStore interface {
+	FindByRestaurant(restID uint) ([]entity.Menu, error)
+	FindByID(id uint) (*entity.Menu, error)
+	// รวมเมนูที่ถูก soft delete ด้วย (ใช้ตรวจ cart / reorder)
+	FindByIDsWithDeleted(ids []uint) ([]entity.Menu, error)
+	Create(menu *entity.Menu) error
+	Update(menu *entity.Menu) error
+	UpdateFields(id uint, fields map[string]any) error
+	Delete(id uint) error
+	UpdateStatus(id uint, statusID uint) error
+
+	// สต็อก: DecrementStock คืน false เมื่อสต็อกไม่พอ (หรือเมนูไม่ได้ track stock)
+	DecrementStock(menuID uint, qty int) (bool, error)
+	IncrementStock(menuID uint, qty int) error
+}
+
+type CartStore interface {
+	GetCartWithItems(userID uint) (*entity.Cart, error)
+	GetOrCreateCart(userID, restaurantID uint) (*entity.Cart, error)
+	CountItems(cartID uint) (int64, error)
+	SetRestaurant(cartID, restaurantID uint) error
+	FindItemForUser(userID, itemID uint) (*entity.CartItem, error)
+	UpsertItem(cartID uint, row *entity.CartItem) error
+	UpdateItemPrice(itemID uint, unitPrice, total int64) error
+	UpdateQty(userID, itemID uint, qty int) error
+	DeleteItem(itemID uint) error
+	RemoveItem(userID, itemID uint) error
+	ClearItems(cartID uint) error
+	ClearCart(userID uint) error
+}
+
+type PaymentStore interface {
+	Create(p *entity.Payment) error
+	Save(p *entity.Payment) error
+	GetByOrderID(orderID uint) (*entity.Payment, error)
+	GetLatestByOrderID(orderID uint) (*entity.Payment, error)
+	UpdateStatus(paymentID, statusID uint, paidAt *time.Time) error
+}
+
+type RestaurantStore interface {
+	FindByID(id uint) (*entity.Restaurant, error)
+	IsOwner(restID, userID uint) (bool, error)
+}
+
+// Store รวม repository ทั้งหมด และเปิด transaction ครอบหลาย repository ได้
+type Store interface {
+	Orders() OrderStore
+	Menus() MenuStore
+	Carts() CartStore
+	Payments() PaymentStore
+	Restaurants() RestaurantStore
+	Transaction(fn func(tx Store) error) error
+}
+
+// ---------------- GORM implementation ----------------
+
+type GormStore struct {
+	DB *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{DB: db}
+}
+
+func (s *GormStore) Orders() OrderStore           { return NewOrderRepository(s.DB) }
+func (s *GormStore) Menus() MenuStore             { return NewMenuRepository(s.DB) }
+func (s *GormStore) Carts() CartStore             { return NewCartRepository(s.DB) }
+func (s *GormStore) Payments() PaymentStore       { return NewPaymentRepository(s.DB) }
+func (s *GormStore) Restaurants() RestaurantStore { return NewRestaurantRepository(s.DB) }
+
+func (s *GormStore) Transaction(fn func(tx Store) error) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormStore(tx))
+	})
+}
+
+// ตรวจตอน compile ว่า implementation ครบตาม interface
+var (
+	_ OrderStore      = (*OrderRepository)(nil)
+	_ MenuStore       = (*MenuRepository)(nil)
+	_ CartStore       = (*CartRepository)(nil)
+	_ PaymentStore    = (*PaymentRepository)(nil)
+	_ RestaurantStore = (*RestaurantRepository)(nil)
+	_ Store           = (*GormStore)(nil)
+)
@@ -78,25 +78,72 @@ func (r *UserRepository) DeleteAvatarBase64(userID uint) error {
 func (r *UserRepository) FindWithRestaurant(id uint) (*entity.User, *entity.Restaurant, error) {
 	var user entity.User
 	if err := r.DB.First(&user, id).Error; err != nil {
-			return nil, nil, err
+		return nil, nil, err
 	}
 
 	var restaurant entity.Restaurant
 	if user.Role == "owner" {
-			if err := r.DB.Where("user_id = ?", id).First(&restaurant).Error; err != nil {
-					return &user, nil, nil // owner ที่ยังไม่มีร้าน
-			}
+		if err := r.DB.Where("user_id = ?", id).First(&restaurant).Error; err != nil {
+			return &user, nil, nil // owner ที่ยังไม่มีร้าน
+		}
 	}
 
 	return &user, &restaurant, nil
 }
 
 func (r *UserRepository) FindRestaurantByUserID(userID uint) (*entity.Restaurant, error) {
-    var restaurant entity.Restaurant
-    if err := r.DB.
-        Where("user_id = ?", userID).
-        First(&restaurant).Error; err != nil {
-        return nil, err
-    }
-    return &restaurant, nil
-}
\ No newline at end of file
+	var restaurant entity.Restaurant
+	if err := r.DB.
+		Where("user_id = ?", userID).
+		First(&restaurant).Error; err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+// สร้าง user พร้อมแถว admin ใน transaction เดียว
+func (r *UserRepository) CreateAdmin(user *entity.User, name string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.Admin{Name: name, UserID: user.ID}).Error
+	})
+}
+
+// เปลี่ยนรหัสผ่าน (รับ hash ที่ทำมาแล้ว)
+func (r *UserRepository) UpdatePassword(userID uint, hashed string) error {
+	return r.DB.Model(&entity.User{}).Where("id = ?", userID).Update("password", hashed).Error
+}
+
+// ---------------- login attempts ----------------
+
+// สถานะ login ผิดของ email (ยังไม่เคยผิด = gorm.ErrRecordNotFound)
+func (r *UserRepository) FindLoginAttempt(email string) (*entity.LoginAttempt, error) {
+	var a entity.LoginAttempt
+	if err := r.DB.Where("email = ?", email).First(&a).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// บันทึก login ผิด: update ปรับค่าตาม policy ของ service แล้ว save ใน transaction เดียว
+func (r *UserRepository) RecordLoginFailure(email string, update func(a *entity.LoginAttempt)) (*entity.LoginAttempt, error) {
+	var a entity.LoginAttempt
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(entity.LoginAttempt{Email: email}).FirstOrInit(&a).Error; err != nil {
+			return err
+		}
+		update(&a)
+		return tx.Save(&a).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// login สำเร็จ → ล้างตัวนับ
+func (r *UserRepository) ClearLoginAttempts(email string) error {
+	return r.DB.Where("email = ?", email).Delete(&entity.LoginAttempt{}).Error
+}
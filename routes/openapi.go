@@ -0,0 +1,316 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"backend/controllers"
+	"backend/entity"
+	"backend/openapi"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OpenAPI สร้างเอกสารจาก route ที่ลงทะเบียนกับ r แล้ว + apiDocs
+// problems = route ที่ยังไม่มีใน apiDocs หรือ apiDocs ที่ไม่มี route แล้ว
+func OpenAPI(r *gin.Engine) (*openapi.Document, []string) {
+	gen := openapi.NewGenerator()
+	gen.Define(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+	info := openapi.Info{
+		Title:       "backend API",
+		Version:     "1.0.0",
+		Description: "ทุก response ห่อด้วย envelope {ok, data | error, requestId}",
+	}
+	return openapi.Build(info, r.Routes(), apiDocs(), gen)
+}
+
+// ---------- query ของแต่ละ endpoint (ใช้ทำเอกสารเท่านั้น) ----------
+
+type pageQuery struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+type statusQuery struct {
+	Status string `form:"status"`
+}
+
+type restaurantListQuery struct {
+	CategoryID uint `form:"categoryId"`
+	StatusID   uint `form:"statusId"`
+}
+
+type ownerOrderQuery struct {
+	StatusID uint `form:"statusId"`
+	Page     int  `form:"page"`
+	Limit    int  `form:"limit"`
+}
+
+type riderWorksQuery struct {
+	Page     int       `form:"page"`
+	PageSize int       `form:"pageSize"`
+	OrderID  string    `form:"orderId"`
+	Status   string    `form:"status"`
+	DateFrom time.Time `form:"dateFrom"`
+	DateTo   time.Time `form:"dateTo"`
+}
+
+type notificationQuery struct {
+	Page   int  `form:"page"`
+	Limit  int  `form:"limit"`
+	Unread bool `form:"unread"`
+}
+
+type deliveryQuery struct {
+	Status string `form:"status"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+type reviewQuery struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+	Rating int `form:"rating" binding:"omitempty,min=1,max=5"`
+}
+
+type myReviewQuery struct {
+	Group  string `form:"group" binding:"omitempty,oneof=restaurant"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+type promoCodeQuery struct {
+	Code string `form:"code"`
+}
+
+type wsQuery struct {
+	Token string `form:"token"` // แทน Authorization header (browser ใส่ header ตอน upgrade ไม่ได้)
+}
+
+type chatRoomsQuery struct {
+	All bool `form:"all"` // รวม order ที่จบแล้ว (ส่งแล้ว/ยกเลิก)
+}
+
+// protocol ของ /ws/chat/:roomId (เอกสารอย่างเดียว OpenAPI อธิบาย frame ของ WebSocket ไม่ได้)
+const wsChatDescription = `client → server (JSON):
+- {"body": "...", "typeMessageId": 1} หรือ {"type": "message", ...} ส่งข้อความ
+- {"type": "typing", "typing": true|false} กำลังพิมพ์/หยุดพิมพ์ (ส่งซ้ำได้ทุก ~2.5 วินาทีขณะพิมพ์)
+- {"type": "read", "messageId": 123} อ่านถึงข้อความนี้ (0 = ล่าสุด)
+
+server → client:
+- ข้อความใหม่: Message (แบบเดียวกับ GET /orders/:id/messages ไม่มี field type)
+- {"type": "read", "roomId", "userId", "lastReadMessageId", "readAt"}
+- {"type": "typing", "roomId", "userId", "typing"} ไม่ส่งกลับหาคนพิมพ์; ไม่ได้ยินซ้ำใน 5 วินาที = ถือว่าหยุดพิมพ์`
+
+// ---------- รูปร่างที่ใช้ซ้ำ ----------
+
+// ผู้เขียนรีวิว (null ถ้า user ถูกลบ)
+type reviewUser struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type F = openapi.Fields
+
+var (
+	message = F{"message": ""}
+	review  = F{
+		"id":         uint(0),
+		"rating":     0,
+		"comments":   "",
+		"reviewDate": time.Time{},
+		"user":       (*reviewUser)(nil),
+	}
+)
+
+// apiDocs = คำอธิบายของทุก route (key ต้องตรงกับ path ที่ประกาศใน RegisterRoutes)
+func apiDocs() openapi.Routes {
+	return openapi.Routes{
+		// ---------- เอกสาร / ไฟล์ ----------
+		"GET /openapi.json":       {Hidden: true, Public: true},
+		"GET /docs":               {Hidden: true, Public: true},
+		"GET /uploads/*filepath":  {Hidden: true, Public: true},
+		"HEAD /uploads/*filepath": {Hidden: true, Public: true},
+		"GET /ws/chat/:roomId":    {Summary: "เปิด WebSocket ห้องแชท", Description: wsChatDescription, Query: wsQuery{}, Status: http.StatusSwitchingProtocols},
+
+		// ---------- Health / metrics ----------
+		"GET /healthz": {Summary: "liveness (process ยังตอบได้)", Public: true, Response: F{"status": ""}},
+		"GET /readyz":  {Summary: "readiness (database + ตัวตรวจสลิป); ไม่พร้อม = 503 พร้อม meta.checks", Public: true, Response: F{"status": "", "checks": map[string]string{}}},
+		"GET /metrics": {Hidden: true, Public: true},
+
+		// ---------- Auth ----------
+		"POST /auth/register":     {Summary: "สมัครสมาชิก", Public: true, Request: controllers.RegisterReq{}, Status: http.StatusCreated, Response: F{"user": entity.User{}}},
+		"POST /auth/login":        {Summary: "เข้าสู่ระบบ", Public: true, Request: controllers.LoginReq{}, Response: F{"token": "", "user": entity.User{}}},
+		"GET /auth/me":            {Summary: "ข้อมูลผู้ใช้ปัจจุบัน", Response: F{"user": entity.User{}}},
+		"PATCH /auth/me":          {Summary: "แก้ไขโปรไฟล์", Request: controllers.UpdateMeReq{}, Response: F{"user": entity.User{}}},
+		"POST /auth/me/avatar":    {Summary: "อัปโหลดรูปโปรไฟล์ (base64)", Request: controllers.UploadAvatarReq{}, Response: F{"user": entity.User{}}},
+		"GET /auth/me/avatar":     {Summary: "รูปโปรไฟล์ (base64)", Response: F{"avatarBase64": ""}},
+		"GET /auth/me/restaurant": {Summary: "ร้านของเจ้าของร้านที่ login อยู่", Response: F{"restaurant": entity.Restaurant{}}},
+
+		// ---------- Reports ----------
+		"POST /reports":    {Summary: "แจ้งปัญหา", Request: controllers.CreateReportReq{}, Form: true, Status: http.StatusCreated, Response: F{"report": entity.Report{}}},
+		"GET /reports":     {Summary: "ปัญหาที่ตัวเองแจ้ง", Response: F{"reports": []entity.Report{}}},
+		"GET /reports/:id": {Summary: "รายละเอียดปัญหาที่แจ้ง", Response: F{"report": entity.Report{}}},
+
+		// ---------- Restaurants ----------
+		"GET /restaurants":           {Summary: "รายการร้าน", Public: true, Query: restaurantListQuery{}, Response: F{"items": []controllers.RestaurantResponse{}}},
+		"GET /restaurants/:id":       {Summary: "รายละเอียดร้าน", Public: true, Response: controllers.RestaurantResponse{}},
+		"GET /restaurants/:id/menus": {Summary: "เมนูของร้าน", Public: true, Response: F{"items": []entity.Menu{}}},
+		"GET /menus/:id":             {Summary: "รายละเอียดเมนู", Public: true, Response: entity.Menu{}},
+		"GET /restaurants/:id/reviews": {Summary: "รีวิวของร้าน", Public: true, Query: reviewQuery{},
+			Response: F{"rows": []any{review}, "avg": 0.0, "total": int64(0)}},
+
+		// ---------- Owner ----------
+		"GET /owner/restaurants/:id/orders":           {Summary: "order ของร้าน", Query: ownerOrderQuery{}, Response: controllers.OwnerOrderListOut{}},
+		"GET /owner/restaurants/:id/orders/scheduled": {Summary: "order สั่งล่วงหน้าที่ยังไม่ถึงเวลา", Response: F{"items": []controllers.OwnerScheduledOrder{}}},
+		"GET /owner/restaurants/:id/orders/:orderId":  {Summary: "รายละเอียด order ของร้าน", Response: controllers.OwnerOrderDetail{}},
+		"PATCH /owner/restaurants/:id":                {Summary: "แก้ไขข้อมูลร้าน", Request: controllers.UpdateRestaurantReq{}, Response: message},
+		"POST /owner/restaurants/:id/menus":           {Summary: "เพิ่มเมนู", Request: entity.Menu{}, Status: http.StatusCreated, Response: entity.Menu{}},
+		"PATCH /owner/menus/:id":                      {Summary: "แก้ไขเมนู", Request: entity.Menu{}, Response: entity.Menu{}},
+		"DELETE /owner/menus/:id":                     {Summary: "ลบเมนู", Response: message},
+		"PATCH /owner/menus/:id/status":               {Summary: "เปลี่ยนสถานะเมนู", Request: controllers.MenuStatusReq{}, Response: message},
+		"PUT /owner/restaurants/:id/menus/stock":      {Summary: "ตั้งสต็อกรายวันหลายเมนู", Request: controllers.BulkStockReq{}, Response: F{"items": []entity.Menu{}}},
+		"POST /owner/orders/:orderId/accept":          {Summary: "ร้านรับ order", Status: http.StatusNoContent},
+		"POST /owner/orders/:orderId/cancel":          {Summary: "ร้านยกเลิก order", Status: http.StatusNoContent},
+
+		"GET /owner/restaurants/:id/webhooks": {Summary: "webhook ของร้าน",
+			Response: F{"items": []controllers.WebhookEndpointRes{}, "events": services.WebhookEvents}},
+		"POST /owner/restaurants/:id/webhooks": {Summary: "เพิ่ม webhook (ได้ secret ครั้งเดียว)",
+			Request: controllers.WebhookEndpointReq{}, Status: http.StatusCreated, Response: controllers.WebhookEndpointRes{}},
+		"PATCH /owner/restaurants/:id/webhooks/:webhookId": {Summary: "แก้ไข webhook",
+			Request: controllers.WebhookEndpointUpdateReq{}, Response: controllers.WebhookEndpointRes{}},
+		"DELETE /owner/restaurants/:id/webhooks/:webhookId":             {Summary: "ลบ webhook", Response: message},
+		"POST /owner/restaurants/:id/webhooks/:webhookId/rotate-secret": {Summary: "ออก secret ใหม่", Response: controllers.WebhookEndpointRes{}},
+		"GET /owner/restaurants/:id/webhooks/:webhookId/deliveries": {Summary: "ประวัติการส่ง webhook", Query: deliveryQuery{},
+			Response: F{"items": []entity.WebhookDelivery{}, "total": int64(0), "page": 0, "limit": 0}},
+		"POST /owner/restaurants/:id/webhooks/deliveries/:deliveryId/redeliver": {Summary: "ส่ง webhook ซ้ำ",
+			Status: http.StatusAccepted, Response: F{"deliveryId": uint(0), "status": ""}},
+
+		// ---------- Rider ----------
+		"GET /rider/me": {Summary: "โปรไฟล์ไรเดอร์", Response: F{
+			"userId": uint(0), "firstName": "", "lastName": "", "phoneNumber": "", "avatarBase64": "",
+			"riderId": uint(0), "nationalId": "", "vehiclePlate": "", "zone": "", "license": "", "driveCard": "", "status": "",
+		}},
+		"PUT /rider/me":                       {Summary: "แก้ไขโปรไฟล์ไรเดอร์", Request: controllers.UpdateRiderReq{}},
+		"PATCH /rider/me/availability":        {Summary: "เปิด/ปิดรับงาน", Request: controllers.RiderAvailabilityReq{}},
+		"GET /rider/me/status":                {Summary: "สถานะไรเดอร์", Response: F{"status": "", "isWorking": false}},
+		"GET /rider/works/current":            {Summary: "งานที่กำลังส่ง", Response: F{"work": (*controllers.RiderJobRow)(nil)}},
+		"GET /rider/works/available":          {Summary: "งานที่รอไรเดอร์", Response: F{"items": []controllers.RiderJobRow{}}},
+		"POST /rider/works/:orderId/accept":   {Summary: "รับงาน"},
+		"POST /rider/works/:orderId/complete": {Summary: "ส่งงานเสร็จ"},
+		"GET /rider/works": {Summary: "ประวัติงาน", Query: riderWorksQuery{}, Response: F{
+			"items":   []controllers.RiderWorkItem{},
+			"total":   int64(0),
+			"summary": F{"totalTrips": int64(0), "totalFare": int64(0)},
+		}},
+
+		// ---------- Applications ----------
+		"POST /partner/restaurant-applications": {Summary: "สมัครเปิดร้าน",
+			Request: controllers.ApplyRestaurantReq{}, Status: http.StatusCreated, Response: controllers.ApplyResponse{}},
+		"GET /partner/restaurant-applications": {Summary: "ใบสมัครร้าน", Query: statusQuery{},
+			Response: F{"items": []controllers.RestaurantApplicationResponse{}}},
+		"PATCH /partner/restaurant-applications/:id/approve": {Summary: "อนุมัติร้าน (admin)", Response: F{
+			"applicationId": uint(0), "restaurantId": uint(0), "status": "", "ownerUserId": uint(0),
+			"newRole": "", "accessToken": "", "refreshToken": "",
+		}},
+		"PATCH /partner/restaurant-applications/:id/reject": {Summary: "ปฏิเสธร้าน (admin)",
+			Request: controllers.RejectReq{}, Response: controllers.RejectResponse{}},
+
+		"POST /partner/rider-applications": {Summary: "สมัครเป็นไรเดอร์",
+			Request: controllers.ApplyRiderReq{}, Status: http.StatusCreated, Response: controllers.ApplyRiderResponse{}},
+		"GET /partner/rider-applications/mine": {Summary: "ใบสมัครไรเดอร์ของตัวเอง", Query: statusQuery{},
+			Response: F{"items": []controllers.RiderApplicationResponse{}}},
+		"GET /partner/rider-applications": {Summary: "ใบสมัครไรเดอร์ (admin)", Query: statusQuery{},
+			Response: F{"items": []controllers.RiderApplicationResponse{}}},
+		"PATCH /partner/rider-applications/:id/approve": {Summary: "อนุมัติไรเดอร์ (admin)", Response: controllers.ApproveRiderResponse{}},
+		"PATCH /partner/rider-applications/:id/reject": {Summary: "ปฏิเสธไรเดอร์ (admin)",
+			Request: controllers.RejectRiderReq{}, Response: controllers.RejectRiderResponse{}},
+
+		// ---------- Orders ----------
+		"POST /orders": {Summary: "สั่งอาหาร", Description: "รองรับ Idempotency-Key",
+			Request: controllers.CreateOrderReq{}, Status: http.StatusCreated, Response: controllers.CreateOrderRes{}},
+		"GET /orders/profile": {Summary: "order ของฉัน", Response: F{"items": []controllers.OrderSummary{}}},
+		"GET /orders/:id":     {Summary: "รายละเอียด order", Response: controllers.OrderDetailRes{}},
+		"POST /orders/checkout-from-cart": {Summary: "สั่งจากตะกร้า", Description: "รองรับ Idempotency-Key",
+			Request: controllers.CheckoutFromCartReq{}, Status: http.StatusCreated, Response: controllers.CreateOrderRes{}},
+		"POST /orders/:id/cancel":  {Summary: "ลูกค้ายกเลิก order", Status: http.StatusNoContent},
+		"POST /orders/:id/reorder": {Summary: "สั่งซ้ำ (ใส่ตะกร้าด้วยราคาปัจจุบัน)", Request: controllers.ReorderReq{}, Response: controllers.ReorderRes{}},
+
+		"GET /orders/:id/chatroom":       {Summary: "ห้องแชทของ order", Response: F{"room": entity.ChatRoom{}, "unreadCount": int64(0)}},
+		"GET /orders/:id/messages":       {Summary: "ข้อความในห้องแชท + read receipt ของแต่ละคน", Response: F{"messages": []entity.Message{}, "reads": []entity.ChatRead{}}},
+		"POST /orders/:id/messages":      {Summary: "ส่งข้อความ", Request: controllers.SendMessageReq{}, Response: F{"message": entity.Message{}}},
+		"POST /orders/:id/messages/read": {Summary: "อ่านถึงข้อความ messageId (ไม่ส่ง = ล่าสุด)", Request: controllers.MarkReadReq{}, Response: F{"read": entity.ChatRead{}}},
+		"GET /chatrooms":                 {Summary: "ห้องแชทของฉันพร้อมข้อความล่าสุดและจำนวนที่ยังไม่อ่าน", Query: chatRoomsQuery{}, Response: F{"rooms": []services.ChatRoomSummary{}}},
+
+		// ---------- Cart ----------
+		"GET /cart": {Summary: "ตะกร้า + ผลตรวจราคา/สต็อกปัจจุบัน", Response: F{
+			"cart": &entity.Cart{}, "subtotal": int64(0), "stale": false, "validation": &services.CartValidation{},
+		}},
+		"POST /cart/items":      {Summary: "ใส่ตะกร้า", Request: controllers.AddCartItemReq{}, Status: http.StatusCreated},
+		"PATCH /cart/items/qty": {Summary: "เปลี่ยนจำนวน (0 = ลบ)", Request: controllers.UpdateCartQtyReq{}},
+		"DELETE /cart/items":    {Summary: "ลบรายการ", Request: controllers.RemoveCartItemReq{}},
+		"DELETE /cart":          {Summary: "ล้างตะกร้า"},
+
+		// ---------- Notifications ----------
+		"GET /notifications": {Summary: "กล่องแจ้งเตือน", Query: notificationQuery{}, Response: F{
+			"items": []entity.Notification{}, "total": int64(0), "page": 0, "limit": 0, "unread": int64(0),
+		}},
+		"GET /notifications/unread-count": {Summary: "จำนวนที่ยังไม่อ่าน", Response: F{"unread": int64(0)}},
+		"PATCH /notifications/:id/read":   {Summary: "อ่านแล้ว"},
+		"POST /notifications/read-all":    {Summary: "อ่านทั้งหมด", Response: F{"updated": int64(0)}},
+		"POST /notifications/devices":     {Summary: "ลงทะเบียน push token", Request: controllers.RegisterDeviceReq{}},
+		"DELETE /notifications/devices":   {Summary: "ยกเลิก push token", Request: controllers.UnregisterDeviceReq{}},
+		"GET /notifications/preferences":  {Summary: "การตั้งค่าแจ้งเตือน", Response: F{"preferences": entity.NotificationPreference{}}},
+		"PUT /notifications/preferences": {Summary: "แก้การตั้งค่าแจ้งเตือน",
+			Request: controllers.NotificationPreferenceReq{}, Response: F{"preferences": entity.NotificationPreference{}}},
+
+		// ---------- Payments ----------
+		"GET /api/orders/:id/payment-intent": {Summary: "ข้อมูลสำหรับสร้าง QR PromptPay", Response: F{
+			"orderId": uint(0), "restaurantId": uint(0), "restaurantUserId": uint(0),
+			"promptPayMobile": "", "promptPay": "", "amount": 0.0, "totalBaht": 0.0, "totalSatang": int64(0),
+		}},
+		"GET /api/orders/:id/payment-summary": {Summary: "สรุปการชำระเงิน", Response: F{
+			"orderCode": "", "paidAmount": 0.0, "currency": "", "method": "", "paidAt": (*time.Time)(nil), "txnId": "",
+		}},
+		"POST /api/payments/verify-easyslip": {Summary: "ตรวจสลิปโอนเงิน", Description: "รองรับ Idempotency-Key",
+			Request: controllers.VerifySlipReq{}, Response: F{
+				"matchedAmount": true, "paymentId": uint(0), "expectedBaht": 0.0, "expectedSatang": int64(0),
+				"slipData": openapi.Any(),
+			}},
+
+		// ---------- Admin ----------
+		"GET /admin/dashboard": {Summary: "ตัวเลขสรุป", Response: F{
+			"totalUser": int64(0), "totalRestaurants": int64(0), "pendingApplications": int64(0), "ordersToday": int64(0),
+		}},
+		"GET /admin/restaurant": {Summary: "รายการร้าน (admin)", Query: pageQuery{}, Response: F{
+			"items": []controllers.AdminRestaurantRow{}, "page": 0, "limit": 0, "total": int64(0),
+		}},
+		"GET /admin/rider":                {Summary: "รายการไรเดอร์ (admin)", Response: F{"items": []controllers.AdminRiderRow{}}},
+		"GET /admin/reports":              {Summary: "ปัญหาที่แจ้งทั้งหมด", Response: F{"reports": []entity.Report{}}},
+		"PATCH /admin/reports/:id/status": {Summary: "เปลี่ยนสถานะปัญหา", Request: controllers.ReportStatusReq{}, Response: message},
+		"DELETE /admin/reports/:id":       {Summary: "ลบปัญหา", Response: message},
+		"GET /admin/promotion":            {Summary: "โปรโมชันทั้งหมด", Response: F{"items": []controllers.AdminPromotionRow{}}},
+		"POST /admin/promotion": {Summary: "สร้างโปรโมชัน",
+			Request: controllers.CreatePromotionReq{}, Status: http.StatusCreated, Response: F{"id": uint(0)}},
+		"PUT /admin/promotion/:id":    {Summary: "แก้ไขโปรโมชัน", Request: controllers.PromotionUpdateReq{}, Response: message},
+		"DELETE /admin/promotion/:id": {Summary: "ลบโปรโมชัน", Response: message},
+
+		// ---------- User promotions ----------
+		"GET /user/promotions": {Summary: "โปรโมชันที่เก็บไว้", Response: []entity.UserPromotion{}},
+		"POST /user/promotions": {Summary: "เก็บโปรโมชัน", Description: "รองรับ Idempotency-Key",
+			Request: controllers.PromotionIDReq{}, Status: http.StatusCreated, Response: message},
+		"POST /user/promotions/:id": {Summary: "เก็บโปรโมชัน (id ใน path)", Description: "รองรับ Idempotency-Key",
+			Status: http.StatusCreated, Response: message},
+		"POST /user/promotions/:id/use": {Summary: "ใช้โปรโมชัน", Response: message},
+
+		// ---------- Public / reviews ----------
+		"GET /promotions": {Summary: "โปรโมชันที่ใช้ได้ตอนนี้", Public: true, Query: promoCodeQuery{}, Response: []entity.Promotion{}},
+		"POST /reviews":   {Summary: "รีวิว order (ซ้ำ = แก้รีวิวเดิม)", Request: controllers.CreateReviewReq{}, Response: F{"review": review}},
+		"GET /profile/reviews": {Summary: "รีวิวของฉัน", Description: "group=restaurant = สรุปแยกร้าน (รูปร่าง items ต่างกัน)",
+			Query: myReviewQuery{}, Response: openapi.Any()},
+		"GET /reviews/:id": {Summary: "รีวิวของฉันตาม id", Response: F{"review": review}},
+	}
+}
@@ -1,9 +1,19 @@
 package routes
 
 import (
+	"context"
+	"os"
+	"time"
+
 	"backend/configs"
 	"backend/controllers"
+	"backend/lookups"
+	"backend/metrics"
 	"backend/middlewares"
+	"backend/openapi"
+	"backend/pkg/lifecycle"
+	"backend/pubsub"
+	"backend/ratelimit"
 	"backend/repository"
 	"backend/services"
 	chatws "backend/ws"
@@ -12,49 +22,221 @@ import (
 	"gorm.io/gorm"
 
 	"log"
+	"log/slog"
 )
 
-func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
-	log.Printf("[ROUTES] EasySlip len=%d", len(cfg.EasySlipAPIKey))
+// Option ปรับ dependency ตอนประกอบ routes (เช่น ใช้ fake ตอนทดสอบ)
+type Option func(*options)
+
+type options struct {
+	slipVerifier services.SlipVerifier
+	rateStore    ratelimit.Store
+	lifecycle    *lifecycle.Manager
+	chatBroker   pubsub.Broker
+}
+
+// WithSlipVerifier ใช้ตัวตรวจสลิปที่กำหนดแทน EasySlip
+func WithSlipVerifier(v services.SlipVerifier) Option {
+	return func(o *options) { o.slipVerifier = v }
+}
+
+// WithRateLimitStore ใช้ store ที่กำหนดแทน memory (เช่น Redis เมื่อรันหลาย instance)
+func WithRateLimitStore(s ratelimit.Store) Option {
+	return func(o *options) { o.rateStore = s }
+}
+
+// WithLifecycle ให้ worker เบื้องหลัง (chat hub, scheduler) หยุดตอน Shutdown ของ m
+func WithLifecycle(m *lifecycle.Manager) Option {
+	return func(o *options) { o.lifecycle = m }
+}
+
+// WithChatBroker ใช้ broker ที่กำหนดแทนค่าจาก CHAT_BROKER (เช่น ให้หลาย router ใน test ใช้ตัวเดียวกัน)
+func WithChatBroker(b pubsub.Broker) Option {
+	return func(o *options) { o.chatBroker = b }
+}
+
+func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config, opts ...Option) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.slipVerifier == nil {
+		o.slipVerifier = services.NewEasySlipVerifier(cfg.EasySlipAPIKey, cfg.SlipVerifyTimeout)
+	}
+	if o.rateStore == nil {
+		o.rateStore = ratelimit.NewMemoryStore()
+	}
+	if o.lifecycle == nil {
+		o.lifecycle = lifecycle.New() // ไม่มีใคร Shutdown = worker รันจนจบ process
+	}
+	lc := o.lifecycle
+	if o.chatBroker == nil {
+		b, err := pubsub.New(cfg.ChatBroker, cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("chat broker: %v", err)
+		}
+		if rb, ok := b.(*pubsub.RedisBroker); ok {
+			lc.OnStop("chat-broker", func(context.Context) error { return rb.Close() })
+		}
+		o.chatBroker = b
+	}
+
+	// id ของสถานะ/วิธีชำระ/ประเภท: โหลดครั้งเดียว ขาดตัวไหนให้ล้มตั้งแต่บูต
+	if _, err := lookups.Load(db); err != nil {
+		log.Fatalf("load lookups: %v", err)
+	}
+
+	// CORS + security headers ครอบทุก route (ต้อง Use ก่อนประกาศ route)
+	origins := middlewares.NewOriginAllowList(cfg.CORSOrigins)
+	headers := middlewares.DefaultSecurityHeaders()
+	headers.HSTSMaxAge = cfg.HSTSMaxAge
+	headers.FrameOptions = cfg.FrameOptions
+	// metrics: registry ต่อ router + วัดเวลา query ทุกตัวผ่าน GORM
+	m := metrics.New()
+	if err := db.Use(m.GormPlugin()); err != nil {
+		slog.Error("register gorm metrics plugin", "error", err)
+	}
+
+	r.Use(middlewares.RequestID(), middlewares.AccessLog(), middlewares.Metrics(m), middlewares.Recovery(), middlewares.ErrorHandler())
+	r.Use(middlewares.CORSMiddleware(origins), middlewares.SecurityHeadersMiddleware(headers))
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(middlewares.NoRoute)
+	r.NoMethod(middlewares.NoMethod)
+
+	// ไฟล์ที่ผู้ใช้อัปโหลด: CSP แยก กันไฟล์ถูก render เป็นหน้าเว็บที่รัน script ได้
+	uploadHeaders := headers.With(func(h *middlewares.SecurityHeaders) {
+		h.ContentSecurityPolicy = cfg.UploadsCSP
+	})
+	r.Group("/uploads", middlewares.SecurityHeadersMiddleware(uploadHeaders)).Static("/", "./uploads")
+
+	// เอกสาร API: สร้างจาก route จริงตอนมีคนขอครั้งแรก (route ต้องลงทะเบียนครบแล้ว)
+	r.GET("/openapi.json", openapi.Handler(func() *openapi.Document {
+		doc, problems := OpenAPI(r)
+		for _, p := range problems {
+			slog.Warn("openapi: " + p)
+		}
+		return doc
+	}))
+	docsHeaders := headers.With(func(h *middlewares.SecurityHeaders) {
+		h.ContentSecurityPolicy = openapi.UICSP
+	})
+	r.GET("/docs", middlewares.SecurityHeadersMiddleware(docsHeaders), openapi.UIHandler("backend API", "/openapi.json"))
+
+	// liveness / readiness / Prometheus
+	healthCtl := controllers.NewHealthController(db, o.slipVerifier)
+	if rc, ok := o.chatBroker.(services.ReadyChecker); ok {
+		healthCtl.Checks = map[string]services.ReadyChecker{"chatBroker": rc}
+	}
+	r.GET("/healthz", healthCtl.Live)
+	r.GET("/readyz", healthCtl.Ready)
+	r.GET("/metrics", middlewares.MetricsAuth(cfg.MetricsToken), gin.WrapH(m.Handler()))
 
 	// ------------------------------------------------------------
 	//Repositories
 	// ------------------------------------------------------------
 	userRepo := repository.NewUserRepository(db)
 	chatRepo := repository.NewChatRepository(db)
-
+	store := repository.NewGormStore(db)
 
 	// ------------------------------------------------------------
 	// Services
 	// ------------------------------------------------------------
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret, cfg.JWTTTL)	
+	authService := services.NewAuthService(userRepo, cfg.JWTSecret, cfg.JWTTTL)
+	authService.MaxAvatarBytes = cfg.MaxAvatarBytes
+	authService.Lockout = services.LockoutPolicy{
+		MaxFailures: cfg.LoginMaxFailures,
+		Base:        cfg.LoginLockout,
+		Max:         cfg.LoginLockoutMax,
+	}
 	userPromoService := services.NewUserPromotionService(db)
 
-	chatService := services.NewChatService(db, chatRepo)
-
-	// Hub WS
+	// Push: เลือก provider ตาม key ที่มี (ไม่มี key = เขียน log อย่างเดียว)
+	pushProviders := map[string]services.Notifier{}
+	if cfg.FCMCredentialsFile != "" {
+		creds, err := os.ReadFile(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Fatalf("fcm credentials: %v", err)
+		}
+		fcm, err := services.NewFCMNotifier(creds, cfg.FCMProjectID)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		fcm.HTTPClient.Timeout = cfg.UpstreamTimeout
+		pushProviders["android"] = fcm
+		pushProviders["web"] = fcm
+	}
+	if cfg.APNsAuthToken != "" && cfg.APNsTopic != "" {
+		apns := services.NewAPNsNotifier(cfg.APNsAuthToken, cfg.APNsTopic, cfg.APNsSandbox)
+		apns.HTTPClient.Timeout = cfg.UpstreamTimeout
+		pushProviders["ios"] = apns
+	}
+	pushService := services.NewPushService(db, pushProviders, services.LogNotifier{})
+	inboxService := services.NewInboxService(db)
+	pushService.Inbox = inboxService
+
+	chatService := services.NewChatService(db, chatRepo, pushService)
+	chatService.Broker = o.chatBroker
+	webhookService := services.NewWebhookService(db)
+	webhookService.HTTPClient.Timeout = cfg.UpstreamTimeout
+	lc.Go("webhook-delivery", webhookService.Run) // ส่ง + retry delivery ที่ค้างอยู่ใน DB
+
+	orderService := services.NewOrderService(store, webhookService, pushService)
+	orderService.DefaultDeliveryFee = cfg.DefaultDeliveryFee
+	orderService.Location = cfg.Timezone
+	orderService.Metrics = m
+	cartService := services.NewCartService(store)
+	cartService.Location = cfg.Timezone
+	menuService := services.NewMenuService(store)
+	paymentService := services.NewPaymentService(store, o.slipVerifier, webhookService)
+	paymentService.MaxSlipBytes = cfg.MaxSlipBytes
+	paymentService.Metrics = m
+
+	// Hub WS (origin ตรวจด้วย allow-list เดียวกับ CORS)
 	hub := chatws.NewChatHub(chatService)
-	go hub.Run()
+	hub.CheckOrigin = origins.CheckRequest
+	hub.Metrics = m
+	hub.MaxMessageBytes = cfg.WSMaxMessageBytes
+	hub.SendBuffer = cfg.WSSendBuffer
+	hub.PongWait = cfg.WSPongWait
+	hub.Broker = o.chatBroker
+	lc.Go("chat-hub", hub.Run)
+	lc.Go("chat-relay", hub.Relay)
+
+	// ปล่อย order สั่งล่วงหน้าเข้าคิวร้านเมื่อถึงเวลา
+	orderScheduler := services.NewOrderScheduler(db, webhookService, pushService)
+	lc.Go("order-scheduler", orderScheduler.Run)
+
+	// reset สต็อกรายวันตอนร้านเปิด
+	stockService := services.NewStockService(db)
+	stockService.Location = cfg.Timezone
+	lc.Go("stock-reset", stockService.Run)
 
 	// ------------------------------------------------------------
 	// Controllers
 	// ------------------------------------------------------------
 	authController := controllers.NewAuthController(authService)
-	menuController := controllers.NewMenuController(db)
-	reportController := controllers.NewReportController(db)
-	rAppController := controllers.NewRestaurantApplicationController(db, cfg)
-	riderAppCtl := controllers.NewRiderApplicationController(db)
-	
-	ownerOrderCtl := controllers.NewOwnerOrderController(db)
-	cartCtl := controllers.NewCartController(db)
-	riderCtl := controllers.NewRiderController(db)
+	menuController := controllers.NewMenuController(menuService)
+	reportController := controllers.NewReportController(db, pushService)
+	rAppController := controllers.NewRestaurantApplicationController(db, cfg, pushService)
+	riderAppCtl := controllers.NewRiderApplicationController(db, pushService)
+
+	ownerOrderCtl := controllers.NewOwnerOrderController(db, webhookService, pushService)
+	cartCtl := controllers.NewCartController(cartService)
+	riderCtl := controllers.NewRiderController(db, webhookService, pushService)
+	riderCtl.Metrics = m
 	chatController := controllers.NewChatController(chatService)
 	reviewCtl := controllers.NewReviewController(db)
-	orderCtl := controllers.NewOrderController(db)
+	orderCtl := controllers.NewOrderController(orderService)
 	restController := controllers.NewRestaurantController(db)
-	
+
 	userPromoCtrl := controllers.NewUserPromotionController(userPromoService)
 	adminCtrl := controllers.NewAdminController(db)
+	webhookCtl := controllers.NewWebhookController(db, webhookService)
+	deviceCtl := controllers.NewDeviceController(db, pushService)
+	notificationCtl := controllers.NewNotificationController(inboxService)
+
+	// กัน request ซ้ำ (ต้องวางหลัง AuthMiddleware)
+	idempotent := middlewares.Idempotency(db, 24*time.Hour)
 
 	// ------------------------------------------------------------
 	// Routes
@@ -62,8 +244,8 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	// ---------- Auth ----------
 	authGroup := r.Group("/auth")
 	{
-		authGroup.POST("/register", authController.Register)
-		authGroup.POST("/login", authController.Login)
+		authGroup.POST("/register", middlewares.RateLimit(o.rateStore, "auth.register", cfg.RateLimitAuth), authController.Register)
+		authGroup.POST("/login", middlewares.RateLimit(o.rateStore, "auth.login", cfg.RateLimitAuth), authController.Login)
 
 		authGroup.Use(middlewares.AuthMiddleware(cfg.JWTSecret))
 		{
@@ -78,7 +260,7 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	// ---------- Reports ----------
 	reportsGroup := r.Group("/reports", middlewares.AuthMiddleware(cfg.JWTSecret))
 	{
-		reportsGroup.POST("", reportController.CreateReport)
+		reportsGroup.POST("", middlewares.RateLimit(o.rateStore, "reports.create", cfg.RateLimitReports), reportController.CreateReport)
 		reportsGroup.GET("", reportController.ListReports)
 		reportsGroup.GET("/:id", reportController.GetReportByID)
 	}
@@ -93,21 +275,32 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	ownerGroup := r.Group("/owner", middlewares.AuthMiddleware(cfg.JWTSecret))
 	{
 		ownerGroup.GET("/restaurants/:id/orders", ownerOrderCtl.List)
+		ownerGroup.GET("/restaurants/:id/orders/scheduled", ownerOrderCtl.Scheduled)
 		ownerGroup.GET("/restaurants/:id/orders/:orderId", ownerOrderCtl.Detail)
 		ownerGroup.PATCH("/restaurants/:id", restController.Update)
 		ownerGroup.POST("/restaurants/:id/menus", menuController.Create)
 		ownerGroup.PATCH("/menus/:id", menuController.Update)
 		ownerGroup.DELETE("/menus/:id", menuController.Delete)
 		ownerGroup.PATCH("/menus/:id/status", menuController.UpdateStatus)
+		ownerGroup.PUT("/restaurants/:id/menus/stock", menuController.BulkUpdateStock)
 		ownerGroup.POST("/orders/:orderId/accept", ownerOrderCtl.Accept)
 		ownerGroup.POST("/orders/:orderId/cancel", ownerOrderCtl.Cancel)
+
+		// Webhooks ของร้าน (POS / integrator)
+		ownerGroup.GET("/restaurants/:id/webhooks", webhookCtl.List)
+		ownerGroup.POST("/restaurants/:id/webhooks", webhookCtl.Create)
+		ownerGroup.PATCH("/restaurants/:id/webhooks/:webhookId", webhookCtl.Update)
+		ownerGroup.DELETE("/restaurants/:id/webhooks/:webhookId", webhookCtl.Delete)
+		ownerGroup.POST("/restaurants/:id/webhooks/:webhookId/rotate-secret", webhookCtl.RotateSecret)
+		ownerGroup.GET("/restaurants/:id/webhooks/:webhookId/deliveries", webhookCtl.Deliveries)
+		ownerGroup.POST("/restaurants/:id/webhooks/deliveries/:deliveryId/redeliver", webhookCtl.Redeliver)
 	}
 
 	// ---------- Rider ----------
 	riderGroup := r.Group("/rider", middlewares.AuthMiddleware(cfg.JWTSecret))
 	{
 		riderGroup.GET("/me", riderCtl.GetProfile)
-    riderGroup.PUT("/me", riderCtl.UpdateMe) 
+		riderGroup.PUT("/me", riderCtl.UpdateMe)
 
 		riderGroup.PATCH("/me/availability", riderCtl.SetAvailability)
 		riderGroup.GET("/me/status", riderCtl.GetStatus)
@@ -148,17 +341,23 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	// ---------- Orders + Cart ----------
 	authOrder := r.Group("/orders", middlewares.AuthMiddleware(cfg.JWTSecret))
 	{
-		authOrder.POST("", orderCtl.Create)
+		authOrder.POST("", idempotent, orderCtl.Create)
 		authOrder.GET("/profile", orderCtl.ListForMe)
 		authOrder.GET("/:id", orderCtl.Detail)
-		authOrder.POST("/checkout-from-cart", orderCtl.CheckoutFromCart)
+		authOrder.POST("/checkout-from-cart", idempotent, orderCtl.CheckoutFromCart)
+		authOrder.POST("/:id/cancel", orderCtl.Cancel)
+		authOrder.POST("/:id/reorder", orderCtl.Reorder)
 
 		// Chat REST
 		authOrder.GET("/:id/chatroom", chatController.GetOrCreateRoom)
 		authOrder.GET("/:id/messages", chatController.GetMessages)
 		authOrder.POST("/:id/messages", chatController.SendMessage)
+		authOrder.POST("/:id/messages/read", chatController.MarkRead)
 	}
 
+	// ห้องแชทของฉัน (รวมทุก order) + จำนวนที่ยังไม่อ่าน
+	r.GET("/chatrooms", middlewares.AuthMiddleware(cfg.JWTSecret), chatController.ListRooms)
+
 	authCart := r.Group("/cart", middlewares.AuthMiddleware(cfg.JWTSecret))
 	{
 		authCart.GET("", cartCtl.Get)
@@ -168,6 +367,20 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 		authCart.DELETE("", cartCtl.Clear)
 	}
 
+	// ---------- Notifications ----------
+	notiGroup := r.Group("/notifications", middlewares.AuthMiddleware(cfg.JWTSecret))
+	{
+		notiGroup.GET("", notificationCtl.List)
+		notiGroup.GET("/unread-count", notificationCtl.UnreadCount)
+		notiGroup.PATCH("/:id/read", notificationCtl.MarkRead)
+		notiGroup.POST("/read-all", notificationCtl.MarkAllRead)
+
+		notiGroup.POST("/devices", deviceCtl.Register)
+		notiGroup.DELETE("/devices", deviceCtl.Unregister)
+		notiGroup.GET("/preferences", deviceCtl.GetPreferences)
+		notiGroup.PUT("/preferences", deviceCtl.UpdatePreferences)
+	}
+
 	// ---------- Chat WS ----------
 	wsGroup := r.Group("/ws", middlewares.WSAuthMiddleware(cfg.JWTSecret))
 	{
@@ -175,7 +388,7 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	}
 
 	// Payment controller
-	paymentController := controllers.NewPaymentController(db, cfg.EasySlipAPIKey) // ===== EasySlip API Key ส่วนมากปัญหาอยู่ตรงนี้
+	paymentController := controllers.NewPaymentController(paymentService)
 
 	r.GET("/api/orders/:id/payment-intent", middlewares.AuthMiddleware(cfg.JWTSecret), paymentController.GetPaymentIntent)
 	r.GET("/api/orders/:id/payment-summary", middlewares.AuthMiddleware(cfg.JWTSecret), paymentController.GetPaymentSummary)
@@ -186,7 +399,8 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 		paymentsGroup := apiGroup.Group("/payments")
 		paymentsGroup.Use(middlewares.AuthMiddleware(cfg.JWTSecret))
 		{
-			paymentsGroup.POST("/verify-easyslip", paymentController.VerifyEasySlip)
+			slipLimit := middlewares.RateLimit(o.rateStore, "payments.verify-easyslip", cfg.RateLimitSlipVerify)
+			paymentsGroup.POST("/verify-easyslip", slipLimit, idempotent, paymentController.VerifyEasySlip)
 		}
 	}
 
@@ -213,13 +427,13 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, cfg *configs.Config) {
 	user := r.Group("/user")
 	user.Use(middlewares.AuthMiddleware(cfg.JWTSecret)) // ตรวจ JWT อย่างเดียว ไม่บังคับ role
 	{
-		user.GET("/promotions", userPromoCtrl.List)                // ดูรายการที่ user คนนั้นเก็บไว้
-		user.POST("/promotions", userPromoCtrl.SavePromotion)      // body: { promoId } หรือ { promotionId }
-		user.POST("/promotions/:id", userPromoCtrl.SavePromotion)  // หรือ path param
+		user.GET("/promotions", userPromoCtrl.List)                           // ดูรายการที่ user คนนั้นเก็บไว้
+		user.POST("/promotions", idempotent, userPromoCtrl.SavePromotion)     // body: { promoId } หรือ { promotionId }
+		user.POST("/promotions/:id", idempotent, userPromoCtrl.SavePromotion) // หรือ path param
 		user.POST("/promotions/:id/use", userPromoCtrl.UsePromotion)
 	}
 
-	// ---------- Public 
+	// ---------- Public
 	r.GET("/promotions", controllers.ListActivePromotions(db))
 	r.GET("/restaurants/:id/reviews", reviewCtl.ListForRestaurant)
 
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"backend/configs"
+)
+
+// runSeed = backend seed [lookups|demo|all]
+func runSeed(args []string) {
+	target := "lookups"
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if target != "lookups" && target != "demo" && target != "all" {
+		fmt.Fprintln(os.Stderr, "usage: backend seed [lookups|demo|all]")
+		os.Exit(2)
+	}
+
+	cfg := bootstrap()
+
+	// demo data อ้างถึง lookup เสมอ จึง seed lookup ก่อนทุกครั้ง
+	if err := configs.SeedLookups(); err != nil {
+		log.Fatalf("seed lookups failed: %v", err)
+	}
+	if target == "demo" || target == "all" {
+		if err := configs.SeedDemoData(); err != nil {
+			log.Fatalf("seed demo data failed: %v", err)
+		}
+	}
+	if target == "all" {
+		if err := configs.SeedAdmin(cfg); err != nil {
+			log.Fatalf("seed admin failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"backend/configs"
+	"backend/migrations"
+	"backend/pkg/lifecycle"
+	"backend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runServe = backend serve
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	seedDemo := fs.Bool("seed-demo", false, "seed demo users/restaurants on boot (default from SEED_DEMO)")
+	fs.Parse(args)
+
+	cfg := bootstrap()
+	db := configs.DB()
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed-demo" {
+			cfg.SeedDemo = *seedDemo
+		}
+	})
+	cfg.LogEffective()
+
+	// migrate: apply เฉพาะ migration ที่ยังไม่ได้รัน (ปิดได้ด้วย DB_AUTO_MIGRATE=false)
+	if cfg.DBAutoMigrate {
+		ran, err := migrations.Up(db)
+		if err != nil {
+			fatal("migrate failed", "error", err)
+		}
+		for _, m := range ran {
+			slog.Info("migrated", "version", m.Version, "name", m.Name)
+		}
+	}
+
+	if err := configs.SeedAdmin(cfg); err != nil {
+		fatal("seed admin failed", "error", err)
+	}
+	if err := configs.SeedLookups(); err != nil {
+		fatal("seed lookups failed", "error", err)
+	}
+	if cfg.SeedDemo {
+		if err := configs.SeedDemoData(); err != nil {
+			fatal("seed demo data failed", "error", err)
+		}
+	}
+
+	// HTTP (access log / recovery ใส่ใน RegisterRoutes ให้ผ่าน slog)
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		fatal("invalid TRUSTED_PROXIES", "error", err)
+	}
+	lc := lifecycle.New()
+	lc.OnStop("database", func(context.Context) error { return configs.CloseDB() })
+	routes.RegisterRoutes(r, db, cfg, routes.WithLifecycle(lc))
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.Port),
+		Handler:      r,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	// SIGTERM (deploy) / Ctrl+C → หยุดรับ request ใหม่ รอที่ค้าง แล้วหยุด worker + ปิด DB
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server running", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// เปิด port ไม่ได้ ฯลฯ: ยังต้องหยุด worker ก่อนออก
+		_ = lc.Shutdown(context.Background())
+		fatal("server stopped", "error", err)
+	case <-ctx.Done():
+	}
+	stop() // สัญญาณครั้งที่สอง = ออกทันที
+
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// WebSocket ถูก hijack ไปแล้ว http.Server ไม่รอ → hub ส่ง close frame เองใน lc.Shutdown
+	failed := false
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http shutdown", "error", err)
+		failed = true
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("server stopped", "error", err)
+		failed = true
+	}
+	if err := lc.Shutdown(shutdownCtx); err != nil {
+		slog.Error("background shutdown", "error", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+	slog.Info("server stopped")
+}
@@ -4,18 +4,75 @@ import (
 	"backend/entity"
 	"backend/repository"
 	"backend/utils"
+	"context"
 	"errors"
+	"log/slog"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrEmailTaken       = errors.New("email already registered")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrPasswordTooShort = errors.New("password must be at least 6 characters")
+
+	ErrAvatarTooLarge      = errors.New("file too large")
+	ErrInvalidAvatarFormat = errors.New("invalid image format")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// AccountLockedError = login ผิดติดกันจนถูกล็อกชั่วคราว
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string { return "account temporarily locked" }
+
+// LockoutPolicy = ล็อกเมื่อ login ผิดติดกันครบ MaxFailures ครั้ง (ล็อก Base)
+// ผิดต่อจากนั้นเพิ่มเวลาเป็น 2 เท่าทุกครั้ง ไม่เกิน Max; ไม่ผิดเลยนานกว่า Max = เริ่มนับใหม่
+type LockoutPolicy struct {
+	MaxFailures int // 0 = ปิด
+	Base        time.Duration
+	Max         time.Duration
+}
+
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{MaxFailures: 5, Base: time.Minute, Max: 24 * time.Hour}
+}
+
+// ระยะเวลาล็อกหลังผิดครั้งที่ failures (0 = ยังไม่ล็อก)
+func (p LockoutPolicy) lockFor(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+	n := failures - p.MaxFailures
+	if n > 30 {
+		return p.Max
+	}
+	d := p.Base << n
+	if d <= 0 || d > p.Max {
+		return p.Max
+	}
+	return d
+}
+
+// ความยาวขั้นต่ำเท่ากับ binding ของ /auth/register
+const minPasswordLen = 6
+
 // AuthService จัดการ business logic ของการ login/register
 type AuthService struct {
 	userRepo  *repository.UserRepository
 	jwtSecret string
 	jwtTTL    time.Duration
+
+	MaxAvatarBytes int // ขนาด base64 ของ avatar สูงสุด (0 = 10MB)
+	Lockout        LockoutPolicy
+
+	now func() time.Time
 }
 
 func NewAuthService(repo *repository.UserRepository, secret string, ttl time.Duration) *AuthService {
@@ -23,6 +80,8 @@ func NewAuthService(repo *repository.UserRepository, secret string, ttl time.Dur
 		userRepo:  repo,
 		jwtSecret: secret,
 		jwtTTL:    ttl,
+		Lockout:   DefaultLockoutPolicy(),
+		now:       time.Now,
 	}
 }
 
@@ -37,7 +96,7 @@ func (s *AuthService) Register(email, password, firstName, lastName, phone strin
 		return nil, err
 	}
 	if count > 0 {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailTaken
 	}
 
 	// hash password
@@ -61,17 +120,86 @@ func (s *AuthService) Register(email, password, firstName, lastName, phone strin
 	return user, nil
 }
 
+// CreateAdmin สร้าง user role admin พร้อมแถว admin (ใช้จาก CLI)
+func (s *AuthService) CreateAdmin(email, password, name string) (*entity.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(password) < minPasswordLen {
+		return nil, ErrPasswordTooShort
+	}
+	count, err := s.userRepo.CountByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrEmailTaken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("hash password failed")
+	}
+	user := &entity.User{
+		Email:     email,
+		Password:  string(hashed),
+		FirstName: "Admin",
+		LastName:  strings.TrimSpace(name),
+		Role:      "admin",
+	}
+	if err := s.userRepo.CreateAdmin(user, name); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ให้ user ตาม email (ใช้จาก CLI)
+func (s *AuthService) ResetPassword(email, password string) (*entity.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(password) < minPasswordLen {
+		return nil, ErrPasswordTooShort
+	}
+	user, err := s.userRepo.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("hash password failed")
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, string(hashed)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // Login ตรวจสอบ user + สร้าง JWT
-func (s *AuthService) Login(email, password string) (string, *entity.User, error) {
+func (s *AuthService) Login(ctx context.Context, email, password string) (string, *entity.User, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
+	now := s.now()
+
+	// ถูกล็อกอยู่ → ไม่ตรวจรหัสผ่านเลย (กันเดาต่อระหว่างล็อก)
+	if s.Lockout.MaxFailures > 0 {
+		if a, err := s.userRepo.FindLoginAttempt(email); err == nil && a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+			return "", nil, &AccountLockedError{Until: *a.LockedUntil}
+		}
+	}
+
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return "", nil, s.loginFailed(ctx, email, now)
 	}
 
 	// เทียบรหัสผ่าน
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return "", nil, s.loginFailed(ctx, email, now)
+	}
+	if s.Lockout.MaxFailures > 0 {
+		if err := s.userRepo.ClearLoginAttempts(email); err != nil {
+			slog.ErrorContext(ctx, "auth: clear login attempts failed", "email", email, "error", err)
+		}
 	}
 
 	// ออก token
@@ -83,6 +211,32 @@ func (s *AuthService) Login(email, password string) (string, *entity.User, error
 	return token, user, nil
 }
 
+// loginFailed นับครั้งที่ผิด แล้วคืน error ที่จะตอบ client
+func (s *AuthService) loginFailed(ctx context.Context, email string, now time.Time) error {
+	if s.Lockout.MaxFailures <= 0 {
+		return ErrInvalidCredentials
+	}
+	a, err := s.userRepo.RecordLoginFailure(email, func(a *entity.LoginAttempt) {
+		if now.Sub(a.LastFailedAt) > s.Lockout.Max {
+			a.Failures = 0
+		}
+		a.Failures++
+		a.LastFailedAt = now
+		if d := s.Lockout.lockFor(a.Failures); d > 0 {
+			until := now.Add(d)
+			a.LockedUntil = &until
+		}
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "auth: record login failure failed", "email", email, "error", err)
+		return ErrInvalidCredentials
+	}
+	if a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+		return &AccountLockedError{Until: *a.LockedUntil}
+	}
+	return ErrInvalidCredentials
+}
+
 // GetProfile
 func (s *AuthService) GetProfile(userID uint) (*entity.User, error) {
 	return s.userRepo.FindByID(userID)
@@ -98,11 +252,15 @@ func (s *AuthService) UpdateProfile(userID uint, updates map[string]any) (*entit
 
 // ✅ Upload avatar (Base64)
 func (s *AuthService) UploadAvatarBase64(userID uint, b64 string) error {
-	if len(b64) > 10*1024*1024 { // limit 10MB
-		return errors.New("file too large")
+	limit := s.MaxAvatarBytes
+	if limit <= 0 {
+		limit = 10 * 1024 * 1024
+	}
+	if len(b64) > limit {
+		return ErrAvatarTooLarge
 	}
 	if !strings.HasPrefix(b64, "data:image/") {
-		return errors.New("invalid image format")
+		return ErrInvalidAvatarFormat
 	}
 	return s.userRepo.SaveAvatarBase64(userID, b64)
 }
@@ -112,11 +270,10 @@ func (s *AuthService) GetAvatarBase64(userID uint) (string, error) {
 	return s.userRepo.FindAvatarBase64(userID)
 }
 
-
 func (s *AuthService) GetProfileWithRestaurant(userID uint) (*entity.User, *entity.Restaurant, error) {
-    return s.userRepo.FindWithRestaurant(userID)
+	return s.userRepo.FindWithRestaurant(userID)
 }
 
 func (s *AuthService) GetRestaurantByUserID(userID uint) (*entity.Restaurant, error) {
-    return s.userRepo.FindRestaurantByUserID(userID)
-}
\ No newline at end of file
+	return s.userRepo.FindRestaurantByUserID(userID)
+}
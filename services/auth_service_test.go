@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/repository"
+)
+
+func TestLoginLockoutGrowsAndResets(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewAuthService(repository.NewUserRepository(db), "secret", time.Hour)
+	svc.Lockout = LockoutPolicy{MaxFailures: 3, Base: time.Minute, Max: time.Hour}
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return clock }
+
+	if _, err := svc.Register("locked@example.com", "correct-horse", "A", "B", ""); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	login := func(password string) error {
+		_, _, err := svc.Login(ctx, "locked@example.com", password)
+		return err
+	}
+	// wantLock = ต้องถูกล็อกไปอีก d นับจาก clock
+	wantLock := func(step string, err error, d time.Duration) {
+		t.Helper()
+		var locked *AccountLockedError
+		if !errors.As(err, &locked) {
+			t.Fatalf("%s: err = %v, want AccountLockedError", step, err)
+		}
+		if got := locked.Until.Sub(clock); got != d {
+			t.Fatalf("%s: locked for %v, want %v", step, got, d)
+		}
+	}
+
+	for i := 1; i < 3; i++ {
+		if err := login("wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failure %d: err = %v, want ErrInvalidCredentials", i, err)
+		}
+	}
+	wantLock("3rd failure", login("wrong"), time.Minute)
+
+	// ระหว่างล็อก รหัสถูกก็เข้าไม่ได้
+	clock = clock.Add(30 * time.Second)
+	wantLock("correct password while locked", login("correct-horse"), 30*time.Second)
+
+	// ผิดต่อหลังหมดล็อก → เวลาเพิ่มเป็น 2 เท่า
+	clock = clock.Add(31 * time.Second)
+	wantLock("4th failure", login("wrong"), 2*time.Minute)
+	clock = clock.Add(2*time.Minute + time.Second)
+	wantLock("5th failure", login("wrong"), 4*time.Minute)
+
+	// login สำเร็จ = เริ่มนับใหม่
+	clock = clock.Add(4*time.Minute + time.Second)
+	if err := login("correct-horse"); err != nil {
+		t.Fatalf("login after lock expired: %v", err)
+	}
+	if err := login("wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("failure after success: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLockoutPolicyLockFor(t *testing.T) {
+	p := LockoutPolicy{MaxFailures: 5, Base: time.Minute, Max: time.Hour}
+	for _, tc := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour}, // 64 นาที เกิน Max
+		{100, time.Hour},
+	} {
+		if got := p.lockFor(tc.failures); got != tc.want {
+			t.Errorf("lockFor(%d) = %v, want %v", tc.failures, got, tc.want)
+		}
+	}
+	if got := (LockoutPolicy{}).lockFor(100); got != 0 {
+		t.Errorf("disabled policy: lockFor = %v, want 0", got)
+	}
+}
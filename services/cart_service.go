@@ -0,0 +1,125 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCartItemNotFound = errors.New("cart item not found")
+
+// CartView = ตะกร้า + ยอดรวม + ผลตรวจกับเมนูปัจจุบัน (สำหรับ GET /cart)
+type CartView struct {
+	Cart       *entity.Cart
+	Subtotal   int64
+	Validation *CartValidation
+}
+
+type CartService struct {
+	Store    repository.Store
+	Location *time.Location // โซนเวลาของร้านสำหรับตรวจเวลาเปิด-ปิด (nil = โซนของเครื่อง)
+}
+
+func NewCartService(store repository.Store) *CartService {
+	return &CartService{Store: store}
+}
+
+// Get ตะกร้าของ user (ไม่มีใน DB ก็คืนตะกร้าว่าง)
+func (s *CartService) Get(userID uint) (*CartView, error) {
+	cart, err := s.Store.Carts().GetCartWithItems(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtotal int64
+	for _, item := range cart.Items {
+		subtotal += item.Total
+	}
+
+	// แจ้ง FE ว่ามีรายการที่ราคาเปลี่ยน/ของหมด/เมนูถูกลบ ตั้งแต่ใส่ตะกร้า
+	validation, err := ValidateCart(s.Store, cart, time.Now(), s.Location)
+	if err != nil {
+		return nil, err
+	}
+	return &CartView{Cart: cart, Subtotal: subtotal, Validation: validation}, nil
+}
+
+// AddItem เพิ่มเมนูลงตะกร้า (ตะกร้ามีของร้านอื่นอยู่ → ล้างก่อน)
+func (s *CartService) AddItem(userID, restaurantID, menuID uint, qty int, note string) error {
+	if qty <= 0 {
+		qty = 1
+	}
+
+	menu, err := s.Store.Menus().FindByID(menuID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMenuNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if menu.RestaurantID != restaurantID {
+		return ErrMenuNotInRestaurant
+	}
+
+	return s.Store.Transaction(func(tx repository.Store) error {
+		carts := tx.Carts()
+		cart, err := carts.GetOrCreateCart(userID, restaurantID)
+		if err != nil {
+			return err
+		}
+
+		if cart.RestaurantID != restaurantID {
+			if err := carts.ClearItems(cart.ID); err != nil {
+				return err
+			}
+			if err := carts.SetRestaurant(cart.ID, restaurantID); err != nil {
+				return err
+			}
+		}
+
+		return carts.UpsertItem(cart.ID, &entity.CartItem{
+			MenuID:    menu.ID,
+			Qty:       qty,
+			UnitPrice: menu.Price,
+			Total:     menu.Price * int64(qty),
+			Note:      note,
+		})
+	})
+}
+
+// UpdateQty เปลี่ยนจำนวน (qty <= 0 = ลบรายการ)
+func (s *CartService) UpdateQty(userID, itemID uint, qty int) error {
+	return s.Store.Transaction(func(tx repository.Store) error {
+		if err := s.ensureOwned(tx, userID, itemID); err != nil {
+			return err
+		}
+		return tx.Carts().UpdateQty(userID, itemID, qty)
+	})
+}
+
+// RemoveItem ลบรายการ (ตะกร้าว่างแล้ว → reset ร้าน)
+func (s *CartService) RemoveItem(userID, itemID uint) error {
+	return s.Store.Transaction(func(tx repository.Store) error {
+		if err := s.ensureOwned(tx, userID, itemID); err != nil {
+			return err
+		}
+		return tx.Carts().RemoveItem(userID, itemID)
+	})
+}
+
+// Clear ล้างตะกร้า (ไม่มีตะกร้าก็ถือว่าล้างแล้ว)
+func (s *CartService) Clear(userID uint) error {
+	return s.Store.Carts().ClearCart(userID)
+}
+
+// ตรวจว่ารายการเป็นของ cart ของ user จริง
+func (s *CartService) ensureOwned(tx repository.Store, userID, itemID uint) error {
+	_, err := tx.Carts().FindItemForUser(userID, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCartItemNotFound
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"backend/entity"
+	"backend/lookups"
+)
+
+func TestCartAddItemMergesAndSwitchesRestaurant(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, nil)
+	shop.addToCart(t, rice, 1)
+	shop.addToCart(t, rice, 2) // เมนู + note เดียวกัน → รวม qty
+
+	svc := NewCartService(shop.store)
+	view, err := svc.Get(shopCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Cart.Items) != 1 || view.Cart.Items[0].Qty != 3 || view.Subtotal != 150 {
+		t.Fatalf("cart = %+v subtotal=%d", view.Cart.Items, view.Subtotal)
+	}
+
+	other := &entity.Restaurant{Name: "ร้านอื่น", RestaurantStatusID: lookups.ID(lookups.RestaurantOpen)}
+	shop.store.AddRestaurant(other)
+	noodle := &entity.Menu{Name: "ก๋วยเตี๋ยว", Price: 40, RestaurantID: other.ID, MenuStatusID: lookups.ID(lookups.MenuAvailable)}
+	if err := shop.store.Menus().Create(noodle); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddItem(shopCustomer, other.ID, noodle.ID, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	view, _ = svc.Get(shopCustomer)
+	if view.Cart.RestaurantID != other.ID || len(view.Cart.Items) != 1 || view.Cart.Items[0].MenuID != noodle.ID {
+		t.Fatalf("cart after switching restaurant = %+v", view.Cart)
+	}
+
+	if err := svc.AddItem(shopCustomer, shop.rest.ID, noodle.ID, 1, ""); !errors.Is(err, ErrMenuNotInRestaurant) {
+		t.Fatalf("err = %v, want ErrMenuNotInRestaurant", err)
+	}
+}
+
+func TestCartGetFlagsStaleItems(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, nil)
+	soup := shop.menu(t, "ต้มยำ", 80, nil)
+	tea := shop.menu(t, "ชาไทย", 30, nil)
+	for _, m := range []*entity.Menu{rice, soup, tea} {
+		shop.addToCart(t, m, 1)
+	}
+	shop.setPrice(t, rice, 45)
+	if err := shop.store.Menus().UpdateStatus(soup.ID, lookups.ID(lookups.MenuOutOfStock)); err != nil {
+		t.Fatal(err)
+	}
+	if err := shop.store.Menus().Delete(tea.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := NewCartService(shop.store).Get(shopCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[uint]string{}
+	for _, is := range view.Validation.Issues {
+		kinds[is.MenuID] = is.Kind
+	}
+	want := map[uint]string{rice.ID: CartIssuePriceChanged, soup.ID: CartIssueUnavailable, tea.ID: CartIssueRemoved}
+	for id, kind := range want {
+		if kinds[id] != kind {
+			t.Errorf("menu %d: kind = %q, want %q", id, kinds[id], kind)
+		}
+	}
+	if view.Validation.Subtotal != 45 || view.Validation.Dropped() != 2 {
+		t.Fatalf("validation subtotal/dropped = %d/%d, want 45/2", view.Validation.Subtotal, view.Validation.Dropped())
+	}
+}
+
+func TestCartRemoveItemChecksOwner(t *testing.T) {
+	shop := newMemoryShop(t)
+	shop.addToCart(t, shop.menu(t, "ข้าวผัด", 50, nil), 1)
+	cart, _ := shop.store.Carts().GetCartWithItems(shopCustomer)
+	itemID := cart.Items[0].ID
+
+	svc := NewCartService(shop.store)
+	if err := svc.RemoveItem(shopCustomer+1, itemID); !errors.Is(err, ErrCartItemNotFound) {
+		t.Fatalf("other user: err = %v, want ErrCartItemNotFound", err)
+	}
+	if err := svc.RemoveItem(shopCustomer, itemID); err != nil {
+		t.Fatal(err)
+	}
+	cart, _ = shop.store.Carts().GetCartWithItems(shopCustomer)
+	if len(cart.Items) != 0 || cart.RestaurantID != 0 {
+		t.Fatalf("cart after removing last item = %+v", cart)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"backend/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ชนิดของปัญหาใน cart เทียบกับเมนูปัจจุบัน
+const (
+	CartIssuePriceChanged = "price_changed"
+	CartIssueUnavailable  = "unavailable" // Out of Stock
+	CartIssueRemoved      = "removed"     // เมนูถูกลบ / ย้ายร้าน
+)
+
+type CartIssue struct {
+	CartItemID uint   `json:"cartItemId"`
+	MenuID     uint   `json:"menuId"`
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	OldPrice   int64  `json:"oldPrice"`
+	NewPrice   int64  `json:"newPrice,omitempty"`
+}
+
+// CartValidation = ผลตรวจ cart ก่อน checkout
+type CartValidation struct {
+	Issues           []CartIssue `json:"issues"`
+	RestaurantClosed bool        `json:"restaurantClosed"` // ร้านตั้งสถานะ Closed
+	OutsideHours     bool        `json:"outsideHours"`     // อยู่นอกเวลาเปิด-ปิด ณ ตอนนี้
+	Subtotal         int64       `json:"subtotal"`         // คิดจากราคาปัจจุบัน ไม่รวมรายการที่สั่งไม่ได้
+}
+
+// Stale = มีรายการที่ต้องให้ลูกค้ายืนยัน
+func (v *CartValidation) Stale() bool { return len(v.Issues) > 0 }
+
+// Dropped = จำนวนรายการที่สั่งไม่ได้แล้ว (จะถูกลบเมื่อยืนยัน)
+func (v *CartValidation) Dropped() int {
+	n := 0
+	for _, is := range v.Issues {
+		if is.Kind != CartIssuePriceChanged {
+			n++
+		}
+	}
+	return n
+}
+
+// ValidateCart เทียบ snapshot ใน cart กับเมนู/ร้าน ณ ตอนนี้ (ไม่แก้ข้อมูล)
+// loc = โซนเวลาของร้าน ใช้ตรวจเวลาเปิด-ปิด (nil = โซนของเครื่อง)
+func ValidateCart(store repository.Store, cart *entity.Cart, now time.Time, loc *time.Location) (*CartValidation, error) {
+	v := &CartValidation{Issues: []CartIssue{}}
+	if len(cart.Items) == 0 {
+		return v, nil
+	}
+
+	menuIDs := make([]uint, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		menuIDs = append(menuIDs, it.MenuID)
+	}
+	menus, err := store.Menus().FindByIDsWithDeleted(menuIDs)
+	if err != nil {
+		return nil, err
+	}
+	menuByID := make(map[uint]entity.Menu, len(menus))
+	for _, m := range menus {
+		menuByID[m.ID] = m
+	}
+
+	outOfStockID := lookups.ID(lookups.MenuOutOfStock)
+
+	for _, it := range cart.Items {
+		m, ok := menuByID[it.MenuID]
+		issue := CartIssue{CartItemID: it.ID, MenuID: it.MenuID, Name: m.Name, OldPrice: it.UnitPrice}
+		switch {
+		case !ok || m.DeletedAt.Valid || m.RestaurantID != cart.RestaurantID:
+			issue.Kind = CartIssueRemoved
+		case m.MenuStatusID == outOfStockID:
+			issue.Kind = CartIssueUnavailable
+		case m.Price != it.UnitPrice:
+			issue.Kind = CartIssuePriceChanged
+			issue.NewPrice = m.Price
+			v.Subtotal += m.Price * int64(it.Qty)
+		default:
+			v.Subtotal += it.Total
+			continue
+		}
+		v.Issues = append(v.Issues, issue)
+	}
+
+	rest, err := store.Restaurants().FindByID(cart.RestaurantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// ร้านถูกลบไปแล้ว
+		v.RestaurantClosed = true
+		return v, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.RestaurantClosed = rest.RestaurantStatus.StatusName == "Closed"
+	if loc == nil {
+		loc = time.Local
+	}
+	v.OutsideHours = !WithinOpeningHours(*rest, now.In(loc))
+	return v, nil
+}
+
+// ApplyCartValidation ปรับ cart ตามผลตรวจ (อัปเดตราคา, ลบรายการที่สั่งไม่ได้)
+// ใช้หลังลูกค้ากดยืนยันการเปลี่ยนแปลงแล้ว
+func ApplyCartValidation(carts repository.CartStore, cart *entity.Cart, v *CartValidation) error {
+	byItem := make(map[uint]CartIssue, len(v.Issues))
+	for _, is := range v.Issues {
+		byItem[is.CartItemID] = is
+	}
+
+	kept := cart.Items[:0]
+	for _, it := range cart.Items {
+		is, ok := byItem[it.ID]
+		if !ok {
+			kept = append(kept, it)
+			continue
+		}
+		if is.Kind == CartIssuePriceChanged {
+			it.UnitPrice = is.NewPrice
+			it.Total = is.NewPrice * int64(it.Qty)
+			if err := carts.UpdateItemPrice(it.ID, it.UnitPrice, it.Total); err != nil {
+				return err
+			}
+			kept = append(kept, it)
+			continue
+		}
+		if err := carts.DeleteItem(it.ID); err != nil {
+			return err
+		}
+	}
+	cart.Items = kept
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"backend/entity"
+	"backend/lookups"
+	"backend/repository"
+)
+
+func TestValidateCartUsesRestaurantTimezone(t *testing.T) {
+	bkk, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups.LoadDefaults()
+	store := repository.NewMemoryStore()
+	rest := &entity.Restaurant{OpeningTime: "09:00", ClosingTime: "21:00", RestaurantStatusID: lookups.ID(lookups.RestaurantOpen)}
+	store.AddRestaurant(rest)
+	menu := &entity.Menu{Name: "ข้าวผัด", Price: 50, RestaurantID: rest.ID, MenuStatusID: lookups.ID(lookups.MenuAvailable)}
+	if err := store.Menus().Create(menu); err != nil {
+		t.Fatal(err)
+	}
+	cart := &entity.Cart{RestaurantID: rest.ID, Items: []entity.CartItem{{MenuID: menu.ID, Qty: 1, UnitPrice: 50, Total: 50}}}
+
+	for _, tc := range []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want bool
+	}{
+		{"10:00 local (03:00 UTC)", time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC), bkk, false},
+		{"22:00 local (15:00 UTC)", time.Date(2026, 1, 1, 15, 0, 0, 0, time.UTC), bkk, true},
+		{"08:30 local (01:30 UTC)", time.Date(2026, 1, 1, 1, 30, 0, 0, time.UTC), bkk, true},
+		{"server zone UTC", time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC), time.UTC, true},
+	} {
+		// now ในโซน UTC เหมือน container ที่ไม่ได้ตั้ง TZ
+		v, err := ValidateCart(store, cart, tc.now, tc.loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.OutsideHours != tc.want {
+			t.Errorf("%s: OutsideHours = %v, want %v", tc.name, v.OutsideHours, tc.want)
+		}
+	}
+}
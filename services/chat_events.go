@@ -0,0 +1,50 @@
+package services
+
+import (
+	"backend/entity"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// ChatChannel = channel ของ pubsub ที่ทุก instance subscribe ไว้กระจายแชทให้ WebSocket ที่ต่อกับตัวเอง
+const ChatChannel = "chat:events"
+
+// ประเภทของ ChatEvent
+const (
+	ChatEventMessage = "message" // ข้อความใหม่
+	ChatEventRead    = "read"    // UserID อ่านถึง LastReadMessageID แล้ว
+	ChatEventTyping  = "typing"  // UserID เริ่ม/หยุดพิมพ์ (ไม่บันทึก; client ควรซ่อนเองถ้าไม่ได้ยินซ้ำภายใน TypingTTL)
+)
+
+// TypingTTL = อายุของสถานะ "กำลังพิมพ์" ฝั่ง client (client ส่ง typing ซ้ำได้ไม่ถี่กว่า TypingTTL/2)
+const TypingTTL = 5 * time.Second
+
+// ChatEvent = สิ่งที่ส่งผ่าน broker ไปยังทุก instance (hub เลือกส่งให้ client ในห้อง RoomID)
+// ข้อความใหม่ส่งถึง client เป็น entity.Message ตรง ๆ (แบบเดิม) ส่วน event อื่นส่งทั้งก้อนนี้ (ดูจาก type)
+type ChatEvent struct {
+	Type    string          `json:"type"`
+	RoomID  uint            `json:"roomId"`
+	UserID  uint            `json:"userId,omitempty"` // คนที่อ่าน / กำลังพิมพ์
+	Message *entity.Message `json:"message,omitempty"`
+
+	LastReadMessageID uint       `json:"lastReadMessageId,omitempty"`
+	ReadAt            *time.Time `json:"readAt,omitempty"`
+	Typing            *bool      `json:"typing,omitempty"`
+}
+
+// publish ส่ง event ให้ทุก instance (ไม่มี broker = ไม่กระจาย realtime; ข้อความยังถูกบันทึกแล้ว)
+func (s *ChatService) publish(ctx context.Context, ev ChatEvent) {
+	if s.Broker == nil {
+		return
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		slog.ErrorContext(ctx, "chat: encode event failed", "type", ev.Type, "error", err)
+		return
+	}
+	if err := s.Broker.Publish(ctx, ChatChannel, data); err != nil {
+		slog.WarnContext(ctx, "chat: publish failed", "type", ev.Type, "error", err)
+	}
+}
@@ -2,19 +2,35 @@ package services
 
 import (
 	"backend/entity"
+	"backend/lookups"
+	"backend/pubsub"
 	"backend/repository"
+	"context"
 	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	ErrEmptyMessage    = errors.New("message body cannot be empty")
+)
+
 type ChatService struct {
 	Repo *repository.ChatRepository
 	DB   *gorm.DB
+	Push *PushService
+
+	// กระจายข้อความใหม่ให้ WebSocket ของทุก instance (ดู ChatChannel; nil = ไม่กระจาย)
+	Broker pubsub.Broker
 }
 
-func NewChatService(db *gorm.DB, repo *repository.ChatRepository) *ChatService {
-	return &ChatService{Repo: repo, DB: db}
+func NewChatService(db *gorm.DB, repo *repository.ChatRepository, push *PushService) *ChatService {
+	return &ChatService{Repo: repo, DB: db, Push: push}
 }
 
 // ---------------------- Rooms ----------------------
@@ -37,9 +53,12 @@ func (s *ChatService) GetMessages(roomID uint) ([]entity.Message, error) {
 }
 
 // ส่งข้อความใหม่
-func (s *ChatService) SendMessage(roomID, userID, typeMsgID uint, body string) (*entity.Message, error) {
+func (s *ChatService) SendMessage(ctx context.Context, roomID, userID, typeMsgID uint, body string) (*entity.Message, error) {
 	if body == "" {
-		return nil, errors.New("message body cannot be empty")
+		return nil, ErrEmptyMessage
+	}
+	if typeMsgID == 0 {
+		typeMsgID = lookups.ID(lookups.MessageText) // client ไม่ระบุ = ข้อความธรรมดา
 	}
 
 	msg := &entity.Message{
@@ -52,30 +71,179 @@ func (s *ChatService) SendMessage(roomID, userID, typeMsgID uint, body string) (
 	if err := s.Repo.CreateMessage(msg); err != nil {
 		return nil, err
 	}
+
+	s.publish(ctx, ChatEvent{Type: ChatEventMessage, RoomID: roomID, Message: msg})
+	s.notifyParticipants(ctx, msg)
 	return msg, nil
 }
 
+// แจ้งเตือนอีกฝั่งของห้อง (ลูกค้า <-> rider) ยกเว้นคนส่ง
+func (s *ChatService) notifyParticipants(ctx context.Context, msg *entity.Message) {
+	if s.Push == nil {
+		return
+	}
+	room, err := s.Repo.FindRoomByID(msg.RoomID)
+	if err != nil {
+		return
+	}
+	order, err := s.Repo.FindOrderWithRider(room.OrderID)
+	if err != nil {
+		return
+	}
+
+	var sender entity.User
+	s.DB.Select("id, first_name, last_name").First(&sender, msg.UserSenderID)
+
+	preview := msg.Body
+	if r := []rune(preview); len(r) > 80 {
+		preview = string(r[:80]) + "…"
+	}
+	data := map[string]string{
+		"orderId":    strconv.FormatUint(uint64(order.ID), 10),
+		"roomId":     strconv.FormatUint(uint64(room.ID), 10),
+		"senderName": strings.TrimSpace(sender.FirstName + " " + sender.LastName),
+		"preview":    preview,
+	}
+
+	recipients := map[uint]bool{order.UserID: true}
+	for _, rw := range order.RiderWork {
+		recipients[rw.Rider.UserID] = true
+	}
+	delete(recipients, msg.UserSenderID)
+	for uid := range recipients {
+		s.Push.Notify(ctx, uid, NotifyChatMessage, data)
+	}
+}
+
+// ---------------------- Read receipts / typing ----------------------
+
+// MarkRead เลื่อน read receipt ของ user ถึง messageID (0 = ข้อความล่าสุดในห้อง) แล้วแจ้งทุกคนในห้องเมื่อขยับจริง
+// ไม่ถอยหลัง: ส่ง id ที่เก่ากว่าที่อ่านไปแล้ว = คืน state เดิม
+func (s *ChatService) MarkRead(ctx context.Context, roomID, userID, messageID uint) (*entity.ChatRead, error) {
+	if messageID == 0 {
+		latest, err := s.Repo.LatestMessageID(roomID)
+		if err != nil {
+			return nil, err
+		}
+		messageID = latest
+	} else if _, err := s.Repo.FindMessageInRoom(roomID, messageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	read, moved, err := s.Repo.AdvanceRead(roomID, userID, messageID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if moved { // อ่านซ้ำ / ไม่ได้ขยับ = ไม่ต้องแจ้งใคร
+		s.publish(ctx, ChatEvent{Type: ChatEventRead, RoomID: roomID, UserID: userID,
+			LastReadMessageID: read.LastReadMessageID, ReadAt: &read.ReadAt})
+	}
+	return read, nil
+}
+
+// SetTyping แจ้งคนอื่นในห้องว่า user กำลังพิมพ์/หยุดพิมพ์ (ไม่บันทึกลง DB)
+func (s *ChatService) SetTyping(ctx context.Context, roomID, userID uint, typing bool) {
+	s.publish(ctx, ChatEvent{Type: ChatEventTyping, RoomID: roomID, UserID: userID, Typing: &typing})
+}
+
+// GetReads = read receipt ของทุกคนในห้อง
+func (s *ChatService) GetReads(roomID uint) ([]entity.ChatRead, error) {
+	return s.Repo.FindReads(roomID)
+}
+
+// UnreadCount = ข้อความจากคนอื่นในห้องที่ user ยังไม่อ่าน
+func (s *ChatService) UnreadCount(roomID, userID uint) (int64, error) {
+	counts, err := s.Repo.UnreadCounts(userID, []uint{roomID})
+	return counts[roomID], err
+}
+
+// ChatRoomSummary = ห้องใน /chatrooms
+type ChatRoomSummary struct {
+	RoomID            uint            `json:"roomId"`
+	OrderID           uint            `json:"orderId"`
+	LastMessage       *entity.Message `json:"lastMessage"`
+	UnreadCount       int64           `json:"unreadCount"`
+	LastReadMessageID uint            `json:"lastReadMessageId"`
+}
+
+// ListRooms = ห้องแชทที่ user เป็นลูกค้าหรือไรเดอร์ เรียงตามข้อความล่าสุด
+// includeFinished = false เอาเฉพาะ order ที่ยังไม่จบ (ไม่ใช่ Completed / Cancelled)
+func (s *ChatService) ListRooms(userID uint, includeFinished bool) ([]ChatRoomSummary, error) {
+	var exclude []uint
+	if !includeFinished {
+		exclude = []uint{lookups.ID(lookups.OrderCompleted), lookups.ID(lookups.OrderCancelled)}
+	}
+	rooms, err := s.Repo.FindRoomsForUser(userID, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(rooms))
+	for i, r := range rooms {
+		ids[i] = r.ID
+	}
+	last, err := s.Repo.LastMessages(ids)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.Repo.UnreadCounts(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	lastRead, err := s.Repo.LastReadIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ChatRoomSummary, len(rooms))
+	for i, r := range rooms {
+		out[i] = ChatRoomSummary{
+			RoomID:            r.ID,
+			OrderID:           r.OrderID,
+			UnreadCount:       unread[r.ID],
+			LastReadMessageID: lastRead[r.ID],
+		}
+		if m, ok := last[r.ID]; ok {
+			out[i].LastMessage = &m
+		}
+	}
+	// ห้องที่มีข้อความล่าสุดใหม่กว่าขึ้นก่อน; ห้องที่ยังไม่มีข้อความเรียงตามห้องใหม่ก่อน (ลำดับจาก repo)
+	sort.SliceStable(out, func(i, j int) bool {
+		return lastMessageID(out[i]) > lastMessageID(out[j])
+	})
+	return out, nil
+}
+
+func lastMessageID(r ChatRoomSummary) uint {
+	if r.LastMessage == nil {
+		return 0
+	}
+	return r.LastMessage.ID
+}
+
 // ---------------------- Permissions ----------------------
 
 // ตรวจสอบว่า user มีสิทธิ์เข้าถึงห้อง (customer + rider)
 func (s *ChatService) CanAccessRoom(userID, orderID uint) (bool, error) {
-    order, err := s.Repo.FindOrderWithRider(orderID)
-    if err != nil {
-        return false, err
-    }
-
-    // ลูกค้าเจ้าของ order
-    if order.UserID == userID {
-        return true, nil
-    }
-
-    // Rider ของ order → เทียบ userID
-    for _, rw := range order.RiderWork {
-        if rw.Rider.UserID == userID {
-            return true, nil
-        }
-    }
-
-    return false, nil
-}
+	order, err := s.Repo.FindOrderWithRider(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	// ลูกค้าเจ้าของ order
+	if order.UserID == userID {
+		return true, nil
+	}
 
+	// Rider ของ order → เทียบ userID
+	for _, rw := range order.RiderWork {
+		if rw.Rider.UserID == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"backend/repository"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotRestaurantOwner = errors.New("forbidden")
+	ErrInvalidQuota       = errors.New("dailyQuota must be >= 0")
+	ErrInvalidStock       = errors.New("dailyQuota and stockRemaining must be >= 0")
+)
+
+// MenuStockUpdate = ตั้งสต็อกของเมนูหนึ่งรายการ (bulk update)
+type MenuStockUpdate struct {
+	MenuID          uint
+	DailyQuota      *int // ตั้ง/เปลี่ยนโควต้ารายวัน
+	StockRemaining  *int // nil = เติมเต็มโควต้า
+	DisableTracking bool // true = เลิก track stock เมนูนี้
+}
+
+type MenuService struct {
+	Store repository.Store
+}
+
+func NewMenuService(store repository.Store) *MenuService {
+	return &MenuService{Store: store}
+}
+
+func (s *MenuService) ListByRestaurant(restID uint) ([]entity.Menu, error) {
+	return s.Store.Menus().FindByRestaurant(restID)
+}
+
+func (s *MenuService) Get(id uint) (*entity.Menu, error) {
+	menu, err := s.Store.Menus().FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMenuNotFound
+	}
+	return menu, err
+}
+
+// Create เพิ่มเมนูให้ร้านของเจ้าของร้าน
+func (s *MenuService) Create(userID, restID uint, menu *entity.Menu) error {
+	if err := s.ensureOwner(restID, userID); err != nil {
+		return err
+	}
+	menu.RestaurantID = restID
+
+	// เปิด track stock ตั้งแต่สร้าง → เริ่มด้วยสต็อกเต็มโควต้า
+	if menu.DailyQuota != nil {
+		if *menu.DailyQuota < 0 {
+			return ErrInvalidQuota
+		}
+		now := time.Now()
+		menu.StockRemaining = *menu.DailyQuota
+		menu.StockResetAt = &now
+	}
+	return s.Store.Menus().Create(menu)
+}
+
+// Update แก้ข้อมูลเมนู (ไม่แตะฟิลด์สต็อก)
+func (s *MenuService) Update(userID uint, menu *entity.Menu) error {
+	if _, err := s.ownedMenu(userID, menu.ID); err != nil {
+		return err
+	}
+	return s.Store.Menus().Update(menu)
+}
+
+func (s *MenuService) Delete(userID, id uint) error {
+	if _, err := s.ownedMenu(userID, id); err != nil {
+		return err
+	}
+	return s.Store.Menus().Delete(id)
+}
+
+func (s *MenuService) UpdateStatus(userID, id, statusID uint) error {
+	if _, err := s.ownedMenu(userID, id); err != nil {
+		return err
+	}
+	return s.Store.Menus().UpdateStatus(id, statusID)
+}
+
+// BulkUpdateStock ตั้งโควต้า/สต็อกหลายเมนูของร้านใน transaction เดียว
+func (s *MenuService) BulkUpdateStock(userID, restID uint, items []MenuStockUpdate) ([]entity.Menu, error) {
+	if err := s.ensureOwner(restID, userID); err != nil {
+		return nil, err
+	}
+
+	availableID := lookups.ID(lookups.MenuAvailable)
+	outOfStockID := lookups.ID(lookups.MenuOutOfStock)
+
+	updated := make([]entity.Menu, 0, len(items))
+	err := s.Store.Transaction(func(tx repository.Store) error {
+		menus := tx.Menus()
+		for _, in := range items {
+			menu, err := menus.FindByID(in.MenuID)
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && menu.RestaurantID != restID) {
+				return ErrMenuNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			fields := map[string]any{}
+			if in.DisableTracking {
+				fields["daily_quota"] = nil
+				fields["stock_remaining"] = 0
+				if menu.AutoOutOfStock {
+					fields["menu_status_id"] = availableID
+					fields["auto_out_of_stock"] = false
+				}
+			} else {
+				quota := menu.DailyQuota
+				if in.DailyQuota != nil {
+					quota = in.DailyQuota
+				}
+				if quota == nil || *quota < 0 {
+					return ErrInvalidStock
+				}
+				stock := *quota
+				if in.StockRemaining != nil {
+					stock = *in.StockRemaining
+				}
+				if stock < 0 {
+					return ErrInvalidStock
+				}
+				fields["daily_quota"] = *quota
+				fields["stock_remaining"] = stock
+				fields["stock_reset_at"] = time.Now()
+
+				// สถานะตามสต็อกใหม่ (ไม่ทับที่เจ้าของร้านปิดเองด้วยมือ)
+				switch {
+				case stock == 0 && menu.MenuStatusID != outOfStockID:
+					fields["menu_status_id"] = outOfStockID
+					fields["auto_out_of_stock"] = true
+				case stock > 0 && menu.AutoOutOfStock:
+					fields["menu_status_id"] = availableID
+					fields["auto_out_of_stock"] = false
+				}
+			}
+
+			if err := menus.UpdateFields(menu.ID, fields); err != nil {
+				return err
+			}
+			fresh, err := menus.FindByID(menu.ID)
+			if err != nil {
+				return err
+			}
+			updated = append(updated, *fresh)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ---------------- Helper ----------------
+
+func (s *MenuService) ensureOwner(restID, userID uint) error {
+	ok, err := s.Store.Restaurants().IsOwner(restID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotRestaurantOwner
+	}
+	return nil
+}
+
+// เมนูที่อยู่ในร้านของ user นี้
+func (s *MenuService) ownedMenu(userID, id uint) (*entity.Menu, error) {
+	menu, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureOwner(menu.RestaurantID, userID); err != nil {
+		return nil, err
+	}
+	return menu, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"backend/lookups"
+)
+
+func TestBulkUpdateStockTogglesStatus(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, intPtr(10))
+	svc := NewMenuService(shop.store)
+
+	if _, err := svc.BulkUpdateStock(shopCustomer, shop.rest.ID, []MenuStockUpdate{{MenuID: rice.ID, StockRemaining: intPtr(0)}}); !errors.Is(err, ErrNotRestaurantOwner) {
+		t.Fatalf("non-owner: err = %v, want ErrNotRestaurantOwner", err)
+	}
+
+	// สต็อกเหลือ 0 → ระบบปิดขายเอง
+	got, err := svc.BulkUpdateStock(shopOwnerID, shop.rest.ID, []MenuStockUpdate{{MenuID: rice.ID, StockRemaining: intPtr(0)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].MenuStatusID != lookups.ID(lookups.MenuOutOfStock) || !got[0].AutoOutOfStock {
+		t.Fatalf("after stock 0: status=%d auto=%v", got[0].MenuStatusID, got[0].AutoOutOfStock)
+	}
+
+	// เติมเต็มโควต้าใหม่ → เปิดขายกลับ
+	got, err = svc.BulkUpdateStock(shopOwnerID, shop.rest.ID, []MenuStockUpdate{{MenuID: rice.ID, DailyQuota: intPtr(20)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].StockRemaining != 20 || got[0].MenuStatusID != lookups.ID(lookups.MenuAvailable) || got[0].AutoOutOfStock {
+		t.Fatalf("after refill: %+v", got[0])
+	}
+}
+
+func TestBulkUpdateStockIsAllOrNothing(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, intPtr(10))
+	soup := shop.menu(t, "ต้มยำ", 80, intPtr(5))
+
+	_, err := NewMenuService(shop.store).BulkUpdateStock(shopOwnerID, shop.rest.ID, []MenuStockUpdate{
+		{MenuID: rice.ID, StockRemaining: intPtr(3)},
+		{MenuID: soup.ID, StockRemaining: intPtr(-1)},
+	})
+	if !errors.Is(err, ErrInvalidStock) {
+		t.Fatalf("err = %v, want ErrInvalidStock", err)
+	}
+	if m, _ := shop.store.Menus().FindByID(rice.ID); m.StockRemaining != 10 {
+		t.Fatalf("rice stock = %d, want 10 (rolled back)", m.StockRemaining)
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"backend/entity"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// InboxService เก็บการแจ้งเตือนลงกล่องข้อความในแอป (ไม่ขึ้นกับการตั้งค่า push)
+type InboxService struct {
+	DB *gorm.DB
+}
+
+func NewInboxService(db *gorm.DB) *InboxService {
+	return &InboxService{DB: db}
+}
+
+// ประเภทที่ไม่ต้องเก็บลงกล่อง (แชทมีห้องของมันเองอยู่แล้ว)
+var inboxSkipKinds = map[string]bool{
+	NotifyChatMessage: true,
+}
+
+// deep link ตามประเภท
+func notificationLink(kind string, data map[string]string) string {
+	switch notifyKinds[kind].Category {
+	case notifyCategoryOrder:
+		if id := data["orderId"]; id != "" {
+			return "/orders/" + id
+		}
+	case notifyCategoryReport:
+		if id := data["reportId"]; id != "" {
+			return "/reports/" + id
+		}
+	case notifyCategoryApplication:
+		return "/applications"
+	}
+	return ""
+}
+
+// Create บันทึกการแจ้งเตือน 1 รายการ (render ตามภาษาที่ผู้ใช้ตั้งไว้)
+func (s *InboxService) Create(userID uint, kind string, data map[string]string) (*entity.Notification, error) {
+	if _, ok := notifyKinds[kind]; !ok {
+		return nil, ErrUnknownNotifyKind
+	}
+
+	locale := "th"
+	var pref entity.NotificationPreference
+	if err := s.DB.Select("locale").Where("user_id = ?", userID).Limit(1).Find(&pref).Error; err == nil && pref.Locale != "" {
+		locale = pref.Locale
+	}
+
+	title, body, err := RenderNotification(kind, locale, data)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := json.Marshal(data)
+
+	n := &entity.Notification{
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+		Link:   notificationLink(kind, data),
+		Data:   string(raw),
+		UserID: userID,
+	}
+	if err := s.DB.Create(n).Error; err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// List คืนรายการของผู้ใช้ (ใหม่สุดก่อน) พร้อมจำนวนทั้งหมด
+func (s *InboxService) List(userID uint, unreadOnly bool, page, limit int) ([]entity.Notification, int64, error) {
+	q := s.DB.Model(&entity.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("is_read = ?", false)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []entity.Notification
+	if err := q.Order("id DESC").Limit(limit).Offset((page - 1) * limit).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (s *InboxService) UnreadCount(userID uint) (int64, error) {
+	var n int64
+	err := s.DB.Model(&entity.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&n).Error
+	return n, err
+}
+
+// MarkRead ทำเครื่องหมายว่าอ่านแล้ว (ของตัวเองเท่านั้น)
+func (s *InboxService) MarkRead(userID, id uint) error {
+	res := s.DB.Model(&entity.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]any{"is_read": true, "read_at": gorm.Expr("COALESCE(read_at, ?)", time.Now())})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead คืนจำนวนรายการที่เพิ่งถูกอ่าน
+func (s *InboxService) MarkAllRead(userID uint) (int64, error) {
+	res := s.DB.Model(&entity.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]any{"is_read": true, "read_at": time.Now()})
+	return res.RowsAffected, res.Error
+}
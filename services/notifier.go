@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PushMessage = ข้อความ push 1 ชิ้นสำหรับอุปกรณ์ 1 เครื่อง
+type PushMessage struct {
+	Token    string
+	Platform string
+	Title    string
+	Body     string
+	Data     map[string]string
+}
+
+// Notifier = ผู้ให้บริการส่ง push (FCM / APNs / log / fake)
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, msg PushMessage) error
+}
+
+// provider ตอบกลับว่า token นี้ใช้ไม่ได้แล้ว → ควรลบทิ้ง
+var ErrInvalidDeviceToken = errors.New("invalid device token")
+
+// ---------------------- FCM ----------------------
+
+// FCMServiceAccount = ฟิลด์ที่ใช้จากไฟล์ service account JSON ที่ดาวน์โหลดจาก Firebase console
+type FCMServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMNotifier ส่งผ่าน FCM HTTP v1 (OAuth2 access token จาก service account)
+type FCMNotifier struct {
+	ProjectID  string
+	Endpoint   string // ไม่รวม path: {Endpoint}/v1/projects/{ProjectID}/messages:send
+	HTTPClient *http.Client
+
+	account FCMServiceAccount
+	key     *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMNotifier อ่าน service account JSON; projectID ว่าง = ใช้ project_id ในไฟล์
+func NewFCMNotifier(credentialsJSON []byte, projectID string) (*FCMNotifier, error) {
+	var sa FCMServiceAccount
+	if err := json.Unmarshal(credentialsJSON, &sa); err != nil {
+		return nil, fmt.Errorf("fcm: parse credentials: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, errors.New("fcm: credentials must contain client_email and private_key")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm: parse private key: %w", err)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if projectID == "" {
+		projectID = sa.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm: project id is required")
+	}
+	return &FCMNotifier{
+		ProjectID:  projectID,
+		Endpoint:   "https://fcm.googleapis.com",
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		account:    sa,
+		key:        key,
+	}, nil
+}
+
+func (n *FCMNotifier) Name() string { return "fcm" }
+
+// token คืน access token ที่ cache ไว้ (ขอใหม่เมื่อเหลืออายุไม่ถึง 1 นาที)
+func (n *FCMNotifier) token(ctx context.Context) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.accessToken != "" && time.Until(n.expiresAt) > time.Minute {
+		return n.accessToken, nil
+	}
+
+	// OAuth2 JWT bearer grant: sign assertion ด้วย private key ของ service account
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   n.account.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   n.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(n.key)
+	if err != nil {
+		return "", fmt.Errorf("fcm: sign assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("fcm: token status %d: %s", resp.StatusCode, string(b))
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.AccessToken == "" {
+		return "", fmt.Errorf("fcm: invalid token response: %v", err)
+	}
+	n.accessToken = out.AccessToken
+	n.expiresAt = now.Add(time.Duration(out.ExpiresIn) * time.Second)
+	return n.accessToken, nil
+}
+
+func (n *FCMNotifier) Send(ctx context.Context, msg PushMessage) error {
+	token, err := n.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token": msg.Token,
+			"notification": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+			"data": msg.Data,
+		},
+	})
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", n.Endpoint, url.PathEscape(n.ProjectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var out struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&out)
+	for _, d := range out.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidDeviceToken
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		// token ถูกเพิกถอน → ครั้งหน้าขอใหม่
+		n.mu.Lock()
+		n.accessToken = ""
+		n.mu.Unlock()
+	}
+	return fmt.Errorf("fcm status %d: %s %s", resp.StatusCode, out.Error.Status, out.Error.Message)
+}
+
+// ---------------------- APNs ----------------------
+
+// APNsNotifier ส่งผ่าน APNs HTTP/2 (token-based auth; AuthToken คือ JWT ที่ออกไว้แล้ว)
+type APNsNotifier struct {
+	AuthToken  string
+	Topic      string // bundle id ของแอป
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+func NewAPNsNotifier(authToken, topic string, sandbox bool) *APNsNotifier {
+	endpoint := "https://api.push.apple.com"
+	if sandbox {
+		endpoint = "https://api.sandbox.push.apple.com"
+	}
+	return &APNsNotifier{
+		AuthToken:  authToken,
+		Topic:      topic,
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *APNsNotifier) Name() string { return "apns" }
+
+func (n *APNsNotifier) Send(ctx context.Context, msg PushMessage) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, _ := json.Marshal(payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Endpoint+"/3/device/"+msg.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+n.AuthToken)
+	req.Header.Set("apns-topic", n.Topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusGone:
+		return ErrInvalidDeviceToken
+	default:
+		var out struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		if out.Reason == "BadDeviceToken" || out.Reason == "Unregistered" {
+			return ErrInvalidDeviceToken
+		}
+		return fmt.Errorf("apns status %d: %s", resp.StatusCode, out.Reason)
+	}
+}
+
+// ---------------------- Log / Fake ----------------------
+
+// LogNotifier แค่เขียน log (ใช้ตอน dev ที่ไม่มี key ของ provider)
+type LogNotifier struct{}
+
+func (LogNotifier) Name() string { return "log" }
+
+func (LogNotifier) Send(ctx context.Context, msg PushMessage) error {
+	slog.InfoContext(ctx, "push (log provider)", "platform", msg.Platform, "title", msg.Title, "body", msg.Body, "data", msg.Data)
+	return nil
+}
+
+// FakeNotifier เก็บข้อความที่ส่งไว้ใน memory ให้ตรวจได้ตอนทดสอบแบบ offline
+type FakeNotifier struct {
+	mu   sync.Mutex
+	sent []PushMessage
+
+	// ถ้าตั้งไว้ จะคืน error นี้ทุกครั้ง (จำลอง provider ล่ม / token เสีย)
+	Err error
+}
+
+func (f *FakeNotifier) Name() string { return "fake" }
+
+func (f *FakeNotifier) Send(_ context.Context, msg PushMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+// Sent คืนสำเนาข้อความที่ส่งไปแล้วทั้งหมด
+func (f *FakeNotifier) Sent() []PushMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]PushMessage, len(f.sent))
+	copy(out, f.sent)
+	return out
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestFCMNotifierSendsV1WithServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	var tokenCalls, sendCalls atomic.Int32
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", r.FormValue("grant_type"))
+		}
+		claims := jwt.MapClaims{}
+		if _, err := jwt.ParseWithClaims(r.FormValue("assertion"), claims, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"})); err != nil {
+			t.Errorf("assertion: %v", err)
+		}
+		if claims["iss"] != "push@demo.iam.gserviceaccount.com" || claims["aud"] != srv.URL+"/token" {
+			t.Errorf("claims = %v", claims)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "ya29.test", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v1/projects/demo-project/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		sendCalls.Add(1)
+		if got := r.Header.Get("Authorization"); got != "Bearer ya29.test" {
+			t.Errorf("authorization = %q", got)
+		}
+		var body struct {
+			Message struct {
+				Token        string            `json:"token"`
+				Notification map[string]string `json:"notification"`
+				Data         map[string]string `json:"data"`
+			} `json:"message"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Message.Token == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`))
+			return
+		}
+		if body.Message.Notification["title"] != "สวัสดี" || body.Message.Data["orderId"] != "7" {
+			t.Errorf("message = %+v", body.Message)
+		}
+		_, _ = w.Write([]byte(`{"name":"projects/demo-project/messages/1"}`))
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	creds, _ := json.Marshal(FCMServiceAccount{
+		ProjectID:   "demo-project",
+		ClientEmail: "push@demo.iam.gserviceaccount.com",
+		PrivateKey:  string(pemKey),
+		TokenURI:    srv.URL + "/token",
+	})
+	n, err := NewFCMNotifier(creds, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Endpoint = srv.URL
+
+	ctx := context.Background()
+	msg := PushMessage{Token: "device-1", Title: "สวัสดี", Body: "ทดสอบ", Data: map[string]string{"orderId": "7"}}
+	for i := 0; i < 2; i++ {
+		if err := n.Send(ctx, msg); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	msg.Token = "gone"
+	if err := n.Send(ctx, msg); !errors.Is(err, ErrInvalidDeviceToken) {
+		t.Fatalf("unregistered token: err = %v, want ErrInvalidDeviceToken", err)
+	}
+
+	if got := tokenCalls.Load(); got != 1 {
+		t.Errorf("token requests = %d, want 1 (cached)", got)
+	}
+	if got := sendCalls.Load(); got != 3 {
+		t.Errorf("send requests = %d, want 3", got)
+	}
+}
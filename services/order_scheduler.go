@@ -0,0 +1,142 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// สั่งล่วงหน้าได้ไม่เกินกี่วัน
+const MaxScheduleAhead = 7 * 24 * time.Hour
+
+var (
+	ErrScheduleTooSoon      = errors.New("scheduled time is earlier than the restaurant lead time")
+	ErrScheduleTooFar       = errors.New("scheduled time is too far in the future")
+	ErrScheduleOutsideHours = errors.New("scheduled time is outside restaurant opening hours")
+)
+
+// parseClock แปลง "HH:MM" เป็นจำนวนนาทีนับจากเที่ยงคืน
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// WithinOpeningHours เช็คว่าเวลา at อยู่ในช่วงเปิดร้าน (รองรับร้านที่ปิดข้ามเที่ยงคืน)
+// ร้านที่ไม่ได้ตั้งเวลาไว้ถือว่าเปิดตลอด
+func WithinOpeningHours(rest entity.Restaurant, at time.Time) bool {
+	if rest.OpeningTime == "" || rest.ClosingTime == "" {
+		return true
+	}
+	open, err1 := parseClock(rest.OpeningTime)
+	closing, err2 := parseClock(rest.ClosingTime)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	m := at.Hour()*60 + at.Minute()
+	if open <= closing {
+		return m >= open && m <= closing
+	}
+	return m >= open || m <= closing
+}
+
+// ValidateSchedule ตรวจเวลาที่ลูกค้าต้องการรับอาหาร
+// loc = โซนเวลาของร้าน (เวลาเปิด-ปิดเป็นเวลาท้องถิ่นของร้าน ไม่ใช่ของ server)
+func ValidateSchedule(rest entity.Restaurant, at, now time.Time, loc *time.Location) error {
+	lead := time.Duration(rest.LeadTimeMinutes) * time.Minute
+	if at.Before(now.Add(lead)) {
+		return ErrScheduleTooSoon
+	}
+	if at.After(now.Add(MaxScheduleAhead)) {
+		return ErrScheduleTooFar
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	local := at.In(loc)
+	if !WithinOpeningHours(rest, local) || !WithinOpeningHours(rest, local.Add(-lead)) {
+		return ErrScheduleOutsideHours
+	}
+	return nil
+}
+
+// OrderScheduler ปล่อย order ที่สั่งล่วงหน้าเข้าคิว Pending ของร้าน
+// เมื่อถึงเวลา scheduledFor - lead time (ให้ร้านมีเวลาเตรียม)
+type OrderScheduler struct {
+	DB       *gorm.DB
+	Webhooks *WebhookService
+	Push     *PushService
+	Interval time.Duration
+}
+
+func NewOrderScheduler(db *gorm.DB, webhooks *WebhookService, push *PushService) *OrderScheduler {
+	return &OrderScheduler{DB: db, Webhooks: webhooks, Push: push, Interval: 30 * time.Second}
+}
+
+// Run วนเช็คจนกว่า ctx จะถูกยกเลิก
+func (s *OrderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.ReleaseDue(ctx, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "scheduler: release failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "scheduler: released scheduled orders", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReleaseDue ย้าย order ที่ถึงเวลาแล้วจาก Scheduled → Pending คืนจำนวนที่ปล่อย
+func (s *OrderScheduler) ReleaseDue(ctx context.Context, now time.Time) (int, error) {
+	scheduledID := lookups.ID(lookups.OrderScheduled)
+	pendingID := lookups.ID(lookups.OrderPending)
+
+	// lead time ต่างกันต่อร้าน → ดึงที่อาจถึงเวลาใน MaxScheduleAhead แล้วกรองต่อใน Go
+	var orders []entity.Order
+	if err := s.DB.Preload("Restaurant").
+		Where("order_status_id = ? AND scheduled_for <= ?", scheduledID, now.Add(MaxScheduleAhead)).
+		Order("scheduled_for ASC").
+		Find(&orders).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, o := range orders {
+		lead := time.Duration(o.Restaurant.LeadTimeMinutes) * time.Minute
+		if o.ScheduledFor == nil || o.ScheduledFor.Add(-lead).After(now) {
+			continue
+		}
+
+		// เงื่อนไข status กันชนกับลูกค้ากดยกเลิกพร้อมกัน
+		res := s.DB.Model(&entity.Order{}).
+			Where("id = ? AND order_status_id = ?", o.ID, scheduledID).
+			Updates(map[string]any{"order_status_id": pendingID, "released_at": now})
+		if res.Error != nil {
+			return released, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		released++
+
+		// ร้านเห็น order ตอนถูกปล่อย ไม่ใช่ตอนลูกค้าสั่ง
+		s.Webhooks.DispatchOrderEvent(ctx, o.ID, WebhookOrderCreated)
+		s.Push.NotifyOrder(ctx, o.ID, NotifyNewOrder)
+	}
+	return released, nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/entity"
+)
+
+func TestValidateScheduleUsesRestaurantTimezone(t *testing.T) {
+	bkk, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest := entity.Restaurant{OpeningTime: "09:00", ClosingTime: "21:00", LeadTimeMinutes: 30}
+	now := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC) // 08:00 ที่ร้าน
+
+	for _, tc := range []struct {
+		name string
+		at   time.Time
+		want error
+	}{
+		{"10:00 local", time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC), nil},
+		{"22:00 local (15:00 UTC)", time.Date(2026, 1, 1, 15, 0, 0, 0, time.UTC), ErrScheduleOutsideHours},
+		{"09:15 local, prep starts before opening", time.Date(2026, 1, 1, 2, 15, 0, 0, time.UTC), ErrScheduleOutsideHours},
+		{"20:30 local (13:30 UTC)", time.Date(2026, 1, 1, 13, 30, 0, 0, time.UTC), nil},
+	} {
+		// now ในโซน UTC เหมือน container ที่ไม่ได้ตั้ง TZ
+		if err := ValidateSchedule(rest, tc.at, now, bkk); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
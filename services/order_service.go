@@ -0,0 +1,454 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"backend/metrics"
+	"backend/repository"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrItemsRequired       = errors.New("items required")
+	ErrMenuNotFound        = errors.New("menu not found")
+	ErrMenuNotInRestaurant = errors.New("menu not in this restaurant")
+	ErrRestaurantNotFound  = errors.New("restaurant not found")
+	ErrCartEmpty           = errors.New("cart empty")
+	ErrCartChanged         = errors.New("cart changed, please review and confirm")
+	ErrRestaurantClosed    = errors.New("restaurant is closed")
+	ErrNoItemsAvailable    = errors.New("no items available to order")
+	ErrOrderReleased       = errors.New("order already released to restaurant")
+	ErrNothingToReorder    = errors.New("no items available to reorder")
+	ErrOrderNotPreparing   = errors.New("order not in preparing state")
+)
+
+// CartConflictError = ตะกร้ามีของร้านอื่นอยู่ ต้องให้ลูกค้ายืนยันก่อนล้าง
+type CartConflictError struct {
+	RestaurantID uint
+}
+
+func (e *CartConflictError) Error() string {
+	return "cart contains items from another restaurant"
+}
+
+// ---------------- Input / Output ----------------
+
+type OrderLine struct {
+	MenuID uint
+	Qty    int
+	Note   string
+}
+
+// PlaceOrderInput ใช้ร่วมกันทั้งสั่งตรง (Lines) และ checkout จาก cart
+type PlaceOrderInput struct {
+	UserID        uint
+	RestaurantID  uint        // ใช้เฉพาะสั่งตรง (checkout ใช้ร้านใน cart)
+	Lines         []OrderLine // ใช้เฉพาะสั่งตรง
+	Address       string
+	PaymentMethod string // "PromptPay" | "Cash on Delivery"
+	Discount      *int64
+	DeliveryFee   *int64
+	ScheduledFor  *time.Time
+
+	// checkout: ลูกค้ายืนยันการเปลี่ยนแปลงจากผลตรวจ cart แล้ว
+	ConfirmChanges bool
+}
+
+type OrderDetail struct {
+	Order   *entity.Order
+	Items   []entity.OrderItem
+	Payment *entity.Payment // payment ล่าสุด (อาจไม่มี)
+}
+
+type ReorderChanged struct {
+	MenuID   uint   `json:"menuId"`
+	Name     string `json:"name"`
+	OldPrice int64  `json:"oldPrice"`
+	NewPrice int64  `json:"newPrice"`
+}
+
+type ReorderDropped struct {
+	MenuID uint   `json:"menuId"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"` // out_of_stock | deleted
+}
+
+type ReorderResult struct {
+	CartID  uint             `json:"cartId"`
+	Added   int              `json:"added"`
+	Changed []ReorderChanged `json:"changed"`
+	Dropped []ReorderDropped `json:"dropped"`
+}
+
+// ---------------- Service ----------------
+
+type OrderService struct {
+	Store    repository.Store
+	Webhooks *WebhookService
+	Push     *PushService
+	Metrics  *metrics.Metrics // nil = ไม่เก็บ
+
+	DefaultDeliveryFee int64          // ค่าส่งเมื่อ input ไม่ระบุ DeliveryFee
+	Location           *time.Location // โซนเวลาของร้านสำหรับตรวจเวลาเปิด-ปิด (nil = โซนของเครื่อง)
+}
+
+func NewOrderService(store repository.Store, webhooks *WebhookService, push *PushService) *OrderService {
+	return &OrderService{Store: store, Webhooks: webhooks, Push: push}
+}
+
+// Create สั่งตรงจากรายการเมนู (คิดราคาจากเมนูปัจจุบัน)
+func (s *OrderService) Create(ctx context.Context, in PlaceOrderInput) (*entity.Order, error) {
+	if len(in.Lines) == 0 {
+		return nil, ErrItemsRequired
+	}
+
+	items := make([]entity.OrderItem, 0, len(in.Lines))
+	for _, l := range in.Lines {
+		menu, err := s.Store.Menus().FindByID(l.MenuID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if menu.RestaurantID != in.RestaurantID {
+			return nil, ErrMenuNotInRestaurant
+		}
+		items = append(items, entity.OrderItem{
+			MenuID:    menu.ID,
+			Qty:       l.Qty,
+			UnitPrice: menu.Price,
+			Total:     menu.Price * int64(l.Qty),
+			Note:      l.Note,
+		})
+	}
+
+	return s.place(ctx, in, in.RestaurantID, items, nil, nil)
+}
+
+// Checkout สร้าง order จาก cart หลังตรวจกับเมนู/ร้านปัจจุบัน
+// คืน validation กลับไปด้วยเสมอ (ให้ FE แสดง diff ได้เมื่อ error)
+func (s *OrderService) Checkout(ctx context.Context, in PlaceOrderInput) (*entity.Order, *CartValidation, error) {
+	cart, err := s.Store.Carts().GetCartWithItems(in.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, nil, ErrCartEmpty
+	}
+
+	validation, err := ValidateCart(s.Store, cart, time.Now(), s.Location)
+	if err != nil {
+		return nil, nil, err
+	}
+	// สั่งล่วงหน้าเช็คเวลาเปิดร้านใน initialStatus แทน
+	if validation.RestaurantClosed || (validation.OutsideHours && in.ScheduledFor == nil) {
+		return nil, validation, ErrRestaurantClosed
+	}
+	if validation.Stale() && !in.ConfirmChanges {
+		return nil, validation, ErrCartChanged
+	}
+	if validation.Dropped() == len(cart.Items) {
+		return nil, validation, ErrNoItemsAvailable
+	}
+
+	// ราคาปัจจุบันจากผลตรวจ (ไม่รวมรายการที่สั่งไม่ได้แล้ว)
+	dropped := map[uint]bool{}
+	price := map[uint]int64{}
+	for _, is := range validation.Issues {
+		if is.Kind == CartIssuePriceChanged {
+			price[is.CartItemID] = is.NewPrice
+		} else {
+			dropped[is.CartItemID] = true
+		}
+	}
+	items := make([]entity.OrderItem, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		if dropped[it.ID] {
+			continue
+		}
+		unit := it.UnitPrice
+		if p, ok := price[it.ID]; ok {
+			unit = p
+		}
+		items = append(items, entity.OrderItem{
+			MenuID:    it.MenuID,
+			Qty:       it.Qty,
+			UnitPrice: unit,
+			Total:     unit * int64(it.Qty),
+			Note:      it.Note,
+		})
+	}
+
+	order, err := s.place(ctx, in, cart.RestaurantID, items, cart, validation)
+	return order, validation, err
+}
+
+// place = ขั้นตอนสร้าง order ที่ใช้ร่วมกันระหว่าง Create และ Checkout
+func (s *OrderService) place(ctx context.Context, in PlaceOrderInput, restaurantID uint, items []entity.OrderItem, cart *entity.Cart, validation *CartValidation) (*entity.Order, error) {
+	statusID, err := s.initialStatus(restaurantID, in.ScheduledFor)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtotal int64
+	for _, it := range items {
+		subtotal += it.Total
+	}
+	discount := int64(0)
+	if in.Discount != nil {
+		discount = *in.Discount
+	}
+	delivery := s.DefaultDeliveryFee
+	if in.DeliveryFee != nil {
+		delivery = *in.DeliveryFee
+	}
+	total := subtotal - discount + delivery
+	if total < 0 {
+		total = 0
+	}
+
+	order := &entity.Order{
+		UserID:        in.UserID,
+		RestaurantID:  restaurantID,
+		Subtotal:      subtotal,
+		Discount:      discount,
+		DeliveryFee:   delivery,
+		Total:         total,
+		Address:       in.Address,
+		OrderStatusID: statusID, // Pending หรือ Scheduled
+		ScheduledFor:  in.ScheduledFor,
+	}
+
+	err = s.Store.Transaction(func(tx repository.Store) error {
+		if cart != nil && validation != nil && validation.Stale() {
+			if err := ApplyCartValidation(tx.Carts(), cart, validation); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Orders().Create(order); err != nil {
+			return err
+		}
+
+		for i := range items {
+			// ตัดสต็อก (order สั่งล่วงหน้าก็จองตั้งแต่ตอนสั่ง)
+			if err := ReserveStock(tx, items[i].MenuID, items[i].Qty); err != nil {
+				return err
+			}
+			items[i].OrderID = order.ID
+			if err := tx.Orders().CreateItem(&items[i]); err != nil {
+				return err
+			}
+		}
+
+		// payment pending ถ้ามีวิธีจ่ายที่รู้จัก
+		if in.PaymentMethod != "" {
+			if methodID := lookups.ID(lookups.PaymentMethod(in.PaymentMethod)); methodID != 0 {
+				if err := tx.Payments().Create(&entity.Payment{
+					Amount:          order.Total,
+					OrderID:         order.ID,
+					PaymentMethodID: methodID,
+					PaymentStatusID: lookups.ID(lookups.PaymentPending),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		if cart != nil {
+			return tx.Carts().ClearItems(cart.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// order สั่งล่วงหน้าจะแจ้งร้านตอน scheduler ปล่อยเข้าคิว
+	if order.ScheduledFor == nil {
+		s.Webhooks.DispatchOrderEvent(ctx, order.ID, WebhookOrderCreated)
+		s.Push.NotifyOrder(ctx, order.ID, NotifyNewOrder)
+	}
+	// label ของ metric ใช้เฉพาะวิธีจ่ายที่อยู่ใน lookup (กัน cardinality จาก input ผู้ใช้)
+	method := in.PaymentMethod
+	if method != "" && lookups.ID(lookups.PaymentMethod(method)) == 0 {
+		method = "other"
+	}
+	s.Metrics.OrderCreated(method, order.ScheduledFor != nil)
+	return order, nil
+}
+
+// initialStatus คืนสถานะเริ่มต้นของ order ใหม่ (ตรวจเวลาสั่งล่วงหน้าด้วย)
+func (s *OrderService) initialStatus(restaurantID uint, scheduledFor *time.Time) (uint, error) {
+	if scheduledFor == nil {
+		return lookups.ID(lookups.OrderPending), nil
+	}
+
+	rest, err := s.Store.Restaurants().FindByID(restaurantID)
+	if err != nil {
+		return 0, ErrRestaurantNotFound
+	}
+	if err := ValidateSchedule(*rest, *scheduledFor, time.Now(), s.Location); err != nil {
+		return 0, err
+	}
+
+	return lookups.ID(lookups.OrderScheduled), nil
+}
+
+// ListForUser รายการ order ของลูกค้า (ใหม่สุดก่อน)
+func (s *OrderService) ListForUser(userID uint) ([]repository.OrderSummary, error) {
+	return s.Store.Orders().ListOrdersForUser(userID, 0)
+}
+
+// Detail รายละเอียด order ของลูกค้า + payment ล่าสุด
+func (s *OrderService) Detail(userID, orderID uint) (*OrderDetail, error) {
+	order, err := s.Store.Orders().GetOrderForUser(userID, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.Store.Orders().GetOrderItems(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OrderDetail{Order: order, Items: items}
+	if p, err := s.Store.Payments().GetLatestByOrderID(order.ID); err == nil {
+		out.Payment = p
+	}
+	return out, nil
+}
+
+// CancelScheduled ลูกค้ายกเลิก order สั่งล่วงหน้าที่ยังไม่ถูกปล่อยเข้าคิวร้าน
+func (s *OrderService) CancelScheduled(userID, orderID uint) error {
+	order, err := s.Store.Orders().GetOrderForUser(userID, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	scheduledID := lookups.ID(lookups.OrderScheduled)
+	cancelledID := lookups.ID(lookups.OrderCancelled)
+
+	return s.Store.Transaction(func(tx repository.Store) error {
+		// เงื่อนไข status กันชนกับ scheduler ที่กำลังปล่อย order
+		ok, err := tx.Orders().UpdateStatusFromTo(order.ID, scheduledID, cancelledID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderReleased
+		}
+		return RestoreOrderStock(tx, order.ID)
+	})
+}
+
+// Reorder สร้างตะกร้าจากรายการใน order เก่า (ราคาปัจจุบัน)
+// replaceCart = true → ล้างตะกร้าที่มีของร้านอื่นแล้วแทนที่
+func (s *OrderService) Reorder(userID, orderID uint, replaceCart bool) (*ReorderResult, error) {
+	order, err := s.Store.Orders().GetOrderForUser(userID, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.Store.Orders().GetOrderItems(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// เมนูปัจจุบัน (รวมที่ถูกลบ เพื่อรายงานชื่อได้)
+	menuIDs := make([]uint, 0, len(items))
+	for _, it := range items {
+		menuIDs = append(menuIDs, it.MenuID)
+	}
+	menus, err := s.Store.Menus().FindByIDsWithDeleted(menuIDs)
+	if err != nil {
+		return nil, err
+	}
+	menuByID := make(map[uint]entity.Menu, len(menus))
+	for _, m := range menus {
+		menuByID[m.ID] = m
+	}
+	outOfStockID := lookups.ID(lookups.MenuOutOfStock)
+
+	res := &ReorderResult{Changed: []ReorderChanged{}, Dropped: []ReorderDropped{}}
+	var keep []entity.CartItem
+	for _, it := range items {
+		m, ok := menuByID[it.MenuID]
+		switch {
+		case !ok || m.DeletedAt.Valid || m.RestaurantID != order.RestaurantID:
+			res.Dropped = append(res.Dropped, ReorderDropped{MenuID: it.MenuID, Name: m.Name, Reason: "deleted"})
+			continue
+		case m.MenuStatusID == outOfStockID:
+			res.Dropped = append(res.Dropped, ReorderDropped{MenuID: it.MenuID, Name: m.Name, Reason: "out_of_stock"})
+			continue
+		}
+		if m.Price != it.UnitPrice {
+			res.Changed = append(res.Changed, ReorderChanged{
+				MenuID: m.ID, Name: m.Name, OldPrice: it.UnitPrice, NewPrice: m.Price,
+			})
+		}
+		keep = append(keep, entity.CartItem{
+			MenuID:    m.ID,
+			Qty:       it.Qty,
+			UnitPrice: m.Price,
+			Total:     m.Price * int64(it.Qty),
+			Note:      it.Note,
+		})
+	}
+	if len(keep) == 0 {
+		return res, ErrNothingToReorder
+	}
+
+	err = s.Store.Transaction(func(tx repository.Store) error {
+		carts := tx.Carts()
+		cart, err := carts.GetOrCreateCart(userID, order.RestaurantID)
+		if err != nil {
+			return err
+		}
+
+		if cart.RestaurantID != order.RestaurantID {
+			n, err := carts.CountItems(cart.ID)
+			if err != nil {
+				return err
+			}
+			if n > 0 && cart.RestaurantID != 0 && !replaceCart {
+				return &CartConflictError{RestaurantID: cart.RestaurantID}
+			}
+			if err := carts.ClearItems(cart.ID); err != nil {
+				return err
+			}
+			if err := carts.SetRestaurant(cart.ID, order.RestaurantID); err != nil {
+				return err
+			}
+		}
+
+		// ร้านเดียวกัน → รวมกับของเดิม (menu + note เดียวกันเพิ่ม qty)
+		for i := range keep {
+			if err := carts.UpsertItem(cart.ID, &keep[i]); err != nil {
+				return err
+			}
+		}
+		res.CartID = cart.ID
+		res.Added = len(keep)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/entity"
+	"backend/lookups"
+	"backend/repository"
+)
+
+// memoryShop = ร้านที่เปิดตลอด (ไม่ตั้งเวลา) บน MemoryStore สำหรับ unit test ของ service
+type memoryShop struct {
+	store *repository.MemoryStore
+	rest  *entity.Restaurant
+}
+
+const (
+	shopOwnerID  uint = 900
+	shopCustomer uint = 901
+)
+
+func newMemoryShop(t *testing.T) *memoryShop {
+	t.Helper()
+	lookups.LoadDefaults()
+	store := repository.NewMemoryStore()
+	rest := &entity.Restaurant{Name: "ร้านทดสอบ", UserID: shopOwnerID, RestaurantStatusID: lookups.ID(lookups.RestaurantOpen)}
+	store.AddRestaurant(rest)
+	return &memoryShop{store: store, rest: rest}
+}
+
+// menu เพิ่มเมนูที่ขายอยู่ (quota = nil คือไม่ track stock)
+func (s *memoryShop) menu(t *testing.T, name string, price int64, quota *int) *entity.Menu {
+	t.Helper()
+	m := &entity.Menu{Name: name, Price: price, RestaurantID: s.rest.ID, MenuStatusID: lookups.ID(lookups.MenuAvailable), DailyQuota: quota}
+	if quota != nil {
+		m.StockRemaining = *quota
+	}
+	if err := s.store.Menus().Create(m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func (s *memoryShop) addToCart(t *testing.T, m *entity.Menu, qty int) {
+	t.Helper()
+	if err := NewCartService(s.store).AddItem(shopCustomer, s.rest.ID, m.ID, qty, ""); err != nil {
+		t.Fatalf("add %s: %v", m.Name, err)
+	}
+}
+
+func (s *memoryShop) setPrice(t *testing.T, m *entity.Menu, price int64) {
+	t.Helper()
+	if err := s.store.Menus().UpdateFields(m.ID, map[string]any{"price": price}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestCheckoutRequiresConfirmationWhenPriceChanged(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, nil)
+	tea := shop.menu(t, "ชาไทย", 30, nil)
+	shop.addToCart(t, rice, 2)
+	shop.addToCart(t, tea, 1)
+	shop.setPrice(t, rice, 60)
+
+	svc := NewOrderService(shop.store, nil, nil)
+	in := PlaceOrderInput{UserID: shopCustomer, PaymentMethod: string(lookups.MethodPromptPay)}
+
+	_, v, err := svc.Checkout(context.Background(), in)
+	if !errors.Is(err, ErrCartChanged) {
+		t.Fatalf("err = %v, want ErrCartChanged", err)
+	}
+	if len(v.Issues) != 1 || v.Issues[0].Kind != CartIssuePriceChanged || v.Issues[0].OldPrice != 50 || v.Issues[0].NewPrice != 60 {
+		t.Fatalf("issues = %+v", v.Issues)
+	}
+	if v.Subtotal != 150 {
+		t.Fatalf("validation subtotal = %d, want 150", v.Subtotal)
+	}
+	if orders, _ := shop.store.Orders().ListOrdersForUser(shopCustomer, 0); len(orders) != 0 {
+		t.Fatalf("orders created before confirmation: %+v", orders)
+	}
+
+	in.ConfirmChanges = true
+	zero := int64(0)
+	in.DeliveryFee = &zero
+	order, _, err := svc.Checkout(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Subtotal != 150 || order.Total != 150 {
+		t.Fatalf("order subtotal/total = %d/%d, want 150/150", order.Subtotal, order.Total)
+	}
+	items, _ := shop.store.Orders().GetOrderItems(order.ID)
+	if len(items) != 2 || items[0].UnitPrice != 60 || items[0].Total != 120 {
+		t.Fatalf("order items = %+v", items)
+	}
+	pay, err := shop.store.Payments().GetLatestByOrderID(order.ID)
+	if err != nil || pay.PaymentMethodID != lookups.ID(lookups.MethodPromptPay) || pay.Amount != 150 {
+		t.Fatalf("payment = %+v, %v", pay, err)
+	}
+	if cart, _ := shop.store.Carts().GetCartWithItems(shopCustomer); len(cart.Items) != 0 {
+		t.Fatalf("cart not cleared: %+v", cart.Items)
+	}
+}
+
+func TestCheckoutRollsBackWhenStockRunsOut(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, intPtr(5))
+	soup := shop.menu(t, "ต้มยำ", 80, intPtr(1))
+	shop.addToCart(t, rice, 2)
+	shop.addToCart(t, soup, 2)
+
+	_, _, err := NewOrderService(shop.store, nil, nil).Checkout(context.Background(), PlaceOrderInput{UserID: shopCustomer})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("err = %v, want ErrInsufficientStock", err)
+	}
+
+	// ตัดสต็อกข้าวผัดไปแล้วก่อนต้มยำไม่พอ → ต้องย้อนทั้ง transaction
+	if m, _ := shop.store.Menus().FindByID(rice.ID); m.StockRemaining != 5 {
+		t.Fatalf("rice stock = %d, want 5", m.StockRemaining)
+	}
+	if orders, _ := shop.store.Orders().ListOrdersForUser(shopCustomer, 0); len(orders) != 0 {
+		t.Fatalf("orders = %+v, want none", orders)
+	}
+	if cart, _ := shop.store.Carts().GetCartWithItems(shopCustomer); len(cart.Items) != 2 {
+		t.Fatalf("cart items = %d, want 2", len(cart.Items))
+	}
+}
+
+func TestCreateRejectsMenuFromAnotherRestaurant(t *testing.T) {
+	shop := newMemoryShop(t)
+	other := &entity.Restaurant{Name: "ร้านอื่น", RestaurantStatusID: lookups.ID(lookups.RestaurantOpen)}
+	shop.store.AddRestaurant(other)
+	m := shop.menu(t, "ข้าวผัด", 50, nil)
+
+	_, err := NewOrderService(shop.store, nil, nil).Create(context.Background(), PlaceOrderInput{
+		UserID: shopCustomer, RestaurantID: other.ID, Lines: []OrderLine{{MenuID: m.ID, Qty: 1}},
+	})
+	if !errors.Is(err, ErrMenuNotInRestaurant) {
+		t.Fatalf("err = %v, want ErrMenuNotInRestaurant", err)
+	}
+}
+
+// placeOrder สั่งตรงผ่าน service แล้วคืน id ของ order
+func (s *memoryShop) placeOrder(t *testing.T, lines ...OrderLine) uint {
+	t.Helper()
+	order, err := NewOrderService(s.store, nil, nil).Create(context.Background(), PlaceOrderInput{
+		UserID: shopCustomer, RestaurantID: s.rest.ID, Lines: lines,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order.ID
+}
+
+func TestReorderSkipsUnavailableItems(t *testing.T) {
+	shop := newMemoryShop(t)
+	rice := shop.menu(t, "ข้าวผัด", 50, nil)
+	soup := shop.menu(t, "ต้มยำ", 80, nil)
+	tea := shop.menu(t, "ชาไทย", 30, nil)
+	orderID := shop.placeOrder(t, OrderLine{MenuID: rice.ID, Qty: 2}, OrderLine{MenuID: soup.ID, Qty: 1}, OrderLine{MenuID: tea.ID, Qty: 1})
+
+	shop.setPrice(t, rice, 55)
+	if err := shop.store.Menus().UpdateStatus(soup.ID, lookups.ID(lookups.MenuOutOfStock)); err != nil {
+		t.Fatal(err)
+	}
+	if err := shop.store.Menus().Delete(tea.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewOrderService(shop.store, nil, nil)
+	res, err := svc.Reorder(shopCustomer, orderID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 1 || len(res.Changed) != 1 || res.Changed[0].NewPrice != 55 {
+		t.Fatalf("added/changed = %d/%+v", res.Added, res.Changed)
+	}
+	reasons := map[uint]string{}
+	for _, d := range res.Dropped {
+		reasons[d.MenuID] = d.Reason
+	}
+	if reasons[soup.ID] != "out_of_stock" || reasons[tea.ID] != "deleted" || len(reasons) != 2 {
+		t.Fatalf("dropped = %+v", res.Dropped)
+	}
+	cart, _ := shop.store.Carts().GetCartWithItems(shopCustomer)
+	if len(cart.Items) != 1 || cart.Items[0].MenuID != rice.ID || cart.Items[0].Total != 110 {
+		t.Fatalf("cart = %+v", cart.Items)
+	}
+
+	// ไม่เหลืออะไรสั่งได้เลย
+	if err := shop.store.Menus().UpdateStatus(rice.ID, lookups.ID(lookups.MenuOutOfStock)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Reorder(shopCustomer, orderID, false); !errors.Is(err, ErrNothingToReorder) {
+		t.Fatalf("err = %v, want ErrNothingToReorder", err)
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"backend/metrics"
+	"backend/repository"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotOrderOwner       = errors.New("forbidden")
+	ErrNoPromptPay         = errors.New("restaurant has no PromptPay (mobile or citizen ID) set")
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrPaymentNotCompleted = errors.New("payment not completed")
+	ErrInvalidBase64       = errors.New("invalid base64 format")
+	ErrSlipTooLarge        = errors.New("Image size exceeds limit")
+	ErrInvalidContentType  = errors.New("Invalid content type, must be image/*")
+)
+
+// ใช้เมื่อไม่ได้ตั้ง PaymentService.MaxSlipBytes
+const defaultMaxSlipBytes = 5 * 1024 * 1024
+
+// AlreadyPaidError = order นี้ชำระแล้ว (ไม่เรียกผู้ให้บริการซ้ำ)
+type AlreadyPaidError struct {
+	Payment *entity.Payment
+}
+
+func (e *AlreadyPaidError) Error() string { return "already_paid" }
+
+// AmountMismatchError = ยอดในสลิปไม่ตรงกับยอดที่คาดไว้
+type AmountMismatchError struct {
+	Slip *EasySlipData
+}
+
+func (e *AmountMismatchError) Error() string { return "amount_mismatch" }
+
+// ---------------- Input / Output ----------------
+
+type PaymentIntent struct {
+	Order      *entity.Order
+	Restaurant *entity.Restaurant
+	PromptPay  string // ตัวเลขล้วน
+	AmountBaht float64
+}
+
+type VerifySlipInput struct {
+	OrderID        uint
+	Amount         int64 // บาทจำนวนเต็ม (0 = ไม่ตรวจยอด)
+	ContentType    string
+	SlipBase64     string
+	CheckDuplicate bool
+}
+
+type VerifySlipResult struct {
+	Payment   *entity.Payment
+	Slip      *EasySlipData
+	Duplicate bool // สลิปเคยถูกตรวจแล้ว (ถือว่าสำเร็จแบบ idempotent)
+}
+
+// ---------------- Service ----------------
+
+type PaymentService struct {
+	Store    repository.Store
+	Verifier SlipVerifier
+	Webhooks *WebhookService
+	Metrics  *metrics.Metrics // nil = ไม่เก็บ
+
+	MaxSlipBytes int // ขนาดสลิปสูงสุดหลัง decode (0 = 5MB)
+}
+
+func NewPaymentService(store repository.Store, verifier SlipVerifier, webhooks *WebhookService) *PaymentService {
+	return &PaymentService{Store: store, Verifier: verifier, Webhooks: webhooks}
+}
+
+// Intent ข้อมูลสำหรับสร้าง QR PromptPay (เจ้าของออเดอร์เท่านั้น)
+func (s *PaymentService) Intent(userID, orderID uint) (*PaymentIntent, error) {
+	order, err := s.ownedOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// PromptPay จากตาราง restaurants
+	rest, err := s.Store.Restaurants().FindByID(order.RestaurantID)
+	if err != nil {
+		return nil, ErrRestaurantNotFound
+	}
+	pp := DigitsOnly(rest.PromptPay)
+	if pp == "" {
+		return nil, ErrNoPromptPay
+	}
+
+	// ord.Total = "บาท"
+	return &PaymentIntent{Order: order, Restaurant: rest, PromptPay: pp, AmountBaht: float64(order.Total)}, nil
+}
+
+// Summary payment ล่าสุดที่ชำระแล้วของออเดอร์
+func (s *PaymentService) Summary(userID, orderID uint) (*entity.Order, *entity.Payment, error) {
+	order, err := s.ownedOrder(userID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pay, err := s.Store.Payments().GetLatestByOrderID(order.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if pay.PaidAt == nil {
+		return nil, nil, ErrPaymentNotCompleted
+	}
+	return order, pay, nil
+}
+
+// SaveSlip เก็บรูปสลิปไว้กับ payment ของออเดอร์ (ยังไม่ตรวจกับผู้ให้บริการ)
+func (s *PaymentService) SaveSlip(orderID uint, amount int64, contentType, slipBase64 string) (*entity.Payment, error) {
+	cleanB64, err := StripDataURLHeader(slipBase64)
+	if err != nil {
+		return nil, err
+	}
+	imageData, _ := base64.StdEncoding.DecodeString(cleanB64)
+	limit := s.MaxSlipBytes
+	if limit <= 0 {
+		limit = defaultMaxSlipBytes
+	}
+	if len(imageData) > limit {
+		return nil, fmt.Errorf("%w of %d bytes", ErrSlipTooLarge, limit)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, ErrInvalidContentType
+	}
+
+	p, err := s.paymentForOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Amount = amount // "บาทจำนวนเต็ม" ให้สอดคล้อง VerifySlip
+	p.SlipBase64 = cleanB64
+	p.SlipContentType = contentType
+	if err := s.Store.Payments().Save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// VerifySlip ตรวจสลิปกับผู้ให้บริการ แล้วบันทึกว่าชำระแล้วเมื่อยอดตรง
+func (s *PaymentService) VerifySlip(ctx context.Context, in VerifySlipInput) (*VerifySlipResult, error) {
+	if s.Verifier == nil {
+		return nil, ErrSlipVerifierNotConfigured
+	}
+
+	// จ่ายแล้ว → ตัดจบทันที ไม่เรียกผู้ให้บริการ
+	p, err := s.paymentForOrder(in.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	b64, err := StripDataURLHeader(in.SlipBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate := false
+	start := time.Now()
+	slip, err := s.Verifier.Verify(ctx, b64, in.CheckDuplicate)
+	s.Metrics.ObserveSlipVerify(slipOutcome(err), time.Since(start))
+	if err != nil {
+		var ve *SlipVerifyError
+		if !errors.As(err, &ve) || ve.Code != "duplicate_slip" || ve.Data == nil {
+			return nil, err
+		}
+		// สลิปซ้ำที่มีข้อมูลเดิม → ถือว่าสำเร็จ (idempotent) ถ้ายอดตรง
+		slip, duplicate = ve.Data, true
+	}
+
+	slipBaht := int64(math.Round(slip.Amount.Amount)) // เก็บเป็น "บาทจำนวนเต็ม"
+	if in.Amount != 0 && slipBaht != in.Amount {
+		return nil, &AmountMismatchError{Slip: slip}
+	}
+
+	contentType := in.ContentType
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = "image/*"
+	}
+	now := time.Now()
+	transRef := slip.TransRef
+	p.SlipBase64 = b64
+	p.SlipContentType = contentType
+	p.Amount = slipBaht
+	p.TransRef = &transRef
+	p.PaidAt = &now
+	p.PaymentStatusID = lookups.ID(lookups.PaymentPaid)
+	if err := s.Store.Payments().Save(p); err != nil {
+		return nil, err
+	}
+
+	s.Metrics.PaymentVerified("slip")
+	s.Webhooks.DispatchOrderEvent(ctx, p.OrderID, WebhookPaymentPaid)
+	return &VerifySlipResult{Payment: p, Slip: slip, Duplicate: duplicate}, nil
+}
+
+// ---------------- Helper ----------------
+
+// ผลการเรียกผู้ให้บริการสำหรับ metrics: ok / code ของ SlipVerifyError / error
+func slipOutcome(err error) string {
+	var ve *SlipVerifyError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &ve):
+		return ve.Code
+	case errors.Is(err, ErrSlipVerifierNotConfigured):
+		return "not_configured"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+func (s *PaymentService) ownedOrder(userID, orderID uint) (*entity.Order, error) {
+	order, err := s.Store.Orders().GetOrder(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrNotOrderOwner
+	}
+	return order, nil
+}
+
+// payment ของออเดอร์ (ยังไม่มีก็เตรียมใหม่) — ชำระแล้วคืน AlreadyPaidError
+func (s *PaymentService) paymentForOrder(orderID uint) (*entity.Payment, error) {
+	order, err := s.Store.Orders().GetOrder(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.Store.Payments().GetByOrderID(order.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &entity.Payment{OrderID: order.ID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.PaidAt != nil {
+		return nil, &AlreadyPaidError{Payment: p}
+	}
+	return p, nil
+}
+
+// StripDataURLHeader ตัด header data:image/...;base64, และตรวจว่าเป็น base64 ที่ถูกต้อง
+func StripDataURLHeader(b64 string) (string, error) {
+	if i := strings.Index(b64, ","); i != -1 {
+		b64 = b64[i+1:]
+	}
+	if _, err := base64.StdEncoding.DecodeString(b64); err != nil {
+		return "", ErrInvalidBase64
+	}
+	return b64, nil
+}
+
+// DigitsOnly เก็บเฉพาะตัวเลข 0-9 (ใช้กับ PromptPay ที่อาจมี dash/space ติดมา)
+func DigitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
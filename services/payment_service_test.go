@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"backend/lookups"
+)
+
+// stubVerifier ตอบสลิปตามยอดที่ตั้งไว้ (นับจำนวนครั้งที่ถูกเรียก)
+type stubVerifier struct {
+	baht  float64
+	calls int
+}
+
+func (v *stubVerifier) Verify(_ context.Context, _ string, _ bool) (*EasySlipData, error) {
+	v.calls++
+	return &EasySlipData{TransRef: "TX-1", Amount: EasySlipAmount{Amount: v.baht}}, nil
+}
+
+var testSlip = base64.StdEncoding.EncodeToString([]byte("slip-image"))
+
+func TestVerifySlipMarksPaymentPaid(t *testing.T) {
+	shop := newMemoryShop(t)
+	orderID := shop.placeOrder(t, OrderLine{MenuID: shop.menu(t, "ข้าวผัด", 120, nil).ID, Qty: 1})
+	verifier := &stubVerifier{baht: 120}
+	svc := NewPaymentService(shop.store, verifier, nil)
+
+	var mismatch *AmountMismatchError
+	if _, err := svc.VerifySlip(context.Background(), VerifySlipInput{OrderID: orderID, Amount: 100, SlipBase64: testSlip}); !errors.As(err, &mismatch) {
+		t.Fatalf("err = %v, want AmountMismatchError", err)
+	}
+	if _, err := shop.store.Payments().GetByOrderID(orderID); err == nil {
+		t.Fatal("payment saved on amount mismatch")
+	}
+
+	res, err := svc.VerifySlip(context.Background(), VerifySlipInput{OrderID: orderID, Amount: 120, SlipBase64: "data:image/png;base64," + testSlip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Payment.PaymentStatusID != lookups.ID(lookups.PaymentPaid) || res.Payment.PaidAt == nil || res.Payment.SlipBase64 != testSlip {
+		t.Fatalf("payment = %+v", res.Payment)
+	}
+
+	// จ่ายแล้ว → ไม่เรียกผู้ให้บริการซ้ำ
+	var paid *AlreadyPaidError
+	if _, err := svc.VerifySlip(context.Background(), VerifySlipInput{OrderID: orderID, SlipBase64: testSlip}); !errors.As(err, &paid) {
+		t.Fatalf("err = %v, want AlreadyPaidError", err)
+	}
+	if verifier.calls != 2 {
+		t.Fatalf("verifier calls = %d, want 2", verifier.calls)
+	}
+}
+
+func TestSaveSlipValidatesInput(t *testing.T) {
+	shop := newMemoryShop(t)
+	orderID := shop.placeOrder(t, OrderLine{MenuID: shop.menu(t, "ข้าวผัด", 120, nil).ID, Qty: 1})
+	svc := NewPaymentService(shop.store, nil, nil)
+	svc.MaxSlipBytes = 4
+
+	if _, err := svc.SaveSlip(orderID, 120, "image/png", "***"); !errors.Is(err, ErrInvalidBase64) {
+		t.Fatalf("bad base64: err = %v", err)
+	}
+	if _, err := svc.SaveSlip(orderID, 120, "image/png", testSlip); !errors.Is(err, ErrSlipTooLarge) {
+		t.Fatalf("too large: err = %v", err)
+	}
+	svc.MaxSlipBytes = 0
+	if _, err := svc.SaveSlip(orderID, 120, "text/plain", testSlip); !errors.Is(err, ErrInvalidContentType) {
+		t.Fatalf("content type: err = %v", err)
+	}
+	if _, err := svc.SaveSlip(orderID+100, 120, "image/png", testSlip); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("missing order: err = %v", err)
+	}
+
+	p, err := svc.SaveSlip(orderID, 120, "image/png", testSlip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := shop.store.Payments().GetByOrderID(orderID); got.ID != p.ID || got.SlipContentType != "image/png" {
+		t.Fatalf("stored payment = %+v", got)
+	}
+}
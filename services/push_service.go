@@ -0,0 +1,319 @@
+package services
+
+import (
+	"backend/entity"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ประเภทการแจ้งเตือน
+const (
+	NotifyOrderAccepted       = "order_accepted"
+	NotifyRiderAssigned       = "rider_assigned"
+	NotifyOrderDelivered      = "order_delivered"
+	NotifyChatMessage         = "chat_message"
+	NotifyApplicationApproved = "application_approved"
+	NotifyApplicationRejected = "application_rejected"
+	NotifyOrderCancelled      = "order_cancelled"
+	NotifyNewOrder            = "new_order" // แจ้งเจ้าของร้าน
+	NotifyReportStatusChanged = "report_status_changed"
+)
+
+// หมวดของการแจ้งเตือน ใช้เทียบกับ NotificationPreference
+const (
+	notifyCategoryOrder       = "order"
+	notifyCategoryChat        = "chat"
+	notifyCategoryApplication = "application"
+	notifyCategoryReport      = "report"
+)
+
+type pushTemplate struct {
+	Title string
+	Body  string
+}
+
+type notifyKind struct {
+	Category  string
+	Templates map[string]pushTemplate // locale -> template
+}
+
+// template ของแต่ละประเภท (ไทย / อังกฤษ) ใช้ text/template กับ data ที่ส่งมา
+var notifyKinds = map[string]notifyKind{
+	NotifyOrderAccepted: {
+		Category: notifyCategoryOrder,
+		Templates: map[string]pushTemplate{
+			"th": {"ร้านรับออเดอร์แล้ว", "{{.restaurantName}} กำลังเตรียมออเดอร์ #{{.orderId}} ของคุณ"},
+			"en": {"Order accepted", "{{.restaurantName}} is preparing your order #{{.orderId}}"},
+		},
+	},
+	NotifyRiderAssigned: {
+		Category: notifyCategoryOrder,
+		Templates: map[string]pushTemplate{
+			"th": {"ไรเดอร์รับงานแล้ว", "{{.riderName}} กำลังนำส่งออเดอร์ #{{.orderId}}"},
+			"en": {"Rider assigned", "{{.riderName}} is delivering your order #{{.orderId}}"},
+		},
+	},
+	NotifyOrderDelivered: {
+		Category: notifyCategoryOrder,
+		Templates: map[string]pushTemplate{
+			"th": {"ส่งอาหารเรียบร้อย", "ออเดอร์ #{{.orderId}} ถึงมือคุณแล้ว ทานให้อร่อยนะ"},
+			"en": {"Order delivered", "Your order #{{.orderId}} has been delivered. Enjoy your meal!"},
+		},
+	},
+	NotifyChatMessage: {
+		Category: notifyCategoryChat,
+		Templates: map[string]pushTemplate{
+			"th": {"ข้อความใหม่จาก {{.senderName}}", "{{.preview}}"},
+			"en": {"New message from {{.senderName}}", "{{.preview}}"},
+		},
+	},
+	NotifyApplicationApproved: {
+		Category: notifyCategoryApplication,
+		Templates: map[string]pushTemplate{
+			"th": {"ใบสมัครได้รับการอนุมัติ", "ใบสมัคร{{.applicationType}}ของคุณได้รับการอนุมัติแล้ว"},
+			"en": {"Application approved", "Your {{.applicationType}} application has been approved"},
+		},
+	},
+	NotifyApplicationRejected: {
+		Category: notifyCategoryApplication,
+		Templates: map[string]pushTemplate{
+			"th": {"ใบสมัครไม่ผ่านการอนุมัติ", "ใบสมัคร{{.applicationType}}ของคุณไม่ผ่าน: {{.reason}}"},
+			"en": {"Application rejected", "Your {{.applicationType}} application was rejected: {{.reason}}"},
+		},
+	},
+	NotifyOrderCancelled: {
+		Category: notifyCategoryOrder,
+		Templates: map[string]pushTemplate{
+			"th": {"ออเดอร์ถูกยกเลิก", "{{.restaurantName}} ยกเลิกออเดอร์ #{{.orderId}} ของคุณ"},
+			"en": {"Order cancelled", "{{.restaurantName}} cancelled your order #{{.orderId}}"},
+		},
+	},
+	NotifyNewOrder: {
+		Category: notifyCategoryOrder,
+		Templates: map[string]pushTemplate{
+			"th": {"มีออเดอร์ใหม่", "ออเดอร์ #{{.orderId}} เข้ามาที่ร้าน {{.restaurantName}}"},
+			"en": {"New order", "Order #{{.orderId}} has arrived at {{.restaurantName}}"},
+		},
+	},
+	NotifyReportStatusChanged: {
+		Category: notifyCategoryReport,
+		Templates: map[string]pushTemplate{
+			"th": {"อัปเดตเรื่องที่แจ้ง", "เรื่องที่คุณแจ้ง #{{.reportId}} เปลี่ยนสถานะเป็น {{.status}}"},
+			"en": {"Report updated", "Your report #{{.reportId}} is now {{.status}}"},
+		},
+	},
+}
+
+var ErrUnknownNotifyKind = errors.New("unknown notification kind")
+
+// RenderNotification สร้าง title/body ตามประเภทและภาษา (ภาษาที่ไม่รองรับจะ fallback เป็นไทย)
+func RenderNotification(kind, locale string, data map[string]string) (string, string, error) {
+	k, ok := notifyKinds[kind]
+	if !ok {
+		return "", "", ErrUnknownNotifyKind
+	}
+	tpl, ok := k.Templates[locale]
+	if !ok {
+		tpl = k.Templates["th"]
+	}
+
+	render := func(text string) (string, error) {
+		t, err := template.New(kind).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	title, err := render(tpl.Title)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := render(tpl.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return title, body, nil
+}
+
+// PushService เลือก provider ตาม platform แล้วส่งให้ทุกอุปกรณ์ของผู้ใช้
+type PushService struct {
+	DB        *gorm.DB
+	Providers map[string]Notifier // platform -> provider
+	Fallback  Notifier            // ใช้เมื่อ platform ไม่มี provider เฉพาะ
+	Inbox     *InboxService       // ถ้าตั้งไว้ Notify จะเก็บลงกล่องข้อความในแอปด้วย
+}
+
+func NewPushService(db *gorm.DB, providers map[string]Notifier, fallback Notifier) *PushService {
+	if providers == nil {
+		providers = map[string]Notifier{}
+	}
+	if fallback == nil {
+		fallback = LogNotifier{}
+	}
+	return &PushService{DB: db, Providers: providers, Fallback: fallback}
+}
+
+func (s *PushService) provider(platform string) Notifier {
+	if n, ok := s.Providers[platform]; ok && n != nil {
+		return n
+	}
+	return s.Fallback
+}
+
+// Preferences คืนการตั้งค่าของผู้ใช้ (ไม่มีแถว = ค่า default)
+func (s *PushService) Preferences(userID uint) (*entity.NotificationPreference, error) {
+	var p entity.NotificationPreference
+	err := s.DB.Where("user_id = ?", userID).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &entity.NotificationPreference{
+			UserID:       userID,
+			Locale:       "th",
+			PushEnabled:  true,
+			OrderUpdates: true,
+			ChatMessages: true,
+			Applications: true,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func allowed(p *entity.NotificationPreference, category string) bool {
+	if !p.PushEnabled {
+		return false
+	}
+	switch category {
+	case notifyCategoryOrder:
+		return p.OrderUpdates
+	case notifyCategoryChat:
+		return p.ChatMessages
+	case notifyCategoryApplication:
+		return p.Applications
+	}
+	return true
+}
+
+// Send ส่ง push ทันที (sync) — คืน error แรกที่ไม่ใช่ token เสีย
+func (s *PushService) Send(ctx context.Context, userID uint, kind string, data map[string]string) error {
+	k, ok := notifyKinds[kind]
+	if !ok {
+		return ErrUnknownNotifyKind
+	}
+
+	pref, err := s.Preferences(userID)
+	if err != nil {
+		return err
+	}
+	if !allowed(pref, k.Category) {
+		return nil
+	}
+
+	var devices []entity.DeviceToken
+	if err := s.DB.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+
+	title, body, err := RenderNotification(kind, pref.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]string{"type": kind}
+	for k, v := range data {
+		payload[k] = v
+	}
+
+	var firstErr error
+	for _, d := range devices {
+		err := s.provider(d.Platform).Send(ctx, PushMessage{
+			Token:    d.Token,
+			Platform: d.Platform,
+			Title:    title,
+			Body:     body,
+			Data:     payload,
+		})
+		if errors.Is(err, ErrInvalidDeviceToken) {
+			// token หมดอายุ/ถอนแอปแล้ว → ลบทิ้ง
+			s.DB.Unscoped().Delete(&entity.DeviceToken{}, d.ID)
+			continue
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Notify เก็บลงกล่องข้อความ (sync) แล้วส่ง push แบบ async ไม่ให้ request หลักต้องรอ provider
+// ctx ใช้แค่เอา attr ของ log (requestId) ไปด้วย ไม่ผูกกับการยกเลิกของ request
+func (s *PushService) Notify(ctx context.Context, userID uint, kind string, data map[string]string) {
+	if s == nil || userID == 0 {
+		return
+	}
+	if s.Inbox != nil && !inboxSkipKinds[kind] {
+		if _, err := s.Inbox.Create(userID, kind, data); err != nil {
+			slog.ErrorContext(ctx, "inbox create failed", "userId", userID, "kind", kind, "error", err)
+		}
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := s.Send(ctx, userID, kind, data); err != nil {
+			slog.ErrorContext(ctx, "push notify failed", "userId", userID, "kind", kind, "error", err)
+		}
+	}()
+}
+
+// NotifyOrder แจ้งลูกค้าเจ้าของ order (NotifyNewOrder แจ้งเจ้าของร้านแทน)
+// เติมชื่อร้าน/ไรเดอร์ให้ template อัตโนมัติ
+func (s *PushService) NotifyOrder(ctx context.Context, orderID uint, kind string) {
+	if s == nil {
+		return
+	}
+	var order entity.Order
+	if err := s.DB.Preload("Restaurant").First(&order, orderID).Error; err != nil {
+		slog.ErrorContext(ctx, "push: order not found", "orderId", orderID, "error", err)
+		return
+	}
+
+	data := map[string]string{
+		"orderId":        strconv.FormatUint(uint64(order.ID), 10),
+		"restaurantName": order.Restaurant.Name,
+	}
+
+	// ไรเดอร์ล่าสุดของ order (ถ้ามี)
+	var rider struct{ FirstName, LastName string }
+	s.DB.Table("rider_works rw").
+		Select("u.first_name, u.last_name").
+		Joins("JOIN riders r ON r.id = rw.rider_id").
+		Joins("JOIN users u ON u.id = r.user_id").
+		Where("rw.order_id = ? AND rw.deleted_at IS NULL", order.ID).
+		Order("rw.id DESC").
+		Limit(1).
+		Scan(&rider)
+	data["riderName"] = strings.TrimSpace(rider.FirstName + " " + rider.LastName)
+
+	recipient := order.UserID
+	if kind == NotifyNewOrder {
+		recipient = order.Restaurant.UserID
+	}
+	s.Notify(ctx, recipient, kind, data)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const easySlipVerifyURL = "https://developer.easyslip.com/api/v1/verify"
+
+var ErrSlipVerifierNotConfigured = errors.New("missing_easyslip_token")
+
+// SlipVerifier ตรวจสลิปโอนเงินกับผู้ให้บริการภายนอก (EasySlip หรือ fake ตอนทดสอบ)
+type SlipVerifier interface {
+	Verify(ctx context.Context, imageBase64 string, checkDuplicate bool) (*EasySlipData, error)
+}
+
+// ReadyChecker = dependency ที่บอกได้ว่าพร้อมให้บริการหรือยัง (ใช้กับ /readyz)
+type ReadyChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// SlipVerifyError = ผู้ให้บริการตอบ error
+// Code: duplicate_slip, invalid_image, qrcode_not_found, unauthorized, quota_exceeded, easyslip_unreachable, ...
+type SlipVerifyError struct {
+	Code           string
+	UpstreamStatus int           // HTTP status จากผู้ให้บริการ (0 = เรียกไม่ถึง)
+	Data           *EasySlipData // duplicate_slip จะมีข้อมูลสลิปเดิมมาด้วย
+}
+
+func (e *SlipVerifyError) Error() string { return e.Code }
+
+// ====== โครงสร้างข้อมูลสลิปของ EasySlip ======
+
+type EasySlipAmount struct {
+	Amount float64 `json:"amount"` // บาท
+	Local  struct {
+		Amount   float64 `json:"amount"` // บาท
+		Currency string  `json:"currency"`
+	} `json:"local"`
+}
+
+type EasySlipBank struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Short string `json:"short"`
+}
+
+type EasySlipAccount struct {
+	Name struct {
+		TH string `json:"th"`
+		EN string `json:"en"`
+	} `json:"name"`
+	Bank *struct {
+		Type    string `json:"type"`
+		Account string `json:"account"`
+	} `json:"bank,omitempty"`
+	Proxy *struct {
+		Type    string `json:"type"`
+		Account string `json:"account"`
+	} `json:"proxy,omitempty"`
+}
+
+type EasySlipData struct {
+	Payload     string         `json:"payload"`
+	TransRef    string         `json:"transRef"`
+	Date        string         `json:"date"`
+	CountryCode string         `json:"countryCode"`
+	Amount      EasySlipAmount `json:"amount"`
+	Fee         int64          `json:"fee"`
+	Ref1        string         `json:"ref1"`
+	Ref2        string         `json:"ref2"`
+	Ref3        string         `json:"ref3"`
+	Sender      struct {
+		Bank    EasySlipBank    `json:"bank"`
+		Account EasySlipAccount `json:"account"`
+	} `json:"sender"`
+	Receiver struct {
+		Bank    EasySlipBank    `json:"bank"`
+		Account EasySlipAccount `json:"account"`
+	} `json:"receiver"`
+}
+
+type easySlipVerifyReq struct {
+	Image          string `json:"image"`
+	CheckDuplicate *bool  `json:"checkDuplicate,omitempty"`
+}
+
+type easySlipOKResp struct {
+	Status int          `json:"status"`
+	Data   EasySlipData `json:"data"`
+}
+
+type easySlipErrResp struct {
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Data    *EasySlipData `json:"data,omitempty"`
+}
+
+// ====== EasySlip (ของจริง) ======
+
+type EasySlipVerifier struct {
+	Token      string
+	URL        string
+	Timeout    time.Duration
+	httpClient *http.Client
+}
+
+// timeout ครอบทั้งการเรียก 1 ครั้ง (0 = 25 วินาที)
+func NewEasySlipVerifier(token string, timeout time.Duration) *EasySlipVerifier {
+	if token == "" {
+		slog.Warn("EASYSLIP_API_KEY is empty: slip verification will fail")
+	}
+	return &EasySlipVerifier{
+		Token:      token,
+		URL:        easySlipVerifyURL,
+		Timeout:    timeout,
+		httpClient: &http.Client{},
+	}
+}
+
+// Ready ตรวจแค่ว่าตั้ง token แล้ว (ไม่ยิง API จริง เพราะเสียโควตา)
+func (v *EasySlipVerifier) Ready(context.Context) error {
+	if v.Token == "" {
+		return ErrSlipVerifierNotConfigured
+	}
+	return nil
+}
+
+func (v *EasySlipVerifier) Verify(ctx context.Context, imageBase64 string, checkDuplicate bool) (*EasySlipData, error) {
+	if v.Token == "" {
+		return nil, ErrSlipVerifierNotConfigured
+	}
+
+	body, _ := json.Marshal(easySlipVerifyReq{
+		Image:          imageBase64,
+		CheckDuplicate: &checkDuplicate,
+	})
+
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = 25 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", v.URL, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+v.Token)
+
+	resp, err := v.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &SlipVerifyError{Code: "easyslip_unreachable"}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		var ok easySlipOKResp
+		if err := json.NewDecoder(resp.Body).Decode(&ok); err != nil {
+			return nil, &SlipVerifyError{Code: "easyslip_decode_error", UpstreamStatus: resp.StatusCode}
+		}
+		return &ok.Data, nil
+	}
+
+	// non-200 → แปลงเป็น SlipVerifyError เสมอ
+	var ek easySlipErrResp
+	if err := json.NewDecoder(resp.Body).Decode(&ek); err != nil || ek.Message == "" {
+		return nil, &SlipVerifyError{Code: "easyslip_error", UpstreamStatus: resp.StatusCode}
+	}
+	return nil, &SlipVerifyError{Code: ek.Message, UpstreamStatus: resp.StatusCode, Data: ek.Data}
+}
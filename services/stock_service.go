@@ -0,0 +1,170 @@
+package services
+
+import (
+	"backend/entity"
+	"backend/lookups"
+	"backend/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidQty        = errors.New("qty must be at least 1")
+)
+
+// StockService จัดการโควต้ารายวันของเมนู (เฉพาะเมนูที่ตั้ง DailyQuota ไว้)
+type StockService struct {
+	DB       *gorm.DB
+	Interval time.Duration
+	Location *time.Location // โซนเวลาของร้าน: วันใหม่/เวลาเปิดคิดตามโซนนี้ (nil = โซนของเครื่อง)
+}
+
+func NewStockService(db *gorm.DB) *StockService {
+	return &StockService{DB: db, Interval: time.Minute}
+}
+
+// ReserveStock ตัดสต็อกแบบ atomic กันขายเกินเมื่อสั่งพร้อมกัน
+// เมนูที่ไม่ได้ track stock จะผ่านเสมอ; ของหมดพอดีจะเปลี่ยนสถานะเป็น Out of Stock อัตโนมัติ
+func ReserveStock(store repository.Store, menuID uint, qty int) error {
+	if qty < 1 {
+		return ErrInvalidQty // qty ติดลบ = stock_remaining เพิ่มเกินโควต้า
+	}
+	menus := store.Menus()
+	ok, err := menus.DecrementStock(menuID, qty)
+	if err != nil {
+		return err
+	}
+
+	menu, err := menus.FindByID(menuID)
+	if err != nil {
+		return err
+	}
+	if menu.DailyQuota == nil {
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s (เหลือ %d)", ErrInsufficientStock, menu.Name, menu.StockRemaining)
+	}
+
+	if menu.StockRemaining == 0 {
+		return menus.UpdateFields(menuID, map[string]any{"menu_status_id": lookups.ID(lookups.MenuOutOfStock), "auto_out_of_stock": true})
+	}
+	return nil
+}
+
+// RestoreOrderStock คืนสต็อกของ order ที่ถูกยกเลิก
+func RestoreOrderStock(store repository.Store, orderID uint) error {
+	items, err := store.Orders().GetOrderItems(orderID)
+	if err != nil {
+		return err
+	}
+
+	menus := store.Menus()
+	ids := make([]uint, 0, len(items))
+	for _, it := range items {
+		if err := menus.IncrementStock(it.MenuID, it.Qty); err != nil {
+			return err
+		}
+		ids = append(ids, it.MenuID)
+	}
+
+	// เปิดขายกลับเฉพาะเมนูที่ระบบปิดเอง (ไม่ทับที่เจ้าของร้านปิดด้วยมือ)
+	availableID := lookups.ID(lookups.MenuAvailable)
+	current, err := menus.FindByIDsWithDeleted(ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range current {
+		if m.AutoOutOfStock && m.StockRemaining > 0 {
+			if err := menus.UpdateFields(m.ID, map[string]any{"menu_status_id": availableID, "auto_out_of_stock": false}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// openingAt คืนเวลาเปิดร้านของวันที่ now อยู่ ตามโซนของ now (ไม่ได้ตั้งเวลาเปิด = เที่ยงคืน)
+func openingAt(openingTime string, now time.Time) time.Time {
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	mins, err := parseClock(openingTime)
+	if err != nil {
+		return midnight
+	}
+	return midnight.Add(time.Duration(mins) * time.Minute)
+}
+
+// ResetDue เติมสต็อกเต็มโควต้าให้เมนูของร้านที่ถึงเวลาเปิดของวันนี้แล้วแต่ยังไม่ได้ reset
+func (s *StockService) ResetDue(now time.Time) (int, error) {
+	var rows []struct {
+		ID           uint
+		StockResetAt *time.Time
+		OpeningTime  string
+	}
+	if err := s.DB.Table("menus m").
+		Select("m.id, m.stock_reset_at, r.opening_time").
+		Joins("JOIN restaurants r ON r.id = m.restaurant_id").
+		Where("m.daily_quota IS NOT NULL AND m.deleted_at IS NULL").
+		Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	local := now.In(loc)
+
+	availableID := lookups.ID(lookups.MenuAvailable)
+	reset := 0
+	for _, r := range rows {
+		openAt := openingAt(r.OpeningTime, local)
+		if now.Before(openAt) || (r.StockResetAt != nil && !r.StockResetAt.Before(openAt)) {
+			continue
+		}
+
+		updates := map[string]any{
+			"stock_remaining": gorm.Expr("daily_quota"),
+			"stock_reset_at":  now,
+		}
+		if err := s.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&entity.Menu{}).Where("id = ?", r.ID).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+			return tx.Model(&entity.Menu{}).
+				Where("id = ? AND auto_out_of_stock = ? AND daily_quota > 0", r.ID, true).
+				UpdateColumns(map[string]any{"menu_status_id": availableID, "auto_out_of_stock": false}).Error
+		}); err != nil {
+			return reset, err
+		}
+		reset++
+	}
+	return reset, nil
+}
+
+// Run วน reset สต็อกรายวันจนกว่า ctx จะถูกยกเลิก
+func (s *StockService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.ResetDue(time.Now()); err != nil {
+			slog.ErrorContext(ctx, "stock: reset failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "stock: reset daily stock", "menus", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
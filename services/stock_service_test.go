@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/configs"
+	"backend/entity"
+	"backend/lookups"
+	"backend/migrations"
+	"backend/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newStockTestDB = SQLite ไฟล์จริงหลาย connection (WAL + busy timeout) ให้ UPDATE แข่งกันได้จริง
+func newStockTestDB(t *testing.T, conns int) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "stock.db") + "?_busy_timeout=10000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(conns)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := configs.SeedLookupTables(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lookups.Load(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestReserveStockNeverOversells(t *testing.T) {
+	const quota, buyers = 5, 40
+	db := newStockTestDB(t, 8)
+	rest := entity.Restaurant{Name: "ร้านทดสอบ"}
+	db.Create(&rest)
+	q := quota
+	menu := entity.Menu{Name: "ข้าวมันไก่", Price: 50, RestaurantID: rest.ID, MenuStatusID: lookups.ID(lookups.MenuAvailable), DailyQuota: &q, StockRemaining: quota}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store := repository.NewGormStore(db)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		ok, fail int
+		start    = make(chan struct{})
+	)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			err := ReserveStock(store, menu.ID, 1)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrInsufficientStock):
+				fail++
+			default:
+				t.Errorf("reserve: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if ok != quota || fail != buyers-quota {
+		t.Fatalf("successes/failures = %d/%d, want %d/%d", ok, fail, quota, buyers-quota)
+	}
+	var got entity.Menu
+	db.First(&got, menu.ID)
+	if got.StockRemaining != 0 {
+		t.Fatalf("stock remaining = %d, want 0", got.StockRemaining)
+	}
+	if got.MenuStatusID != lookups.ID(lookups.MenuOutOfStock) || !got.AutoOutOfStock {
+		t.Fatalf("status = %d auto=%v, want Out of Stock (auto)", got.MenuStatusID, got.AutoOutOfStock)
+	}
+}
+
+func TestResetDueUsesConfiguredTimezone(t *testing.T) {
+	bkk, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newStockTestDB(t, 1)
+	rest := entity.Restaurant{Name: "ร้านทดสอบ", OpeningTime: "09:00", ClosingTime: "21:00"}
+	db.Create(&rest)
+	q := 10
+	menu := entity.Menu{Name: "ข้าวมันไก่", RestaurantID: rest.ID, MenuStatusID: lookups.ID(lookups.MenuOutOfStock), AutoOutOfStock: true, DailyQuota: &q}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewStockService(db)
+	svc.Location = bkk
+	// now เป็น UTC เหมือน container ที่ไม่ได้ตั้ง TZ
+	for _, step := range []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"08:00 local, before opening", time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC), 0},
+		{"09:30 local", time.Date(2026, 1, 1, 2, 30, 0, 0, time.UTC), 1},
+		{"16:00 local, already reset today", time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), 0},
+		{"next day 09:01 local", time.Date(2026, 1, 2, 2, 1, 0, 0, time.UTC), 1},
+	} {
+		n, err := svc.ResetDue(step.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != step.want {
+			t.Fatalf("%s: reset %d menus, want %d", step.name, n, step.want)
+		}
+	}
+
+	var got entity.Menu
+	db.First(&got, menu.ID)
+	if got.StockRemaining != 10 || got.MenuStatusID != lookups.ID(lookups.MenuAvailable) || got.AutoOutOfStock {
+		t.Fatalf("menu after reset = stock %d status %d auto %v", got.StockRemaining, got.MenuStatusID, got.AutoOutOfStock)
+	}
+}
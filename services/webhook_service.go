@@ -0,0 +1,417 @@
+package services
+
+import (
+	"backend/entity"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// event ที่ส่งออกไปให้ร้าน
+const (
+	WebhookOrderCreated    = "order.created"
+	WebhookOrderAccepted   = "order.accepted"
+	WebhookOrderCancelled  = "order.cancelled"
+	WebhookOrderDelivering = "order.delivering"
+	WebhookOrderCompleted  = "order.completed"
+	WebhookPaymentPaid     = "payment.paid"
+)
+
+// รายการ event ทั้งหมดที่สมัครได้
+var WebhookEvents = []string{
+	WebhookOrderCreated,
+	WebhookOrderAccepted,
+	WebhookOrderCancelled,
+	WebhookOrderDelivering,
+	WebhookOrderCompleted,
+	WebhookPaymentPaid,
+}
+
+// header ที่แนบไปกับทุก request
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+var (
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookURLNotAllowed    = errors.New("webhook url must resolve to a public address")
+)
+
+// delivery ที่ worker หยิบไปแล้วจะไม่ถูกหยิบซ้ำจนกว่าจะพ้นเวลานี้ (กัน instance อื่นส่งซ้ำ / process ตายกลางคัน)
+const webhookClaimTTL = time.Minute
+
+type WebhookService struct {
+	DB         *gorm.DB
+	HTTPClient *http.Client
+
+	MaxAttempts int           // จำนวนครั้งสูงสุด (รวมครั้งแรก)
+	BaseBackoff time.Duration // รอก่อน retry ครั้งแรก แล้วคูณ 2 ไปเรื่อย ๆ
+	Interval    time.Duration // รอบที่ worker เช็ค delivery ที่ถึงเวลาส่ง
+	BatchSize   int
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		DB:          db,
+		HTTPClient:  newWebhookHTTPClient(),
+		MaxAttempts: 5,
+		BaseBackoff: 2 * time.Second,
+		Interval:    2 * time.Second,
+		BatchSize:   50,
+	}
+}
+
+// ---------------- SSRF ----------------
+
+// blockedWebhookIP = IP ที่ห้ามส่ง webhook ไป (loopback, private, link-local, unspecified, multicast)
+func blockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+// CheckWebhookURL resolve host ของ URL แล้วปฏิเสธถ้ามี IP ใดชี้เข้าเครือข่ายภายใน
+// ตอนส่งจริงยังตรวจซ้ำที่ dialer (กัน DNS rebinding)
+func CheckWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Hostname() == "" {
+		return ErrWebhookURLNotAllowed
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedWebhookIP(ip) {
+			return ErrWebhookURLNotAllowed
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ErrWebhookURLNotAllowed
+	}
+	for _, a := range addrs {
+		if blockedWebhookIP(a.IP) {
+			return ErrWebhookURLNotAllowed
+		}
+	}
+	return nil
+}
+
+// dialer ตรวจ IP ที่กำลังจะต่อจริง (หลัง resolve แล้ว) ทุกครั้ง รวมถึงตอนตาม redirect
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookURLNotAllowed, address)
+	}
+	return nil
+}
+
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second, Control: webhookDialControl}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil // ผ่าน proxy = dialer ตรวจได้แค่ IP ของ proxy
+	tr.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: tr}
+}
+
+// GenerateWebhookSecret สุ่ม secret ใหม่สำหรับ endpoint
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhookPayload = HMAC-SHA256 ของ "<timestamp>.<body>" แบบ hex
+// ฝั่งผู้รับคำนวณแบบเดียวกันแล้วเทียบกับ header X-Webhook-Signature (ตัด "sha256=" ออก)
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature ใช้ตรวจ signature (เผื่อฝั่ง integrator ที่เขียน Go)
+func VerifyWebhookSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := SignWebhookPayload(secret, timestamp, body)
+	signature = strings.TrimPrefix(signature, "sha256=")
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ตรวจว่า endpoint สมัคร event นี้ไว้หรือไม่
+func subscribed(ep *entity.WebhookEndpoint, event string) bool {
+	for _, e := range strings.Split(ep.Events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff ของครั้งที่ attempt (เริ่มที่ 1): base, base*2, base*4, ...
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return s.BaseBackoff * time.Duration(1<<uint(attempt-1))
+}
+
+// Dispatch สร้าง delivery ให้ทุก endpoint ของร้านที่สมัคร event นี้ (worker ใน Run เป็นคนส่ง)
+func (s *WebhookService) Dispatch(ctx context.Context, restaurantID uint, event string, data any) {
+	if s == nil || restaurantID == 0 {
+		return
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	if err := s.DB.Where("restaurant_id = ? AND is_active = ?", restaurantID, true).
+		Find(&endpoints).Error; err != nil {
+		slog.ErrorContext(ctx, "webhook: load endpoints failed", "restaurantId", restaurantID, "error", err)
+		return
+	}
+
+	for i := range endpoints {
+		ep := endpoints[i]
+		if !subscribed(&ep, event) {
+			continue
+		}
+
+		// สร้าง row กับใส่ payload ใน transaction เดียว → ไม่มี delivery ค้าง pending แบบไม่มี payload
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			d := entity.WebhookDelivery{EndpointID: ep.ID, Event: event, Status: "pending"}
+			if err := tx.Create(&d).Error; err != nil {
+				return err
+			}
+
+			// ใส่ delivery id ลงใน payload ด้วย ให้ผู้รับใช้กันประมวลผลซ้ำได้
+			body, err := json.Marshal(map[string]any{
+				"id":        d.ID,
+				"event":     event,
+				"createdAt": d.CreatedAt.UTC().Format(time.RFC3339),
+				"data":      data,
+			})
+			if err != nil {
+				return err
+			}
+			// ตั้ง next_attempt_at พร้อม payload → worker เริ่มหยิบได้
+			return tx.Model(&d).Updates(map[string]any{"payload": string(body), "next_attempt_at": time.Now()}).Error
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "webhook: create delivery failed", "endpointId", ep.ID, "error", err)
+		}
+	}
+}
+
+// DispatchOrderEvent โหลด order แล้วส่ง event ให้ร้านเจ้าของ order
+func (s *WebhookService) DispatchOrderEvent(ctx context.Context, orderID uint, event string) {
+	if s == nil {
+		return
+	}
+	var order entity.Order
+	if err := s.DB.Preload("OrderStatus").First(&order, orderID).Error; err != nil {
+		slog.ErrorContext(ctx, "webhook: order not found", "orderId", orderID, "error", err)
+		return
+	}
+
+	var items []entity.OrderItem
+	s.DB.Select("id, menu_id, qty, unit_price, total, note").
+		Where("order_id = ?", order.ID).Find(&items)
+
+	s.Dispatch(ctx, order.RestaurantID, event, map[string]any{
+		"orderId":       order.ID,
+		"restaurantId":  order.RestaurantID,
+		"userId":        order.UserID,
+		"subtotal":      order.Subtotal,
+		"discount":      order.Discount,
+		"deliveryFee":   order.DeliveryFee,
+		"total":         order.Total,
+		"address":       order.Address,
+		"orderStatusId": order.OrderStatusID,
+		"orderStatus":   order.OrderStatus.StatusName,
+		"items":         items,
+	})
+}
+
+// Redeliver ส่ง delivery เดิมซ้ำ (payload เดิม) — ใช้กับปุ่ม "ส่งใหม่" ของเจ้าของร้าน
+func (s *WebhookService) Redeliver(ctx context.Context, restaurantID, deliveryID uint) (*entity.WebhookDelivery, error) {
+	var d entity.WebhookDelivery
+	if err := s.DB.Joins("JOIN webhook_endpoints ep ON ep.id = webhook_deliveries.endpoint_id").
+		Where("webhook_deliveries.id = ? AND ep.restaurant_id = ? AND ep.deleted_at IS NULL", deliveryID, restaurantID).
+		Select("webhook_deliveries.*").
+		First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	var ep entity.WebhookEndpoint
+	if err := s.DB.First(&ep, d.EndpointID).Error; err != nil {
+		return nil, err
+	}
+
+	// สร้าง delivery ใหม่ที่อ้าง payload เดิม เพื่อให้ log เดิมไม่ถูกทับ
+	now := time.Now()
+	nd := entity.WebhookDelivery{
+		EndpointID:    ep.ID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        "pending",
+		NextAttemptAt: &now,
+	}
+	if err := s.DB.Create(&nd).Error; err != nil {
+		return nil, err
+	}
+	return &nd, nil
+}
+
+// ---------------- Worker ----------------
+
+// Run ส่ง delivery ที่ถึงเวลา (next_attempt_at <= now) จนกว่า ctx จะถูกยกเลิก
+// retry ที่ค้างอยู่ใน DB จึงไม่หายตอน restart
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeliverDue(ctx, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "webhook: deliver due failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue ส่ง delivery ที่ถึงเวลา ณ now ไม่เกิน BatchSize รายการ คืนจำนวนที่ส่งสำเร็จ
+func (s *WebhookService) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	var due []entity.WebhookDelivery
+	if err := s.DB.Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		Order("next_attempt_at ASC").
+		Limit(s.BatchSize).
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		d := &due[i]
+
+		// จองก่อนส่ง: เลื่อน next_attempt_at ออกไป ใครอัปเดตได้คนแรกเป็นคนส่ง
+		claim := s.DB.Model(&entity.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", d.ID, "pending", now).
+			Update("next_attempt_at", now.Add(webhookClaimTTL))
+		if claim.Error != nil {
+			return delivered, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		var ep entity.WebhookEndpoint
+		if err := s.DB.First(&ep, d.EndpointID).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return delivered, err
+			}
+			// endpoint ถูกลบไปแล้ว
+			s.DB.Model(&entity.WebhookDelivery{}).Where("id = ?", d.ID).
+				Updates(map[string]any{"status": "failed", "last_error": "endpoint deleted", "next_attempt_at": nil})
+			continue
+		}
+
+		if s.attempt(ctx, &ep, d, now) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt ยิง request 1 ครั้งแล้วบันทึกผลลง delivery log (retry ครั้งถัดไปนับ backoff จาก now)
+func (s *WebhookService) attempt(ctx context.Context, ep *entity.WebhookEndpoint, d *entity.WebhookDelivery, now time.Time) bool {
+	d.Attempts++
+	ts := time.Now().Unix()
+	body := []byte(d.Payload)
+
+	updates := map[string]any{"attempts": d.Attempts}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		// URL ใช้ไม่ได้ ไม่ต้อง retry
+		updates["status"] = "failed"
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = nil
+		s.DB.Model(&entity.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "justeat-webhooks/1.0")
+	req.Header.Set(WebhookHeaderEvent, d.Event)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhookPayload(ep.Secret, ts, body))
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		updates["last_error"] = err.Error()
+		updates["response_code"] = 0
+	} else {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		updates["response_code"] = resp.StatusCode
+		updates["response_body"] = string(respBody)
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			deliveredAt := time.Now()
+			updates["status"] = "success"
+			updates["last_error"] = ""
+			updates["delivered_at"] = &deliveredAt
+			updates["next_attempt_at"] = nil
+			s.DB.Model(&entity.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates)
+			return true
+		}
+		updates["last_error"] = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+
+	slog.WarnContext(ctx, "webhook delivery failed",
+		"deliveryId", d.ID, "endpointId", ep.ID, "attempt", d.Attempts, "error", updates["last_error"])
+	if d.Attempts >= s.MaxAttempts {
+		updates["status"] = "failed"
+		updates["next_attempt_at"] = nil
+	} else {
+		next := now.Add(s.backoff(d.Attempts))
+		updates["status"] = "pending"
+		updates["next_attempt_at"] = &next
+	}
+	s.DB.Model(&entity.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates)
+	return false
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/entity"
+	"backend/migrations"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testDBSeq atomic.Int64
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:services_%d?mode=memory&cache=shared", testDBSeq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// ผู้รับปลายทาง: ตอบตาม statuses ทีละครั้ง (หมดแล้วตอบ 200) และเก็บ request ที่ได้รับไว้ตรวจ
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	reqs     []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.reqs = append(r.reqs, receivedWebhook{header: req.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.reqs...)
+}
+
+func setupWebhook(t *testing.T, url string) (*WebhookService, *gorm.DB, entity.WebhookEndpoint) {
+	t.Helper()
+	db := newTestDB(t)
+	ep := entity.WebhookEndpoint{URL: url, Secret: "whsec_test", Events: WebhookOrderCreated, IsActive: true, RestaurantID: 1}
+	if err := db.Create(&ep).Error; err != nil {
+		t.Fatalf("create endpoint: %v", err)
+	}
+	s := NewWebhookService(db)
+	s.BaseBackoff = time.Minute
+	return s, db, ep
+}
+
+func loadDelivery(t *testing.T, db *gorm.DB) entity.WebhookDelivery {
+	t.Helper()
+	var d entity.WebhookDelivery
+	if err := db.Order("id DESC").First(&d).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	return d
+}
+
+func TestWebhookSignedDeliveryRetriesAfterServerError(t *testing.T) {
+	recv := newWebhookReceiver(t, http.StatusInternalServerError)
+	s, db, ep := setupWebhook(t, recv.URL)
+	s.HTTPClient = recv.Client() // ผู้รับอยู่บน 127.0.0.1 ซึ่ง client จริงไม่ยอมต่อ
+	ctx := context.Background()
+
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCreated, map[string]any{"orderId": 7})
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCompleted, nil) // ไม่ได้สมัคร
+
+	now := time.Now().Add(time.Second)
+	if n, err := s.DeliverDue(ctx, now); err != nil || n != 0 {
+		t.Fatalf("first DeliverDue = %d, %v; want 0, nil", n, err)
+	}
+	d := loadDelivery(t, db)
+	if d.Status != "pending" || d.Attempts != 1 || d.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("after 500: status=%s attempts=%d code=%d", d.Status, d.Attempts, d.ResponseCode)
+	}
+	if d.NextAttemptAt == nil || d.NextAttemptAt.Sub(now) != s.BaseBackoff {
+		t.Fatalf("next attempt = %v, want now+%v", d.NextAttemptAt, s.BaseBackoff)
+	}
+
+	// ยังไม่ถึงเวลา backoff = ไม่ส่ง
+	if _, err := s.DeliverDue(ctx, now.Add(s.BaseBackoff/2)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(recv.received()); got != 1 {
+		t.Fatalf("requests before backoff = %d, want 1", got)
+	}
+
+	if n, err := s.DeliverDue(ctx, now.Add(s.BaseBackoff)); err != nil || n != 1 {
+		t.Fatalf("retry DeliverDue = %d, %v; want 1, nil", n, err)
+	}
+	d = loadDelivery(t, db)
+	if d.Status != "success" || d.Attempts != 2 || d.NextAttemptAt != nil || d.DeliveredAt == nil {
+		t.Fatalf("after retry: status=%s attempts=%d next=%v delivered=%v", d.Status, d.Attempts, d.NextAttemptAt, d.DeliveredAt)
+	}
+
+	reqs := recv.received()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2", len(reqs))
+	}
+	for i, r := range reqs {
+		if ev := r.header.Get(WebhookHeaderEvent); ev != WebhookOrderCreated {
+			t.Errorf("request %d: event = %q", i, ev)
+		}
+		if id := r.header.Get(WebhookHeaderDelivery); id != strconv.FormatUint(uint64(d.ID), 10) {
+			t.Errorf("request %d: delivery = %q, want %d", i, id, d.ID)
+		}
+		if string(r.body) != d.Payload || !strings.Contains(d.Payload, `"orderId":7`) {
+			t.Errorf("request %d: body = %s, payload = %s", i, r.body, d.Payload)
+		}
+		ts, err := strconv.ParseInt(r.header.Get(WebhookHeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Fatalf("request %d: timestamp: %v", i, err)
+		}
+		sig := r.header.Get(WebhookHeaderSignature)
+		if !strings.HasPrefix(sig, "sha256=") || !VerifyWebhookSignature(ep.Secret, ts, r.body, sig) {
+			t.Errorf("request %d: bad signature %q", i, sig)
+		}
+		if VerifyWebhookSignature("whsec_other", ts, r.body, sig) {
+			t.Errorf("request %d: signature verified with wrong secret", i)
+		}
+	}
+}
+
+func TestWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	recv := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	s, db, ep := setupWebhook(t, recv.URL)
+	s.HTTPClient = recv.Client()
+	s.MaxAttempts = 2
+	ctx := context.Background()
+
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCreated, nil)
+	now := time.Now().Add(time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := s.DeliverDue(ctx, now.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := loadDelivery(t, db)
+	if d.Status != "failed" || d.Attempts != 2 || d.NextAttemptAt != nil || d.ResponseCode != http.StatusBadGateway {
+		t.Fatalf("status=%s attempts=%d next=%v code=%d", d.Status, d.Attempts, d.NextAttemptAt, d.ResponseCode)
+	}
+	if got := len(recv.received()); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+}
+
+func TestWebhookDispatchLeavesNoRowWithoutPayload(t *testing.T) {
+	s, db, ep := setupWebhook(t, "https://8.8.8.8/hook")
+	ctx := context.Background()
+
+	// data ที่ marshal ไม่ได้ → ต้อง rollback ทั้ง row
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCreated, map[string]any{"bad": make(chan int)})
+	var n int64
+	db.Model(&entity.WebhookDelivery{}).Count(&n)
+	if n != 0 {
+		t.Fatalf("deliveries after failed dispatch = %d, want 0", n)
+	}
+
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCreated, map[string]any{"orderId": 1})
+	d := loadDelivery(t, db)
+	if d.Payload == "" || d.NextAttemptAt == nil || !strings.Contains(d.Payload, fmt.Sprintf(`"id":%d`, d.ID)) {
+		t.Fatalf("delivery payload = %q next = %v", d.Payload, d.NextAttemptAt)
+	}
+}
+
+func TestWebhookClientRefusesInternalAddress(t *testing.T) {
+	recv := newWebhookReceiver(t)
+	s, db, ep := setupWebhook(t, recv.URL) // client จริงที่มี dial guard
+	ctx := context.Background()
+
+	s.Dispatch(ctx, ep.RestaurantID, WebhookOrderCreated, nil)
+	if _, err := s.DeliverDue(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(recv.received()); got != 0 {
+		t.Fatalf("receiver got %d requests, want 0", got)
+	}
+	if d := loadDelivery(t, db); !strings.Contains(d.LastError, ErrWebhookURLNotAllowed.Error()) {
+		t.Fatalf("last error = %q", d.LastError)
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://8.8.8.8/hook", true},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://localhost/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.0.1/hook", false},
+		{"http://192.168.1.10/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+	} {
+		err := CheckWebhookURL(context.Background(), tc.url)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.url, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrWebhookURLNotAllowed) {
+			t.Errorf("%s: err = %v, want ErrWebhookURLNotAllowed", tc.url, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+
+	"backend/entity"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultPassword = "secret123"
+
+// Actor = ผู้ใช้ที่ login แล้ว
+type Actor struct {
+	ID       uint
+	Email    string
+	Password string
+	Role     string
+	Token    string
+
+	// ร้าน (owner) / rider ที่ผูกกับ actor นี้
+	RestaurantID uint
+	RiderID      uint
+}
+
+func (h *Harness) nextEmail(prefix string) string {
+	h.seq++
+	return fmt.Sprintf("%s%d@testkit.local", prefix, h.seq)
+}
+
+// Register สมัครผ่าน /auth/register แล้ว login (role = customer)
+func (h *Harness) Register(email, password string) *Actor {
+	h.T.Helper()
+	h.MustDo(http.StatusCreated, "POST", "/auth/register", "", map[string]any{
+		"email":     email,
+		"password":  password,
+		"firstName": "Test",
+		"lastName":  "User",
+	})
+	a := &Actor{Email: email, Password: password}
+	h.Login(a)
+	return a
+}
+
+// Login ขอ token ใหม่ผ่าน /auth/login (ใช้หลัง role เปลี่ยนด้วย)
+func (h *Harness) Login(a *Actor) {
+	h.T.Helper()
+	var out struct {
+		Token string `json:"token"`
+		User  struct {
+			ID   uint   `json:"ID"`
+			Role string `json:"role"`
+		} `json:"user"`
+	}
+	h.MustDo(http.StatusOK, "POST", "/auth/login", "", map[string]any{
+		"email":    a.Email,
+		"password": a.Password,
+	}).JSON(&out)
+
+	a.Token = out.Token
+	a.Role = out.User.Role
+	a.ID = out.User.ID
+}
+
+// Customer ลูกค้าใหม่
+func (h *Harness) Customer() *Actor {
+	h.T.Helper()
+	return h.Register(h.nextEmail("customer"), defaultPassword)
+}
+
+// Admin ผู้ดูแลระบบ (สร้างตรงใน DB แบบเดียวกับ SeedAdmin แล้ว login ผ่าน API)
+func (h *Harness) Admin() *Actor {
+	h.T.Helper()
+	if h.admin != nil {
+		return h.admin
+	}
+
+	email := h.nextEmail("admin")
+	hash, _ := bcrypt.GenerateFromPassword([]byte(defaultPassword), bcrypt.MinCost)
+	user := entity.User{Email: email, Password: string(hash), FirstName: "Admin", LastName: "Test", Role: "admin"}
+	if err := h.DB.Create(&user).Error; err != nil {
+		h.T.Fatalf("testkit: create admin: %v", err)
+	}
+	if err := h.DB.Create(&entity.Admin{Name: "Test Admin", UserID: user.ID}).Error; err != nil {
+		h.T.Fatalf("testkit: create admin: %v", err)
+	}
+
+	a := &Actor{Email: email, Password: defaultPassword}
+	h.Login(a)
+	h.admin = a
+	return a
+}
+
+// Owner ลูกค้าสมัครเปิดร้าน → admin อนุมัติ → login ใหม่เป็น owner
+func (h *Harness) Owner() *Actor {
+	h.T.Helper()
+	a := h.Customer()
+
+	var app struct {
+		ID uint `json:"id"`
+	}
+	h.MustDo(http.StatusCreated, "POST", "/partner/restaurant-applications", a.Token, map[string]any{
+		"name":                 "ร้านทดสอบ " + a.Email,
+		"address":              "Bangkok",
+		"openingTime":          "00:00",
+		"closingTime":          "23:59",
+		"restaurantCategoryId": 1,
+		"promptPay":            "0812345678",
+	}).JSON(&app)
+
+	var approved struct {
+		RestaurantID uint `json:"restaurantId"`
+	}
+	h.MustDo(http.StatusOK, "PATCH", fmt.Sprintf("/partner/restaurant-applications/%d/approve", app.ID),
+		h.Admin().Token, map[string]any{}).JSON(&approved)
+
+	h.Login(a)
+	a.RestaurantID = approved.RestaurantID
+	return a
+}
+
+// Rider ลูกค้าสมัครเป็นไรเดอร์ → admin อนุมัติ → login ใหม่ → เปิดรับงาน (ONLINE)
+func (h *Harness) Rider() *Actor {
+	h.T.Helper()
+	a := h.Customer()
+
+	var app struct {
+		ID uint `json:"id"`
+	}
+	h.MustDo(http.StatusCreated, "POST", "/partner/rider-applications", a.Token, map[string]any{
+		"vehiclePlate": "กข 1234",
+		"license":      "L-0001",
+		"nationalId":   "1234567890123",
+		"zone":         "Bangkok",
+	}).JSON(&app)
+
+	var approved struct {
+		RiderID uint `json:"riderId"`
+	}
+	h.MustDo(http.StatusOK, "PATCH", fmt.Sprintf("/partner/rider-applications/%d/approve", app.ID),
+		h.Admin().Token, map[string]any{}).JSON(&approved)
+
+	h.Login(a)
+	a.RiderID = approved.RiderID
+	h.MustDo(http.StatusOK, "PATCH", "/rider/me/availability", a.Token, map[string]any{"status": "ONLINE"})
+	return a
+}
@@ -0,0 +1,110 @@
+package testkit_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/testkit"
+)
+
+type chatRoom struct {
+	OrderID     uint  `json:"orderId"`
+	UnreadCount int64 `json:"unreadCount"`
+	LastRead    uint  `json:"lastReadMessageId"`
+	LastMessage *struct {
+		ID uint `json:"ID"`
+	} `json:"lastMessage"`
+}
+
+func TestChatUnreadRoomsAndReceipts(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) {
+		f := testkit.FullFlow(t) // order แรกส่งเสร็จแล้ว (Completed)
+		h, cust, rider := f.H, f.Customer, f.Rider
+
+		// order ที่สองยังไม่จบ (ไรเดอร์กำลังส่ง)
+		h.MustDo(http.StatusCreated, "POST", "/cart/items", cust.Token, map[string]any{
+			"restaurantId": f.Owner.RestaurantID, "menuId": f.MenuID, "qty": 1,
+		})
+		var active struct {
+			ID uint `json:"id"`
+		}
+		h.MustDo(http.StatusCreated, "POST", "/orders/checkout-from-cart", cust.Token, map[string]any{
+			"address": "123 ถนนทดสอบ", "paymentMethod": "Cash on Delivery",
+		}).JSON(&active)
+		h.MustDo(http.StatusNoContent, "POST", fmt.Sprintf("/owner/orders/%d/accept", active.ID), f.Owner.Token, nil)
+		h.MustDo(http.StatusOK, "POST", fmt.Sprintf("/rider/works/%d/accept", active.ID), rider.Token, nil)
+
+		send := func(a *testkit.Actor, orderID uint, body string) uint {
+			t.Helper()
+			var out struct {
+				Message struct {
+					ID uint `json:"ID"`
+				} `json:"message"`
+			}
+			h.MustDo(http.StatusOK, "POST", fmt.Sprintf("/orders/%d/messages", orderID), a.Token, map[string]any{"body": body}).JSON(&out)
+			return out.Message.ID
+		}
+		rooms := func(a *testkit.Actor, query string) []chatRoom {
+			t.Helper()
+			var out struct {
+				Rooms []chatRoom `json:"rooms"`
+			}
+			h.MustDo(http.StatusOK, "GET", "/chatrooms"+query, a.Token, nil).JSON(&out)
+			return out.Rooms
+		}
+		read := func(a *testkit.Actor, orderID, messageID uint) uint {
+			t.Helper()
+			var out struct {
+				Read struct {
+					LastReadMessageID uint `json:"lastReadMessageId"`
+				} `json:"read"`
+			}
+			h.MustDo(http.StatusOK, "POST", fmt.Sprintf("/orders/%d/messages/read", orderID), a.Token, map[string]any{"messageId": messageID}).JSON(&out)
+			return out.Read.LastReadMessageID
+		}
+
+		send(rider, f.OrderID, "ถึงแล้วครับ")
+		send(cust, f.OrderID, "ขอบคุณค่ะ")
+		r2 := send(rider, active.ID, "รับของแล้ว")
+		send(cust, active.ID, "โอเคค่ะ")
+		r3 := send(rider, active.ID, "อีก 5 นาที")
+
+		// ค่า default = เฉพาะ order ที่ยังไม่จบ; unread ไม่นับข้อความของตัวเอง
+		got := rooms(cust, "")
+		if len(got) != 1 || got[0].OrderID != active.ID || got[0].UnreadCount != 2 {
+			t.Fatalf("customer rooms = %+v, want only order %d with 2 unread", got, active.ID)
+		}
+
+		// all=true รวม order ที่จบแล้ว เรียงตามข้อความล่าสุด
+		got = rooms(cust, "?all=true")
+		if len(got) != 2 || got[0].OrderID != active.ID || got[1].OrderID != f.OrderID {
+			t.Fatalf("customer rooms (all) = %+v, want [%d %d]", got, active.ID, f.OrderID)
+		}
+		if got[1].UnreadCount != 1 || got[0].LastMessage == nil || got[0].LastMessage.ID != r3 {
+			t.Fatalf("customer rooms (all) = %+v", got)
+		}
+		last := send(cust, f.OrderID, "ฝากไว้หน้าบ้านนะคะ") // order เก่ามีข้อความใหม่กว่า → ขึ้นก่อน
+		if got = rooms(cust, "?all=true"); got[0].OrderID != f.OrderID || got[0].LastMessage.ID != last {
+			t.Fatalf("customer rooms after new message = %+v, want order %d first", got, f.OrderID)
+		}
+		if got = rooms(rider, "?all=true"); len(got) != 2 || got[0].UnreadCount != 2 || got[1].UnreadCount != 1 {
+			t.Fatalf("rider rooms = %+v, want unread 2 (order %d) and 1 (order %d)", got, f.OrderID, active.ID)
+		}
+
+		// read receipt ไม่ถอยหลัง
+		if id := read(cust, active.ID, r3); id != r3 {
+			t.Fatalf("read to %d: last read = %d", r3, id)
+		}
+		if id := read(cust, active.ID, r2); id != r3 {
+			t.Fatalf("read back to %d: last read = %d, want %d", r2, id, r3)
+		}
+		if id := read(cust, active.ID, 0); id != r3 {
+			t.Fatalf("read latest: last read = %d, want %d", id, r3)
+		}
+		got = rooms(cust, "")
+		if got[0].UnreadCount != 0 || got[0].LastRead != r3 {
+			t.Fatalf("customer room after read = %+v, want 0 unread up to %d", got[0], r3)
+		}
+	})
+}
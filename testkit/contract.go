@@ -0,0 +1,145 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"backend/routes"
+)
+
+// EnableContract ตรวจทุก response ที่ยิงผ่าน Do กับเอกสาร OpenAPI (ไม่ตรง = t.Errorf)
+// และ fail ทันทีถ้าเอกสารไม่ครบ/ค้างเมื่อเทียบกับ route จริง
+func (h *Harness) EnableContract() {
+	h.T.Helper()
+	doc, problems := routes.OpenAPI(h.Router)
+	for _, p := range problems {
+		h.T.Errorf("testkit: openapi: %s", p)
+	}
+	h.contract = doc
+}
+
+// checkContract หา route ของ request แล้วตรวจ response กับเอกสาร
+func (h *Harness) checkContract(method, path string, res *Response) {
+	h.T.Helper()
+	route := h.routeOf(method, path)
+	if route == "" {
+		return // 404 ของ router เอง
+	}
+	for _, p := range h.contract.CheckResponse(method, route, res.Code, res.Body) {
+		h.T.Errorf("testkit: contract: %s %s (%d): %s", method, path, res.Code, p)
+	}
+}
+
+// routeOf = path แบบที่ประกาศกับ gin ที่ตรงกับ URL (segment คงที่ชนะ :param)
+func (h *Harness) routeOf(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segs := strings.Split(path, "/")
+
+	best, bestScore := "", -1
+	for _, r := range h.Router.Routes() {
+		if r.Method != method {
+			continue
+		}
+		if score := matchRoute(strings.Split(r.Path, "/"), segs); score > bestScore {
+			best, bestScore = r.Path, score
+		}
+	}
+	return best
+}
+
+// matchRoute คืนจำนวน segment คงที่ที่ตรง (-1 = ไม่ตรง)
+func matchRoute(pattern, segs []string) int {
+	score := 0
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "*") {
+			return score
+		}
+		if i >= len(segs) {
+			return -1
+		}
+		switch {
+		case strings.HasPrefix(p, ":"):
+			if segs[i] == "" {
+				return -1
+			}
+		case p == segs[i]:
+			score++
+		default:
+			return -1
+		}
+	}
+	if len(pattern) != len(segs) {
+		return -1
+	}
+	return score
+}
+
+// Contract ยิง scenario หลักทั้งหมด + GET ของแต่ละ role บน harness ที่เปิดตรวจเอกสารไว้
+// response ที่ไม่ตรงกับ /openapi.json จะทำให้ test fail
+//
+//	func TestContract(t *testing.T) {
+//		testkit.Matrix(t, func(t *testing.T) { testkit.Contract(t) })
+//	}
+func Contract(t testing.TB) {
+	t.Helper()
+	h := New(t)
+	h.EnableContract()
+
+	f := fullFlow(t, h)
+	cod := codFlow(t, h)
+	cust, owner, rider, admin := f.Customer, f.Owner, f.Rider, h.Admin()
+	rid := owner.RestaurantID
+
+	gets := []struct {
+		path  string
+		token string
+	}{
+		{"/auth/me", cust.Token},
+		{"/auth/me/avatar", cust.Token},
+		{"/auth/me/restaurant", owner.Token},
+		{"/restaurants", ""},
+		{fmt.Sprintf("/restaurants/%d", rid), ""},
+		{fmt.Sprintf("/restaurants/%d/menus", rid), ""},
+		{fmt.Sprintf("/menus/%d", f.MenuID), ""},
+		{"/promotions", ""},
+		{"/orders/profile", cust.Token},
+		{fmt.Sprintf("/orders/%d", f.OrderID), cust.Token},
+		{fmt.Sprintf("/orders/%d/chatroom", f.OrderID), cust.Token},
+		{fmt.Sprintf("/orders/%d/messages", f.OrderID), cust.Token},
+		{fmt.Sprintf("/api/orders/%d/payment-intent", cod.OrderID), cod.Customer.Token},
+		{fmt.Sprintf("/api/orders/%d/payment-summary", f.OrderID), cust.Token},
+		{"/cart", cust.Token},
+		{"/notifications", cust.Token},
+		{"/notifications/unread-count", cust.Token},
+		{"/notifications/preferences", cust.Token},
+		{"/profile/reviews", cust.Token},
+		{"/user/promotions", cust.Token},
+		{"/reports", cust.Token},
+		{fmt.Sprintf("/owner/restaurants/%d/orders", rid), owner.Token},
+		{fmt.Sprintf("/owner/restaurants/%d/orders/scheduled", rid), owner.Token},
+		{fmt.Sprintf("/owner/restaurants/%d/orders/%d", rid, f.OrderID), owner.Token},
+		{fmt.Sprintf("/owner/restaurants/%d/webhooks", rid), owner.Token},
+		{"/rider/me", rider.Token},
+		{"/rider/me/status", rider.Token},
+		{"/rider/works", rider.Token},
+		{"/rider/works/current", rider.Token},
+		{"/rider/works/available", rider.Token},
+		{"/partner/rider-applications/mine", rider.Token},
+		{"/partner/restaurant-applications", admin.Token},
+		{"/partner/rider-applications", admin.Token},
+		{"/admin/dashboard", admin.Token},
+		{"/admin/restaurant", admin.Token},
+		{"/admin/rider", admin.Token},
+		{"/admin/reports", admin.Token},
+		{"/admin/promotion", admin.Token},
+	}
+	for _, g := range gets {
+		h.MustDo(http.StatusOK, "GET", g.path, g.token, nil)
+	}
+
+	// error envelope ก็ต้องตรงเอกสาร
+	h.MustDo(http.StatusNotFound, "GET", "/orders/999999", cust.Token, nil)
+	h.MustDo(http.StatusUnauthorized, "GET", "/auth/me", "", nil)
+}
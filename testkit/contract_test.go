@@ -0,0 +1,12 @@
+package testkit_test
+
+import (
+	"testing"
+
+	"backend/testkit"
+)
+
+// response จริงต้องตรงกับ /openapi.json (และเอกสารต้องครบทุก route)
+func TestContract(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) { testkit.Contract(t) })
+}
@@ -0,0 +1,33 @@
+package testkit_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"backend/testkit"
+)
+
+// error จาก input ของผู้ใช้ต้องได้ 400 พร้อม code ของตัวเอง ไม่ใช่ 500 internal_error
+func TestInputErrorsHaveCodes(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) {
+		f := testkit.FullFlow(t)
+		h, cust := f.H, f.Customer
+
+		for _, tc := range []struct {
+			path string
+			body map[string]any
+			code string
+		}{
+			{fmt.Sprintf("/orders/%d/messages", f.OrderID), map[string]any{"body": ""}, "empty_message"},
+			{"/auth/me/avatar", map[string]any{"avatarBase64": "aGVsbG8="}, "invalid_avatar_format"},
+			{"/auth/me/avatar", map[string]any{"avatarBase64": "data:image/png;base64," + strings.Repeat("A", 11<<20)}, "avatar_too_large"},
+		} {
+			res := h.MustDo(http.StatusBadRequest, "POST", tc.path, cust.Token, tc.body)
+			if e := res.Err(); e == nil || e.Code != tc.code {
+				t.Errorf("POST %s: error = %+v, want code %q", tc.path, e, tc.code)
+			}
+		}
+	})
+}
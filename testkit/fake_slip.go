@@ -0,0 +1,54 @@
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"backend/services"
+)
+
+// FakeSlipVerifier แทน EasySlip: คืนสลิปตามยอดที่ตั้งไว้ หรือ error ที่กำหนด
+type FakeSlipVerifier struct {
+	mu     sync.Mutex
+	amount float64
+	err    error
+	calls  int
+}
+
+// Accept ให้สลิปถัดไปผ่านด้วยยอด (บาท) ที่กำหนด
+func (f *FakeSlipVerifier) Accept(amountBaht float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.amount, f.err = amountBaht, nil
+}
+
+// Fail ให้การตรวจถัดไปตอบ error จากผู้ให้บริการ (เช่น "qrcode_not_found")
+func (f *FakeSlipVerifier) Fail(code string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = &services.SlipVerifyError{Code: code}
+}
+
+// Calls จำนวนครั้งที่ถูกเรียก
+func (f *FakeSlipVerifier) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *FakeSlipVerifier) Verify(ctx context.Context, imageBase64 string, checkDuplicate bool) (*services.EasySlipData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	slip := &services.EasySlipData{
+		TransRef: fmt.Sprintf("FAKE-%d", f.calls),
+		Date:     "2024-01-01T00:00:00+07:00",
+	}
+	slip.Amount.Amount = f.amount
+	return slip, nil
+}
@@ -0,0 +1,17 @@
+package testkit_test
+
+import (
+	"testing"
+
+	"backend/testkit"
+)
+
+// flow หลักรันกับทุก driver ที่ตั้ง DSN ไว้ (ดู testkit.Drivers)
+
+func TestFullFlow(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) { testkit.FullFlow(t) })
+}
+
+func TestCashOnDeliveryFlow(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) { testkit.CashOnDeliveryFlow(t) })
+}
@@ -0,0 +1,288 @@
+// Package testkit ประกอบ backend ทั้งชุดบน DB ทดสอบ สำหรับทดสอบแบบ end-to-end ผ่าน HTTP
+//
+// ใช้จากไฟล์ _test.go (ดู flow_test.go):
+//
+//	func TestFullFlow(t *testing.T) {
+//		testkit.Matrix(t, func(t *testing.T) { testkit.FullFlow(t) })
+//	}
+//
+// ค่า default คือ SQLite ใน memory; ถ้าจะรันบน Postgres/MySQL:
+//
+//	TESTKIT_DB_DRIVER=postgres TESTKIT_DB_DSN="host=localhost user=test dbname=test" go test ./...
+//
+// หรือใช้ Matrix เพื่อรัน test เดียวกันกับทุก driver ที่ตั้ง DSN ไว้ (ไม่ตั้ง = sqlite อย่างเดียว):
+//
+//	TESTKIT_POSTGRES_DSN="host=localhost user=test password=test dbname=test sslmode=disable" \
+//	TESTKIT_MYSQL_DSN="test:test@tcp(localhost:3306)/test?parseTime=True&charset=utf8mb4" \
+//	go test -p 1 ./...
+//
+// CI รันแบบนี้ใน job "drivers" ของ .github/workflows/test.yml (Postgres + MySQL เป็น service container)
+// database ของ DSN จะถูกล้างทุกตารางทุกครั้งที่สร้าง harness — ห้ามชี้ไปที่ database ที่มีข้อมูลจริง
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/configs"
+	"backend/migrations"
+	"backend/openapi"
+	"backend/pkg/lifecycle"
+	"backend/pkg/logx"
+	"backend/pkg/resp"
+	"backend/routes"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var dbSeq atomic.Int64
+
+// Driver = DB ที่ harness ใช้ (DSN ว่างได้เฉพาะ sqlite)
+type Driver struct {
+	Name string
+	DSN  string
+}
+
+// Drivers คืน driver ทั้งหมดที่รันได้ในเครื่องนี้: sqlite เสมอ + ตัวที่ตั้ง DSN ไว้
+func Drivers() []Driver {
+	ds := []Driver{{Name: "sqlite"}}
+	if dsn := os.Getenv("TESTKIT_POSTGRES_DSN"); dsn != "" {
+		ds = append(ds, Driver{Name: "postgres", DSN: dsn})
+	}
+	if dsn := os.Getenv("TESTKIT_MYSQL_DSN"); dsn != "" {
+		ds = append(ds, Driver{Name: "mysql", DSN: dsn})
+	}
+	return ds
+}
+
+// Matrix รัน fn เป็น subtest ต่อ driver; New ภายใน fn จะใช้ driver ของ subtest นั้น
+func Matrix(t *testing.T, fn func(t *testing.T)) {
+	for _, d := range Drivers() {
+		t.Run(d.Name, func(t *testing.T) {
+			t.Setenv("TESTKIT_DB_DRIVER", d.Name)
+			t.Setenv("TESTKIT_DB_DSN", d.DSN)
+			fn(t)
+		})
+	}
+}
+
+func driverFromEnv() Driver {
+	name := os.Getenv("TESTKIT_DB_DRIVER")
+	if name == "" {
+		name = "sqlite"
+	}
+	return Driver{Name: name, DSN: os.Getenv("TESTKIT_DB_DSN")}
+}
+
+// Harness = router จริง + DB ทดสอบ + fake ของบริการภายนอก
+type Harness struct {
+	T      testing.TB
+	DB     *gorm.DB
+	Router *gin.Engine
+	Config *configs.Config
+	Slips  *FakeSlipVerifier
+
+	// worker เบื้องหลังของ harness (Shutdown เองได้ เช่น ทดสอบการปิด WebSocket)
+	Lifecycle *lifecycle.Manager
+
+	admin    *Actor
+	seq      int
+	contract *openapi.Document // ไม่ nil = ตรวจ response กับเอกสาร (EnableContract)
+}
+
+// New สร้าง harness ใหม่ (DB แยกกันทุกครั้ง) และปิด DB ให้เองตอนจบ test
+func New(t testing.TB) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	d := driverFromEnv()
+	var db *gorm.DB
+	if d.Name == "sqlite" {
+		db, d.DSN = openSQLite(t)
+	} else {
+		db = openExternal(t, d)
+	}
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("testkit: migrate: %v", err)
+	}
+	if err := configs.SeedLookupTables(db); err != nil {
+		t.Fatalf("testkit: seed lookups: %v", err)
+	}
+
+	cfg := configs.Default("test")
+	cfg.DBDriver = d.Name
+	cfg.DBSource = d.DSN
+	cfg.JWTSecret = "testkit-secret"
+	cfg.JWTTTL = time.Hour
+	if _, err := logx.Setup(os.Stderr, cfg.LogFormat, cfg.LogLevel); err != nil {
+		t.Fatalf("testkit: logger: %v", err)
+	}
+	slips := &FakeSlipVerifier{}
+
+	// หยุด chat hub / scheduler ของ harness นี้ตอนจบ test (ก่อนปิด DB เพราะ Cleanup รันย้อนลำดับ)
+	lc := lifecycle.New()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := lc.Shutdown(ctx); err != nil {
+			t.Errorf("testkit: shutdown: %v", err)
+		}
+	})
+
+	r := gin.New()
+	routes.RegisterRoutes(r, db, cfg, routes.WithSlipVerifier(slips), routes.WithLifecycle(lc))
+
+	return &Harness{T: t, DB: db, Router: r, Config: cfg, Slips: slips, Lifecycle: lc}
+}
+
+// SQLite ใน memory: ชื่อไม่ซ้ำกันต่อ harness จึงรัน test แบบ parallel ได้
+func openSQLite(t testing.TB) (*gorm.DB, string) {
+	dsn := fmt.Sprintf("file:testkit_%d?mode=memory&cache=shared", dbSeq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("testkit: open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("testkit: sql db: %v", err)
+	}
+	// ใช้ connection เดียวกันทั้งหมด กัน "database table is locked"
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db, dsn
+}
+
+// Postgres/MySQL: ใช้ database เดียวกันทุก harness → ลบตารางทิ้งก่อนเริ่ม
+// (test ที่ใช้ driver นี้ห้ามรันแบบ parallel)
+func openExternal(t testing.TB, d Driver) *gorm.DB {
+	if d.DSN == "" {
+		t.Fatalf("testkit: TESTKIT_DB_DSN is required for driver %s", d.Name)
+	}
+	db, err := configs.Open(&configs.Config{DBDriver: d.Name, DBSource: d.DSN, DBMaxOpenConns: 5})
+	if err != nil {
+		t.Fatalf("testkit: open %s: %v", d.Name, err)
+	}
+	db = db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("testkit: sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// ลบจากตารางลูกไปหาตารางแม่ (ย้อนลำดับของ Models)
+	models := configs.Models()
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := db.Migrator().DropTable(models[i]); err != nil {
+			t.Fatalf("testkit: drop table: %v", err)
+		}
+	}
+	// ที่เหลือ: schema_migrations + ตารางที่มีแต่ใน migration (ไม่อยู่ใน Models)
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("testkit: list tables: %v", err)
+	}
+	for _, name := range tables {
+		if err := db.Migrator().DropTable(name); err != nil {
+			t.Fatalf("testkit: drop table %s: %v", name, err)
+		}
+	}
+	return db
+}
+
+// ---------------- HTTP ----------------
+
+type Response struct {
+	t      testing.TB
+	Code   int
+	Header http.Header
+	Body   []byte
+}
+
+// Envelope = resp.Envelope ฝั่งอ่าน (data เก็บเป็น raw JSON ไว้ decode ต่อ)
+type Envelope struct {
+	OK        bool            `json:"ok"`
+	Data      json.RawMessage `json:"data"`
+	Error     *resp.APIError  `json:"error"`
+	RequestID string          `json:"requestId"`
+}
+
+// Envelope decode body ทั้งก้อน
+func (r *Response) Envelope() (env Envelope) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, &env); err != nil {
+		r.t.Fatalf("testkit: decode %s: %v", r.Body, err)
+	}
+	return env
+}
+
+// JSON decode data ของ envelope ลง v (fail test ถ้า decode ไม่ได้)
+func (r *Response) JSON(v any) {
+	r.t.Helper()
+	env := r.Envelope()
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		r.t.Fatalf("testkit: decode data %s: %v", env.Data, err)
+	}
+}
+
+// Map decode data เป็น map (สะดวกกับ response แบบ gin.H)
+func (r *Response) Map() map[string]any {
+	r.t.Helper()
+	var m map[string]any
+	r.JSON(&m)
+	return m
+}
+
+// Err = error ใน envelope (nil ถ้าสำเร็จ)
+func (r *Response) Err() *resp.APIError {
+	r.t.Helper()
+	return r.Envelope().Error
+}
+
+// Do ยิง request เข้า router ตรง ๆ (token ว่าง = ไม่แนบ Authorization)
+func (h *Harness) Do(method, path, token string, body any) *Response {
+	h.T.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			h.T.Fatalf("testkit: encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+	res := &Response{t: h.T, Code: w.Code, Header: w.Header(), Body: w.Body.Bytes()}
+	if h.contract != nil {
+		h.checkContract(method, path, res)
+	}
+	return res
+}
+
+// MustDo เหมือน Do แต่ fail test ทันทีถ้า status ไม่ตรง
+func (h *Harness) MustDo(want int, method, path, token string, body any) *Response {
+	h.T.Helper()
+	res := h.Do(method, path, token, body)
+	if res.Code != want {
+		h.T.Fatalf("testkit: %s %s: want %d, got %d: %s", method, path, want, res.Code, res.Body)
+	}
+	return res
+}
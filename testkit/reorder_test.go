@@ -0,0 +1,147 @@
+package testkit_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/lookups"
+	"backend/testkit"
+)
+
+type reorderRes struct {
+	Added   int `json:"added"`
+	Changed []struct {
+		MenuID   uint  `json:"menuId"`
+		OldPrice int64 `json:"oldPrice"`
+		NewPrice int64 `json:"newPrice"`
+	} `json:"changed"`
+	Dropped []struct {
+		MenuID uint   `json:"menuId"`
+		Reason string `json:"reason"`
+	} `json:"dropped"`
+}
+
+type cartRes struct {
+	Cart struct {
+		RestaurantID uint `json:"restaurantId"`
+		Items        []struct {
+			MenuID    uint  `json:"menuId"`
+			Qty       int   `json:"qty"`
+			UnitPrice int64 `json:"unitPrice"`
+		} `json:"items"`
+	}
+}
+
+// Reorder: ราคาเปลี่ยน / เมนูหมด / เมนูถูกลบ, รวมกับตะกร้าร้านเดิม, ตะกร้าร้านอื่น (conflict → replaceCart)
+// และ order ที่ไม่มีอะไรสั่งซ้ำได้เลย
+func TestReorder(t *testing.T) {
+	testkit.Matrix(t, func(t *testing.T) {
+		h := testkit.New(t)
+		owner, other, cust := h.Owner(), h.Owner(), h.Customer()
+
+		menu := func(o *testkit.Actor, name string, price int64) uint {
+			t.Helper()
+			var m struct {
+				ID uint `json:"ID"`
+			}
+			h.MustDo(http.StatusCreated, "POST", fmt.Sprintf("/owner/restaurants/%d/menus", o.RestaurantID), o.Token, map[string]any{
+				"name": name, "price": price, "menuTypeId": 1, "menuStatusId": lookups.ID(lookups.MenuAvailable),
+			}).JSON(&m)
+			return m.ID
+		}
+		addToCart := func(o *testkit.Actor, menuID uint, qty int) {
+			t.Helper()
+			h.MustDo(http.StatusCreated, "POST", "/cart/items", cust.Token, map[string]any{
+				"restaurantId": o.RestaurantID, "menuId": menuID, "qty": qty,
+			})
+		}
+		checkout := func() uint {
+			t.Helper()
+			var order struct {
+				ID uint `json:"id"`
+			}
+			h.MustDo(http.StatusCreated, "POST", "/orders/checkout-from-cart", cust.Token, map[string]any{
+				"address": "123 ถนนทดสอบ", "paymentMethod": "Cash on Delivery",
+			}).JSON(&order)
+			return order.ID
+		}
+		reorder := func(status int, orderID uint, replace bool) *testkit.Response {
+			t.Helper()
+			return h.MustDo(status, "POST", fmt.Sprintf("/orders/%d/reorder", orderID), cust.Token, map[string]any{"replaceCart": replace})
+		}
+		cart := func() cartRes {
+			t.Helper()
+			var c cartRes
+			h.MustDo(http.StatusOK, "GET", "/cart", cust.Token, nil).JSON(&c)
+			return c
+		}
+
+		kept, soldOut, gone := menu(owner, "ข้าวผัด", 60), menu(owner, "ต้มยำ", 80), menu(owner, "ไข่เจียว", 30)
+		elsewhere := menu(other, "ชาเย็น", 25)
+
+		addToCart(owner, kept, 2)
+		addToCart(owner, soldOut, 1)
+		addToCart(owner, gone, 1)
+		mixed := checkout()
+		addToCart(owner, soldOut, 1)
+		onlySoldOut := checkout()
+
+		// หลังสั่ง: ขึ้นราคา / ของหมด / ลบเมนู
+		h.MustDo(http.StatusOK, "PATCH", fmt.Sprintf("/owner/menus/%d", kept), owner.Token, map[string]any{
+			"name": "ข้าวผัด", "price": 70, "menuTypeId": 1, "menuStatusId": lookups.ID(lookups.MenuAvailable),
+		})
+		h.MustDo(http.StatusOK, "PATCH", fmt.Sprintf("/owner/menus/%d/status", soldOut), owner.Token, map[string]any{
+			"menuStatusId": lookups.ID(lookups.MenuOutOfStock),
+		})
+		h.MustDo(http.StatusOK, "DELETE", fmt.Sprintf("/owner/menus/%d", gone), owner.Token, nil)
+
+		// ไม่มีอะไรสั่งซ้ำได้ → 422 พร้อมรายการที่ตกไป และตะกร้าไม่ถูกแตะ
+		res := reorder(http.StatusUnprocessableEntity, onlySoldOut, false)
+		if e := res.Err(); e == nil || e.Code != "nothing_to_reorder" {
+			t.Fatalf("nothing to reorder: error = %+v", e)
+		} else if dropped, _ := e.Meta["dropped"].([]any); len(dropped) != 1 {
+			t.Fatalf("nothing to reorder: dropped = %v", e.Meta["dropped"])
+		}
+		if c := cart(); len(c.Cart.Items) != 0 {
+			t.Fatalf("cart after failed reorder = %+v", c.Cart.Items)
+		}
+
+		// ตะกร้ามีของร้านเดิม → รวม qty (ราคาปัจจุบัน) และข้ามเมนูที่หมด/ถูกลบ
+		addToCart(owner, kept, 1)
+		var merged reorderRes
+		reorder(http.StatusOK, mixed, false).JSON(&merged)
+		if merged.Added != 1 || len(merged.Changed) != 1 || merged.Changed[0].MenuID != kept ||
+			merged.Changed[0].OldPrice != 60 || merged.Changed[0].NewPrice != 70 {
+			t.Fatalf("merge: result = %+v", merged)
+		}
+		reasons := map[uint]string{}
+		for _, d := range merged.Dropped {
+			reasons[d.MenuID] = d.Reason
+		}
+		if len(reasons) != 2 || reasons[soldOut] != "out_of_stock" || reasons[gone] != "deleted" {
+			t.Fatalf("merge: dropped = %+v", merged.Dropped)
+		}
+		if c := cart(); len(c.Cart.Items) != 1 || c.Cart.Items[0].Qty != 3 || c.Cart.Items[0].UnitPrice != 70 {
+			t.Fatalf("merge: cart = %+v, want %d x3 @70", c.Cart.Items, kept)
+		}
+
+		// ตะกร้ามีของร้านอื่น → 409 จนกว่าจะยืนยัน replaceCart
+		h.MustDo(http.StatusOK, "DELETE", "/cart", cust.Token, nil)
+		addToCart(other, elsewhere, 1)
+		res = reorder(http.StatusConflict, mixed, false)
+		if e := res.Err(); e == nil || e.Code != "cart_conflict" || e.Meta["cartRestaurantId"] != float64(other.RestaurantID) {
+			t.Fatalf("conflict: error = %+v", e)
+		}
+		if c := cart(); c.Cart.RestaurantID != other.RestaurantID || len(c.Cart.Items) != 1 {
+			t.Fatalf("conflict: cart changed to %+v", c)
+		}
+		var replaced reorderRes
+		reorder(http.StatusOK, mixed, true).JSON(&replaced)
+		c := cart()
+		if replaced.Added != 1 || c.Cart.RestaurantID != owner.RestaurantID || len(c.Cart.Items) != 1 ||
+			c.Cart.Items[0].MenuID != kept || c.Cart.Items[0].Qty != 2 {
+			t.Fatalf("replace: result = %+v cart = %+v", replaced, c)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/entity"
+	"backend/lookups"
+)
+
+// FlowResult สิ่งที่ scenario สร้างไว้ (ให้ test ตรวจต่อได้)
+type FlowResult struct {
+	H        *Harness
+	Customer *Actor
+	Owner    *Actor
+	Rider    *Actor
+	MenuID   uint
+	OrderID  uint
+	Total    int64
+}
+
+// FullFlow: สมัคร+อนุมัติร้าน → สร้างเมนู → ใส่ตะกร้า → checkout (PromptPay)
+// → ร้านรับ → ไรเดอร์รับ+ส่ง → ชำระด้วยสลิป → รีวิว
+func FullFlow(t testing.TB) *FlowResult {
+	t.Helper()
+	return fullFlow(t, New(t))
+}
+
+func fullFlow(t testing.TB, h *Harness) *FlowResult {
+	t.Helper()
+	f := orderFlow(t, h, "PromptPay")
+	cust := f.Customer
+
+	// ชำระเงินด้วยสลิป (fake EasySlip ตอบยอดตรง)
+	h.Slips.Accept(float64(f.Total))
+	h.MustDo(http.StatusOK, "POST", "/api/payments/verify-easyslip", cust.Token, map[string]any{
+		"orderId":     f.OrderID,
+		"amount":      f.Total,
+		"contentType": "image/png",
+		"slipBase64":  "data:image/png;base64,aGVsbG8=",
+	})
+	summary := h.MustDo(http.StatusOK, "GET", fmt.Sprintf("/api/orders/%d/payment-summary", f.OrderID), cust.Token, nil).Map()
+	if summary["paidAmount"] != float64(f.Total) {
+		t.Fatalf("testkit: paidAmount = %v, want %d", summary["paidAmount"], f.Total)
+	}
+
+	review(t, f)
+	return f
+}
+
+// CashOnDeliveryFlow: เหมือน FullFlow แต่จ่ายปลายทาง (ไรเดอร์ส่งเสร็จ = ชำระแล้ว)
+func CashOnDeliveryFlow(t testing.TB) *FlowResult {
+	t.Helper()
+	return codFlow(t, New(t))
+}
+
+func codFlow(t testing.TB, h *Harness) *FlowResult {
+	t.Helper()
+	f := orderFlow(t, h, "Cash on Delivery")
+
+	var p entity.Payment
+	f.H.DB.Preload("PaymentStatus").Where("order_id = ?", f.OrderID).First(&p)
+	if p.PaymentStatus.StatusName != "Paid" {
+		t.Fatalf("testkit: COD payment status = %q, want Paid", p.PaymentStatus.StatusName)
+	}
+
+	review(t, f)
+	return f
+}
+
+// orderFlow ส่วนที่เหมือนกันของทุก scenario: ตั้งร้าน → สั่ง → ส่งจนเสร็จ
+func orderFlow(t testing.TB, h *Harness, paymentMethod string) *FlowResult {
+	t.Helper()
+	owner := h.Owner()
+	rider := h.Rider()
+	cust := h.Customer()
+
+	// เจ้าของร้านสร้างเมนู
+	var menu struct {
+		ID uint `json:"ID"`
+	}
+	h.MustDo(http.StatusCreated, "POST", fmt.Sprintf("/owner/restaurants/%d/menus", owner.RestaurantID), owner.Token, map[string]any{
+		"name":         "ข้าวผัด",
+		"price":        60,
+		"menuTypeId":   1,
+		"menuStatusId": 1,
+	}).JSON(&menu)
+
+	// ลูกค้าใส่ตะกร้าแล้ว checkout
+	h.MustDo(http.StatusCreated, "POST", "/cart/items", cust.Token, map[string]any{
+		"restaurantId": owner.RestaurantID,
+		"menuId":       menu.ID,
+		"qty":          2,
+	})
+	var order struct {
+		ID    uint  `json:"id"`
+		Total int64 `json:"total"`
+	}
+	h.MustDo(http.StatusCreated, "POST", "/orders/checkout-from-cart", cust.Token, map[string]any{
+		"address":       "123 ถนนทดสอบ",
+		"paymentMethod": paymentMethod,
+		"deliveryFee":   15,
+	}).JSON(&order)
+	if order.Total != 60*2+15 {
+		t.Fatalf("testkit: order total = %d, want %d", order.Total, 60*2+15)
+	}
+
+	// ร้านรับ → ไรเดอร์รับ → ส่งเสร็จ
+	h.MustDo(http.StatusNoContent, "POST", fmt.Sprintf("/owner/orders/%d/accept", order.ID), owner.Token, nil)
+	h.MustDo(http.StatusOK, "POST", fmt.Sprintf("/rider/works/%d/accept", order.ID), rider.Token, nil)
+	h.MustDo(http.StatusOK, "POST", fmt.Sprintf("/rider/works/%d/complete", order.ID), rider.Token, nil)
+
+	var detail struct {
+		OrderStatusID uint `json:"orderStatusId"`
+	}
+	h.MustDo(http.StatusOK, "GET", fmt.Sprintf("/orders/%d", order.ID), cust.Token, nil).JSON(&detail)
+	if want := lookups.ID(lookups.OrderCompleted); detail.OrderStatusID != want {
+		t.Fatalf("testkit: order status = %d, want Completed (%d)", detail.OrderStatusID, want)
+	}
+
+	return &FlowResult{
+		H: h, Customer: cust, Owner: owner, Rider: rider,
+		MenuID: menu.ID, OrderID: order.ID, Total: order.Total,
+	}
+}
+
+func review(t testing.TB, f *FlowResult) {
+	t.Helper()
+	h := f.H
+	h.MustDo(http.StatusOK, "POST", "/reviews", f.Customer.Token, map[string]any{
+		"orderId":  f.OrderID,
+		"rating":   5,
+		"comments": "อร่อยมาก",
+	})
+
+	var reviews struct {
+		Total int64 `json:"total"`
+	}
+	h.MustDo(http.StatusOK, "GET", fmt.Sprintf("/restaurants/%d/reviews", f.Owner.RestaurantID), "", nil).JSON(&reviews)
+	if reviews.Total != 1 {
+		t.Fatalf("testkit: restaurant reviews = %d, want 1", reviews.Total)
+	}
+}
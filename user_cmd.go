@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"backend/configs"
+	"backend/repository"
+	"backend/services"
+)
+
+func authService(cfg *configs.Config) *services.AuthService {
+	return services.NewAuthService(repository.NewUserRepository(configs.DB()), cfg.JWTSecret, cfg.JWTTTL)
+}
+
+// ไม่ส่ง -password มา = อ่านจาก stdin (กันรหัสผ่านค้างใน shell history / ps)
+func readPassword(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+// runCreateAdmin = backend create-admin -email x [-password y] [-name z]
+func runCreateAdmin(args []string) {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "admin email (required)")
+	password := fs.String("password", "", "password (read from stdin if omitted)")
+	name := fs.String("name", "System Admin", "admin display name")
+	fs.Parse(args)
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := bootstrap()
+	user, err := authService(cfg).CreateAdmin(*email, readPassword(*password), *name)
+	if err != nil {
+		log.Fatalf("create-admin: %v", err)
+	}
+	fmt.Printf("created admin %s (user id %d)\n", user.Email, user.ID)
+}
+
+// runResetPassword = backend reset-password -email x [-password y]
+func runResetPassword(args []string) {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := fs.String("email", "", "user email (required)")
+	password := fs.String("password", "", "new password (read from stdin if omitted)")
+	fs.Parse(args)
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := bootstrap()
+	user, err := authService(cfg).ResetPassword(*email, readPassword(*password))
+	if err != nil {
+		log.Fatalf("reset-password: %v", err)
+	}
+	fmt.Printf("password updated for %s (user id %d)\n", user.Email, user.ID)
+}
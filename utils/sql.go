@@ -0,0 +1,16 @@
+package utils
+
+import "gorm.io/gorm"
+
+// CastToText คืน SQL expression ที่แปลงค่าเป็นข้อความตาม dialect ของ db
+// (ใช้กับ LIKE บนคอลัมน์ตัวเลข เช่นค้นหาเลข order)
+func CastToText(db *gorm.DB, expr string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return expr + "::text"
+	case "mysql":
+		return "CAST(" + expr + " AS CHAR)"
+	default:
+		return "CAST(" + expr + " AS TEXT)"
+	}
+}
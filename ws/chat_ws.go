@@ -2,159 +2,265 @@ package ws
 
 import (
 	"backend/entity"
+	"backend/metrics"
+	"backend/pkg/logx"
+	"backend/pkg/resp"
+	"backend/pubsub"
 	"backend/services"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
+// ค่า default ของ hub (ปรับผ่าน field ของ ChatHub ก่อนเรียก Run)
+const (
+	defaultSendBuffer      = 32
+	defaultWriteWait       = 10 * time.Second
+	defaultPongWait        = 60 * time.Second
+	defaultMaxMessageBytes = 8 << 10
+)
+
 // ChatHub คือศูนย์กลางของระบบแชทผ่าน WebSocket
+//
+// สมาชิกของห้องแก้ได้จาก goroutine ของ Run เท่านั้น (ไม่ต้องมี lock)
+// การกระจายข้อความไม่เขียนลง conn ตรง ๆ แต่โยนเข้า buffer ของแต่ละ Client → client ช้าไม่ถ่วงห้องอื่น
 type ChatHub struct {
-	clients    map[uint]map[*websocket.Conn]bool // roomID -> set of clients
+	clients    map[uint]map[*Client]struct{} // roomID -> set of clients
 	broadcast  chan BroadcastMessage
-	register   chan Subscription
-	unregister chan Subscription
-	mu         sync.Mutex
+	register   chan *Client
+	unregister chan *Client
 	service    *services.ChatService
-}
+	done       chan struct{}  // ปิดเมื่อ Run หยุด (หลัง shutdown ห้ามส่งเข้า channel ข้างบนอีก)
+	writers    sync.WaitGroup // writePump ที่ยังทำงาน (Run รอให้ส่ง close frame ครบก่อนคืนค่า)
 
-// Subscription = การสมัครสมาชิกห้อง (1 user ต่อ 1 connection)
-type Subscription struct {
-	Conn   *websocket.Conn // การเชื่อมต่อ WS ของ client
-	RoomID uint
-	UserID uint
+	// CheckOrigin ตรวจ header Origin ตอน upgrade (nil = อนุญาตเฉพาะ origin เดียวกับ host)
+	CheckOrigin func(r *http.Request) bool
+	Metrics     *metrics.Metrics // จำนวน connection ต่อห้อง (nil = ไม่เก็บ)
+	Broker      pubsub.Broker    // แหล่ง ChatEvent จากทุก instance (ดู Relay)
+
+	SendBuffer      int           // ข้อความที่ค้างส่งได้ต่อ connection; เต็ม = ตัด client นั้นทิ้ง (slow consumer)
+	WriteWait       time.Duration // เวลาสูงสุดของการเขียน 1 ครั้ง
+	PongWait        time.Duration // ไม่ได้ยินจาก client นานเท่านี้ = ถือว่าหลุด
+	PingInterval    time.Duration // 0 = 9/10 ของ PongWait
+	MaxMessageBytes int64         // ขนาดข้อความสูงสุดที่รับจาก client
 }
 
-// BroadcastMessage = ข้อความที่จะส่งกระจายให้ทุกคนในห้อง
+// BroadcastMessage = frame (encode แล้ว) ที่จะส่งกระจายให้ทุกคนในห้อง
 type BroadcastMessage struct {
-	RoomID  uint
-	Message *entity.Message
+	RoomID   uint
+	Data     []byte
+	SkipUser uint // ไม่ส่งให้ connection ของ user นี้ (เช่น คนที่กำลังพิมพ์เอง; 0 = ส่งทุกคน)
 }
 
 // สร้าง ChatHub ใหม่
 func NewChatHub(service *services.ChatService) *ChatHub {
 	return &ChatHub{
-		clients:    make(map[uint]map[*websocket.Conn]bool),
+		clients:    make(map[uint]map[*Client]struct{}),
 		broadcast:  make(chan BroadcastMessage),
-		register:   make(chan Subscription),
-		unregister: make(chan Subscription),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
 		service:    service,
+		done:       make(chan struct{}),
+
+		SendBuffer:      defaultSendBuffer,
+		WriteWait:       defaultWriteWait,
+		PongWait:        defaultPongWait,
+		MaxMessageBytes: defaultMaxMessageBytes,
 	}
 }
 
-// คอยฟัง register/unregister/broadcast ตลอดเวลา
-func (h *ChatHub) Run() {
+func (h *ChatHub) pingInterval() time.Duration {
+	if h.PingInterval > 0 {
+		return h.PingInterval
+	}
+	return h.PongWait * 9 / 10
+}
+
+// คอยฟัง register/unregister/broadcast จน ctx ถูก cancel แล้วส่ง close frame ให้ทุก client
+func (h *ChatHub) Run(ctx context.Context) {
+	defer close(h.done)
 	for {
 		select {
+		case <-ctx.Done():
+			h.closeAll()
+			return
+
 			// มี client ใหม่เข้าห้อง
-		case sub := <-h.register:
-			h.mu.Lock()
-			if h.clients[sub.RoomID] == nil {
-				h.clients[sub.RoomID] = make(map[*websocket.Conn]bool)
+		case c := <-h.register:
+			if h.clients[c.RoomID] == nil {
+				h.clients[c.RoomID] = make(map[*Client]struct{})
 			}
-			h.clients[sub.RoomID][sub.Conn] = true
-			h.mu.Unlock()
+			h.clients[c.RoomID][c] = struct{}{}
+			h.writers.Add(1)
+			go c.writePump()
+			h.Metrics.SetWSConnections(c.RoomID, len(h.clients[c.RoomID]))
 
 			// มี client ออกจากห้อง
-		case sub := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[sub.RoomID][sub.Conn]; ok {
-				delete(h.clients[sub.RoomID], sub.Conn)
-				sub.Conn.Close()
+		case c := <-h.unregister:
+			if _, ok := h.clients[c.RoomID][c]; ok {
+				h.remove(c, 0, "")
 			}
-			h.mu.Unlock()
 
 			// มีข้อความใหม่เข้ามา → กระจายให้ทุกคนในห้อง
 		case msg := <-h.broadcast:
-			h.mu.Lock()
-			for conn := range h.clients[msg.RoomID] {
-				if err := conn.WriteJSON(msg.Message); err != nil {
-					log.Printf("ws write error: %v", err)
-					conn.Close()
-					delete(h.clients[msg.RoomID], conn)
+			for c := range h.clients[msg.RoomID] {
+				if msg.SkipUser != 0 && c.UserID == msg.SkipUser {
+					continue
+				}
+				select {
+				case c.send <- msg.Data:
+				default:
+					slog.WarnContext(c.Ctx, "ws slow consumer dropped", "buffer", cap(c.send))
+					h.remove(c, websocket.CloseTryAgainLater, "slow consumer")
 				}
 			}
-			h.mu.Unlock()
 		}
 	}
 }
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// remove เอา client ออกจากห้องแล้วปิด send (writePump ส่ง close frame ตาม code แล้วปิด conn)
+func (h *ChatHub) remove(c *Client, code int, text string) {
+	room := h.clients[c.RoomID]
+	delete(room, c)
+	if len(room) == 0 {
+		delete(h.clients, c.RoomID)
+	}
+	c.closeCode, c.closeText = code, text
+	close(c.send)
+	h.Metrics.SetWSConnections(c.RoomID, len(room))
 }
 
-// WS route: /ws/chat/:roomId
-func (h *ChatHub) HandleWebSocket(c *gin.Context) {
-    roomIDStr := c.Param("roomId")
-    var roomID uint
-    fmt.Sscan(roomIDStr, &roomID)
-
-		// --- ดึง userId จาก JWT ที่ middleware ใส่ไว้
-    userIDVal, _ := c.Get("userId")
-    userID := userIDVal.(uint)
-
-    // --- ตรวจสอบว่าห้องนี้มีจริงไหม
-    room, err := h.service.GetRoomByID(roomID)
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
-        return
-    }
-
-		// --- ตรวจสอบสิทธิ์ (เจ้าของ order หรือ rider เท่านั้น)
-    ok, err := h.service.CanAccessRoom(userID, room.OrderID)
-    if err != nil || !ok {
-        c.JSON(http.StatusForbidden, gin.H{"error": "no access"})
-        return
-    }
-
-		// --- Upgrade HTTP → WebSocket
-    conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-    if err != nil {
-        log.Printf("ws upgrade error: %v", err)
-        return
-    }
-
-		// --- สมัคร client เข้า room
-    sub := Subscription{Conn: conn, RoomID: room.ID, UserID: userID}
-    h.register <- sub
-
-    go h.listenMessages(sub)
+// closeAll ส่ง close frame (going away) ให้ทุก connection ให้ client รู้ว่าต้องต่อใหม่ แล้วรอ writer จบ
+func (h *ChatHub) closeAll() {
+	n := 0
+	for _, room := range h.clients {
+		for c := range room {
+			h.remove(c, websocket.CloseGoingAway, "server shutting down")
+			n++
+		}
+	}
+	h.writers.Wait() // writer แต่ละตัวถูกจำกัดด้วย WriteWait
+	slog.Info("ws hub stopped", "closed", n)
 }
 
-// listenMessages = ฟังข้อความใหม่จาก client ทาง WS
-func (h *ChatHub) listenMessages(sub Subscription) {
-	defer func() { h.unregister <- sub }()
+// Broadcast ส่งข้อความให้ทุก connection ในห้อง (หลัง hub หยุดแล้ว = ไม่ทำอะไร)
+func (h *ChatHub) Broadcast(roomID uint, msg *entity.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("ws encode message failed", "roomId", roomID, "error", err)
+		return
+	}
+	h.send(BroadcastMessage{RoomID: roomID, Data: data})
+}
 
-	for {
-		_, msgData, err := sub.Conn.ReadMessage()
-		if err != nil {
-			log.Printf("ws read error: %v", err)
-			break
-		}
+func (h *ChatHub) send(msg BroadcastMessage) {
+	select {
+	case h.broadcast <- msg:
+	case <-h.done:
+	}
+}
 
-		var payload struct {
-			Body          string `json:"body"`
-			TypeMessageID uint   `json:"typeMessageId"`
+// Relay รับ ChatEvent จาก broker (ทุก instance รวมตัวเอง) แล้วกระจายให้ client ในห้องที่ต่อกับ instance นี้
+// รันเป็น worker แยกจาก Run; ต่อ broker ไม่ได้ = ลองใหม่เรื่อย ๆ จน ctx ถูก cancel
+func (h *ChatHub) Relay(ctx context.Context) {
+	if h.Broker == nil {
+		return
+	}
+	backoff := time.Second
+	for {
+		err := h.Broker.Subscribe(ctx, services.ChatChannel, h.deliver)
+		if ctx.Err() != nil {
+			return
 		}
-		if err := json.Unmarshal(msgData, &payload); err != nil {
-			log.Printf("invalid payload: %v", err)
-			continue
+		slog.Error("ws relay subscribe failed", "error", err, "retryIn", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
 		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
 
-		// ใช้ user จาก JWT ไม่ใช่ FE
-		msg, err := h.service.SendMessage(sub.RoomID, sub.UserID, payload.TypeMessageID, payload.Body)
-		if err != nil {
-			log.Printf("save msg error: %v", err)
-			continue
+func (h *ChatHub) deliver(payload []byte) {
+	var ev services.ChatEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		slog.Warn("ws relay invalid event", "error", err)
+		return
+	}
+	switch ev.Type {
+	case services.ChatEventMessage:
+		if ev.Message != nil {
+			h.Broadcast(ev.RoomID, ev.Message) // client เดิมรับ Message ตรง ๆ
 		}
+	case services.ChatEventRead:
+		h.send(BroadcastMessage{RoomID: ev.RoomID, Data: payload})
+	case services.ChatEventTyping:
+		h.send(BroadcastMessage{RoomID: ev.RoomID, Data: payload, SkipUser: ev.UserID})
+	}
+}
 
-		h.broadcast <- BroadcastMessage{RoomID: sub.RoomID, Message: msg}
+// leave = client หลุด/ออกเอง (เรียกจาก readPump)
+func (h *ChatHub) leave(c *Client) {
+	select {
+	case h.unregister <- c:
+	case <-h.done: // hub ปิด connection ให้แล้ว
 	}
 }
 
+// WS route: /ws/chat/:roomId
+func (h *ChatHub) HandleWebSocket(c *gin.Context) {
+	roomIDStr := c.Param("roomId")
+	var roomID uint
+	fmt.Sscan(roomIDStr, &roomID)
+
+	// --- ดึง userId จาก JWT ที่ middleware ใส่ไว้
+	userIDVal, _ := c.Get("userId")
+	userID := userIDVal.(uint)
+
+	// --- ตรวจสอบว่าห้องนี้มีจริงไหม
+	room, err := h.service.GetRoomByID(roomID)
+	if err != nil {
+		resp.NotFound(c, "room not found")
+		return
+	}
+
+	// --- ตรวจสอบสิทธิ์ (เจ้าของ order หรือ rider เท่านั้น)
+	ok, err := h.service.CanAccessRoom(userID, room.OrderID)
+	if err != nil || !ok {
+		resp.Forbidden(c, "no access")
+		return
+	}
+
+	// --- Upgrade HTTP → WebSocket
+	upgrader := websocket.Upgrader{CheckOrigin: h.CheckOrigin}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "ws upgrade failed", "roomId", roomID, "error", err)
+		return
+	}
 
+	// --- สมัคร client เข้า room
+	// request จบแล้วแต่ session ยังอยู่ → เอาแค่ attr ของ log ไป ไม่เอาการยกเลิก
+	ctx := logx.With(context.WithoutCancel(c.Request.Context()), "roomId", room.ID)
+	client := newClient(h, conn, room.ID, userID, ctx)
+	slog.InfoContext(ctx, "ws connected")
+	select {
+	case h.register <- client:
+	case <-h.done:
+		// hub หยุดแล้ว (กำลัง shutdown)
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(h.WriteWait))
+		conn.Close()
+		return
+	}
+
+	go client.readPump()
+}
@@ -0,0 +1,402 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/configs"
+	"backend/lookups"
+	"backend/migrations"
+	"backend/pubsub"
+	"backend/repository"
+	"backend/services"
+
+	"github.com/gorilla/websocket"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testHub รัน ChatHub จริงหลัง httptest server ที่ upgrade แล้วเข้าห้องตาม ?room= (&user=, default 1) โดยไม่ผ่าน ChatService
+type testHub struct {
+	t      *testing.T
+	hub    *ChatHub
+	url    string
+	cancel context.CancelFunc
+	joined chan struct{}
+}
+
+func newTestHub(t *testing.T, configure func(h *ChatHub)) *testHub {
+	t.Helper()
+	h := NewChatHub(nil)
+	h.WriteWait = 2 * time.Second
+	if configure != nil {
+		configure(h)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go h.Run(ctx)
+	go h.Relay(ctx) // ไม่มี Broker = จบทันที
+
+	th := &testHub{t: t, hub: h, cancel: cancel, joined: make(chan struct{})}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		var roomID, userID uint = 1, 1
+		if r.URL.Query().Get("room") == "2" {
+			roomID = 2
+		}
+		if u, err := strconv.ParseUint(r.URL.Query().Get("user"), 10, 64); err == nil {
+			userID = uint(u)
+		}
+		c := newClient(h, conn, roomID, userID, context.Background())
+		h.register <- c
+		go c.readPump()
+		th.joined <- struct{}{}
+	}))
+	th.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+	t.Cleanup(func() {
+		cancel()
+		<-h.done
+		srv.Close()
+	})
+	return th
+}
+
+// dial ต่อเข้าห้องแล้วรอจน hub รับ register
+func (th *testHub) dial(room string) *websocket.Conn {
+	th.t.Helper()
+	return th.dialAs(room, 1)
+}
+
+func (th *testHub) dialAs(room string, userID uint) *websocket.Conn {
+	th.t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s?room=%s&user=%d", th.url, room, userID), nil)
+	if err != nil {
+		th.t.Fatal(err)
+	}
+	th.t.Cleanup(func() { conn.Close() })
+	select {
+	case <-th.joined:
+	case <-time.After(2 * time.Second):
+		th.t.Fatal("client was not registered")
+	}
+	return conn
+}
+
+// bystander = client ที่อ่านตลอด (ตอบ ping) คืน error สุดท้ายที่อ่านได้
+func (th *testHub) bystander(room string) <-chan error {
+	conn := th.dial(room)
+	done := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// shutdown ยกเลิก Run แล้วตรวจว่า closeAll ส่ง going away ให้ client ที่ยังต่ออยู่
+func (th *testHub) shutdown(bystander <-chan error) {
+	th.t.Helper()
+	select {
+	case err := <-bystander:
+		th.t.Fatalf("bystander disconnected before shutdown: %v", err)
+	default:
+	}
+	th.cancel()
+	select {
+	case <-th.hub.done:
+	case <-time.After(5 * time.Second):
+		th.t.Fatal("hub did not stop")
+	}
+	select {
+	case err := <-bystander:
+		wantClose(th.t, err, websocket.CloseGoingAway)
+	case <-time.After(2 * time.Second):
+		th.t.Fatal("bystander got no close frame on shutdown")
+	}
+}
+
+func wantClose(t *testing.T, err error, code int) {
+	t.Helper()
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != code {
+		t.Fatalf("err = %v, want close %d", err, code)
+	}
+}
+
+func TestSlowConsumerIsDropped(t *testing.T) {
+	th := newTestHub(t, func(h *ChatHub) { h.SendBuffer = 1 })
+	other := th.bystander("2")
+	slow := th.dial("1") // ไม่อ่านเลย
+
+	// frame ใหญ่พอให้ socket buffer เต็ม → writePump ค้าง → send เต็ม
+	const sent = 32
+	data := make([]byte, 1<<20)
+	for i := 0; i < sent; i++ {
+		th.hub.send(BroadcastMessage{RoomID: 1, Data: data})
+	}
+
+	received := 0
+	_ = slow.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := slow.ReadMessage()
+		if err != nil {
+			wantClose(t, err, websocket.CloseTryAgainLater)
+			break
+		}
+		received++
+	}
+	if received >= sent {
+		t.Fatalf("slow consumer received all %d messages", received)
+	}
+
+	th.shutdown(other)
+}
+
+func TestPongTimeoutDisconnects(t *testing.T) {
+	th := newTestHub(t, func(h *ChatHub) { h.PongWait = 200 * time.Millisecond })
+	other := th.bystander("1") // ตอบ ping ตลอด ต้องไม่หลุด
+	idle := th.dial("1")
+
+	// ไม่อ่าน = ไม่ตอบ pong; เกิน PongWait แล้ว server ต้องปิด conn (ไม่มี close frame)
+	time.Sleep(600 * time.Millisecond)
+	_ = idle.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := idle.ReadMessage()
+		if err != nil {
+			wantClose(t, err, websocket.CloseAbnormalClosure)
+			break
+		}
+	}
+
+	th.shutdown(other)
+}
+
+func TestMaxMessageBytesEnforced(t *testing.T) {
+	th := newTestHub(t, func(h *ChatHub) { h.MaxMessageBytes = 64 })
+	other := th.bystander("2")
+	conn := th.dial("1")
+
+	// ข้อความเล็กกว่า limit ผ่าน (event ที่ไม่รู้จัก = ข้ามไป) และ connection ยังรับ broadcast ได้
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"noop"}`)); err != nil {
+		t.Fatal(err)
+	}
+	th.hub.send(BroadcastMessage{RoomID: 1, Data: []byte(`{"id":1}`)})
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, data, err := conn.ReadMessage(); err != nil || string(data) != `{"id":1}` {
+		t.Fatalf("broadcast: %q %v", data, err)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"body":"`+strings.Repeat("x", 128)+`"}`)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			wantClose(t, err, websocket.CloseMessageTooBig)
+			break
+		}
+	}
+
+	th.shutdown(other)
+}
+
+var chatDBSeq atomic.Int64
+
+// newChatService = ChatService บน SQLite in-memory ที่ publish event เข้า broker
+func newChatService(t *testing.T, broker pubsub.Broker) *services.ChatService {
+	t.Helper()
+	dsn := fmt.Sprintf("file:ws_%d?mode=memory&cache=shared", chatDBSeq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := configs.SeedLookupTables(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lookups.Load(db); err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewChatService(db, repository.NewChatRepository(db), nil)
+	svc.Broker = broker
+	return svc
+}
+
+// frames อ่านทุก frame ของ conn ใน goroutine แยก (read deadline ที่หมดเวลาจะทำให้ conn ใช้ต่อไม่ได้)
+func frames(conn *websocket.Conn) <-chan map[string]any {
+	ch := make(chan map[string]any, 64)
+	go func() {
+		defer close(ch)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var f map[string]any
+			if json.Unmarshal(data, &f) == nil {
+				ch <- f
+			}
+		}
+	}()
+	return ch
+}
+
+// nextFrame รอ frame แรกที่ match (ข้าม frame อื่น)
+func nextFrame(t *testing.T, ch <-chan map[string]any, what string, match func(map[string]any) bool) map[string]any {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case f, ok := <-ch:
+			if !ok {
+				t.Fatalf("%s: connection closed", what)
+			}
+			if match(f) {
+				return f
+			}
+		case <-timeout:
+			t.Fatalf("%s: no frame", what)
+		}
+	}
+}
+
+const probeUser = 999 // user ของ typing ที่ waitRelay ใช้ (ไม่ใช่ user ของ conn ไหนในเทสต์ → ไม่ถูกข้าม)
+
+// waitRelay = Relay subscribe แบบ async และ broker เป็น at-most-once → ส่ง typing ซ้ำจนทุก conn ได้รับก่อนเริ่มทดสอบจริง
+func waitRelay(t *testing.T, svc *services.ChatService, conns ...<-chan map[string]any) {
+	t.Helper()
+	for i, ch := range conns {
+		deadline := time.After(3 * time.Second)
+	probe:
+		for {
+			svc.SetTyping(context.Background(), 1, probeUser, true)
+			select {
+			case f, ok := <-ch:
+				if !ok {
+					t.Fatalf("conn %d closed while waiting for relay", i)
+				}
+				if f["type"] == services.ChatEventTyping {
+					break probe
+				}
+			case <-time.After(20 * time.Millisecond):
+			case <-deadline:
+				t.Fatalf("conn %d: relay never subscribed", i)
+			}
+		}
+	}
+}
+
+func TestRelayDeliversAcrossHubs(t *testing.T) {
+	broker := pubsub.NewMemoryBroker()
+	a := newTestHub(t, func(h *ChatHub) { h.Broker = broker })
+	b := newTestHub(t, func(h *ChatHub) { h.Broker = broker })
+	svc := newChatService(t, broker) // API ของ instance A
+	onA, onB := frames(a.dial("1")), frames(b.dial("1"))
+	otherRoom := frames(b.dial("2"))
+	waitRelay(t, svc, onA, onB)
+
+	msg, err := svc.SendMessage(context.Background(), 1, 2, 0, "hello from A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, ch := range map[string]<-chan map[string]any{"hub A": onA, "hub B": onB} {
+		f := nextFrame(t, ch, name, func(f map[string]any) bool { return f["body"] != nil })
+		if f["body"] != "hello from A" || f["ID"] != float64(msg.ID) || f["roomId"] != float64(1) {
+			t.Errorf("%s: frame = %v, want message %d", name, f, msg.ID)
+		}
+	}
+
+	// ห้องอื่นบน hub B ต้องไม่ได้อะไรเลย
+	select {
+	case f := <-otherRoom:
+		t.Fatalf("room 2 got %v", f)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// eventsUntil เก็บ read/typing event ของ conn (ข้าม probe ของ waitRelay และข้อความแชท) จนเจอ event ที่ stop
+func eventsUntil(t *testing.T, ch <-chan map[string]any, what string, stop func(map[string]any) bool) []map[string]any {
+	t.Helper()
+	var got []map[string]any
+	for {
+		f := nextFrame(t, ch, what, func(f map[string]any) bool {
+			return f["type"] != nil && f["userId"] != float64(probeUser)
+		})
+		if stop(f) {
+			return got
+		}
+		got = append(got, f)
+	}
+}
+
+func TestReadAndTypingEvents(t *testing.T) {
+	broker := pubsub.NewMemoryBroker()
+	th := newTestHub(t, func(h *ChatHub) { h.Broker = broker })
+	svc := newChatService(t, broker)
+	ctx := context.Background()
+	reader, peer := frames(th.dialAs("1", 1)), frames(th.dialAs("1", 2))
+	waitRelay(t, svc, reader, peer)
+
+	m1, err := svc.SendMessage(ctx, 1, 2, 0, "one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := svc.SendMessage(ctx, 1, 2, 0, "two")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// เลื่อนครั้งแรกเท่านั้นที่ขยับจริง: id เก่ากว่า / id เดิม / ล่าสุด (= m2) ต้องไม่ส่ง event ซ้ำ
+	for _, id := range []uint{m2.ID, m1.ID, m2.ID, 0} {
+		read, err := svc.MarkRead(ctx, 1, 1, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.LastReadMessageID != m2.ID {
+			t.Fatalf("MarkRead(%d): last read = %d, want %d", id, read.LastReadMessageID, m2.ID)
+		}
+	}
+	// typing ไม่ส่งกลับไปหาคนพิมพ์; typing ของ peer ปิดท้ายเป็นตัวบอกว่า event ก่อนหน้ามาครบแล้ว
+	svc.SetTyping(ctx, 1, 1, true)
+	svc.SetTyping(ctx, 1, 2, true)
+
+	typingBy := func(user uint) func(map[string]any) bool {
+		return func(f map[string]any) bool {
+			return f["type"] == services.ChatEventTyping && f["userId"] == float64(user)
+		}
+	}
+	got := eventsUntil(t, reader, "reader", typingBy(2))
+	if len(got) != 1 || got[0]["type"] != services.ChatEventRead || got[0]["lastReadMessageId"] != float64(m2.ID) {
+		t.Fatalf("reader events = %v, want a single read up to %d (and no own typing)", got, m2.ID)
+	}
+	got = eventsUntil(t, peer, "peer", typingBy(1))
+	if len(got) != 1 || got[0]["type"] != services.ChatEventRead {
+		t.Fatalf("peer events before typing = %v, want a single read", got)
+	}
+	select {
+	case f := <-peer:
+		t.Fatalf("peer got its own event %v", f)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,151 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"backend/services"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client = 1 connection ในห้องแชท
+//
+// เขียนลง conn ได้จาก writePump เท่านั้น (gorilla ไม่รองรับ writer หลายตัว) ส่วน hub แค่โยนข้อความเข้า send
+// hub เป็นคนเดียวที่ close(send) → writePump ส่ง close frame ตาม closeCode แล้วปิด conn
+type Client struct {
+	hub  *ChatHub
+	conn *websocket.Conn
+	send chan []byte // ข้อความที่ encode แล้ว รอ writePump ส่ง
+
+	RoomID uint
+	UserID uint
+	Ctx    context.Context // ของ session: requestId ตอน upgrade + userId + roomId สำหรับ log
+
+	// สถานะพิมพ์ที่แจ้งไปล่าสุด (ใช้ใน readPump เท่านั้น) กัน client ส่ง typing ถี่เกินไป
+	typing       bool
+	typingSentAt time.Time
+
+	// เหตุผลที่ hub ตัด connection (ตั้งก่อน close(send) เท่านั้น; 0 = client ออกเอง ไม่ต้องส่ง close frame)
+	closeCode int
+	closeText string
+}
+
+func newClient(h *ChatHub, conn *websocket.Conn, roomID, userID uint, ctx context.Context) *Client {
+	return &Client{
+		hub:    h,
+		conn:   conn,
+		send:   make(chan []byte, h.SendBuffer),
+		RoomID: roomID,
+		UserID: userID,
+		Ctx:    ctx,
+	}
+}
+
+// writePump ส่งข้อความจาก send + ping ตามรอบ; จบเมื่อ send ถูกปิดหรือเขียนไม่สำเร็จ
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.hub.pingInterval())
+	defer func() {
+		ticker.Stop()
+		c.conn.Close() // readPump จะอ่านไม่ได้แล้ว unregister เอง
+		c.hub.writers.Done()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.WriteWait))
+			if !ok {
+				if c.closeCode != 0 {
+					_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeText))
+				}
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				slog.WarnContext(c.Ctx, "ws write failed", "error", err)
+				return
+			}
+
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump = ฟังข้อความใหม่จาก client ทาง WS
+// ไม่มี pong (หรือข้อความใด ๆ) ภายใน PongWait = ถือว่าหลุด; ข้อความเกิน MaxMessageBytes = ตัดทิ้ง (gorilla ส่ง 1009 ให้)
+func (c *Client) readPump() {
+	defer c.hub.leave(c)
+	defer c.setTyping(false) // หลุดกลางคันตอนกำลังพิมพ์
+
+	h := c.hub
+	c.conn.SetReadLimit(h.MaxMessageBytes)
+	extend := func() error { return c.conn.SetReadDeadline(time.Now().Add(h.PongWait)) }
+	_ = extend()
+	c.conn.SetPongHandler(func(string) error { return extend() })
+
+	for {
+		_, msgData, err := c.conn.ReadMessage()
+		if err != nil {
+			var netErr interface{ Timeout() bool }
+			switch {
+			case errors.Is(err, websocket.ErrReadLimit):
+				slog.WarnContext(c.Ctx, "ws message too large", "limit", h.MaxMessageBytes)
+			case errors.As(err, &netErr) && netErr.Timeout():
+				slog.InfoContext(c.Ctx, "ws keepalive timeout")
+			case websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure):
+				slog.WarnContext(c.Ctx, "ws read failed", "error", err)
+			default:
+				slog.InfoContext(c.Ctx, "ws disconnected")
+			}
+			return
+		}
+		_ = extend()
+
+		var payload struct {
+			Type          string `json:"type"` // ว่าง/message | typing | read
+			Body          string `json:"body"`
+			TypeMessageID uint   `json:"typeMessageId"`
+			Typing        bool   `json:"typing"`
+			MessageID     uint   `json:"messageId"` // read: 0 = ข้อความล่าสุด
+		}
+		if err := json.Unmarshal(msgData, &payload); err != nil {
+			slog.WarnContext(c.Ctx, "ws invalid payload", "error", err)
+			continue
+		}
+
+		// ใช้ user จาก JWT ไม่ใช่ FE; service publish ให้ทุก instance เอง (รวม hub นี้ผ่าน Relay)
+		switch payload.Type {
+		case "", services.ChatEventMessage:
+			if _, err := h.service.SendMessage(c.Ctx, c.RoomID, c.UserID, payload.TypeMessageID, payload.Body); err != nil {
+				slog.ErrorContext(c.Ctx, "ws save message failed", "error", err)
+				continue
+			}
+			c.setTyping(false) // ส่งแล้ว = หยุดพิมพ์
+		case services.ChatEventTyping:
+			c.setTyping(payload.Typing)
+		case services.ChatEventRead:
+			if _, err := h.service.MarkRead(c.Ctx, c.RoomID, c.UserID, payload.MessageID); err != nil {
+				slog.WarnContext(c.Ctx, "ws mark read failed", "messageId", payload.MessageID, "error", err)
+			}
+		default:
+			slog.WarnContext(c.Ctx, "ws unknown event", "type", payload.Type)
+		}
+	}
+}
+
+// setTyping แจ้งสถานะพิมพ์เมื่อเปลี่ยน หรือเมื่อ "ยังพิมพ์อยู่" เกินครึ่ง TypingTTL (ต่ออายุฝั่ง client)
+func (c *Client) setTyping(typing bool) {
+	now := time.Now()
+	if typing == c.typing && (!typing || now.Sub(c.typingSentAt) < services.TypingTTL/2) {
+		return
+	}
+	c.typing, c.typingSentAt = typing, now
+	c.hub.service.SetTyping(c.Ctx, c.RoomID, c.UserID, typing)
+}